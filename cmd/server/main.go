@@ -8,6 +8,7 @@ import (
 	"lovender_backend/internal/repository"
 	"lovender_backend/internal/routes"
 	"lovender_backend/internal/service"
+	"lovender_backend/pkg/jwtutil"
 	"net/http"
 	"os"
 	"os/signal"
@@ -19,6 +20,11 @@ import (
 )
 
 func main() {
+	// JWTの署名鍵を読み込む（未設定なら起動しない）
+	if err := jwtutil.Init(); err != nil {
+		panic("Failed to load JWT keys: " + err.Error())
+	}
+
 	// データベース接続
 	db, err := database.NewConnection()
 	if err != nil {
@@ -32,9 +38,35 @@ func main() {
 
 	// 依存関係の注入
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	sessionRepo := repository.NewSessionRepository(db)
+	sessionService := service.NewSessionService(sessionRepo, userRepo)
+	sessionHandler := handler.NewSessionHandler(sessionService)
+	// メール送信（SMTP未設定時はログに出力）
+	mailer := service.NewMailerFromEnv()
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	// パスワード再設定メールの送信回数の制限（メールアドレス・IPごとにメモリで管理）
+	passwordResetThrottle := service.NewPasswordResetThrottle(service.NewMemoryLoginAttemptStore())
+	accountService := service.NewAccountService(userRepo, userTokenRepo, mailer, passwordResetThrottle)
+	accountHandler := handler.NewAccountHandler(accountService)
+	// ログイン試行の制限（メールアドレス・IPごとの失敗回数はメモリで管理）
+	loginThrottle := service.NewLoginThrottle(service.NewMemoryLoginAttemptStore())
+	userService := service.NewUserService(userRepo, sessionService, accountService, loginThrottle)
 	userHandler := handler.NewUserHandler(userService)
 
+	// ソーシャルログイン（OIDC_PROVIDERS で設定したプロバイダー）
+	oidcRepo := repository.NewOIDCRepository(db)
+	oidcService := service.NewOIDCService(service.NewOIDCProvidersFromEnv(), oidcRepo, userRepo, sessionService)
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+
+	// JWTの検証時にセッションが失効していないか確認する
+	jwtutil.SetSessionValidator(sessionService.IsSessionActive)
+
+	// 個人用アクセストークン（スクリプトや外部連携用）
+	personalAccessTokenRepo := repository.NewPersonalAccessTokenRepository(db)
+	personalAccessTokenService := service.NewPersonalAccessTokenService(personalAccessTokenRepo)
+	personalAccessTokenHandler := handler.NewPersonalAccessTokenHandler(personalAccessTokenService)
+	jwtutil.SetPersonalAccessTokenValidator(personalAccessTokenService.Authenticate)
+
 	oshiRepo := repository.NewOshiRepository(db)
 	oshiService := service.NewOshiService(oshiRepo)
 	oshiHandler := handler.NewOshiHandler(oshiService)
@@ -49,24 +81,66 @@ func main() {
 	eventsService := service.NewEventsService(eventsRepo)
 	eventsHandler := handler.NewEventsHandler(eventsService)
 
+	// イベント取り込み（iCalendar / CSV）
+	eventImportService := service.NewEventImportService(eventsRepo)
+	eventImportHandler := handler.NewEventImportHandler(eventImportService)
+
+	// カレンダーフィード（iCalendar形式でイベントを配信）
+	calendarFeedRepo := repository.NewCalendarFeedRepository(db)
+	calendarFeedService := service.NewCalendarFeedService(calendarFeedRepo, eventsRepo)
+	calendarFeedHandler := handler.NewCalendarFeedHandler(calendarFeedService)
+
+	// 自動検出したイベント候補（確認待ち）
+	eventCandidateRepo := repository.NewEventCandidateRepository(db)
+	eventCandidateService := service.NewEventCandidateService(eventCandidateRepo, eventsRepo)
+	eventCandidateHandler := handler.NewEventCandidateHandler(eventCandidateService)
+
+	// 投稿からの日時抽出（組み込みのパターンに DATETIME_PATTERNS_FILE のパターンを追加）
+	dateTimeExtractor, err := service.NewDateTimeExtractionServiceFromEnv()
+	if err != nil {
+		panic("Failed to load datetime patterns: " + err.Error())
+	}
+	dateTimePatternHandler := handler.NewDateTimePatternHandler(dateTimeExtractor)
+
 	// イベント自動登録サービス
-	eventAutoService := service.NewEventAutoService(eventsRepo, cacheManager.GetKeywordCache())
+	eventAutoService := service.NewEventAutoService(eventsRepo, eventCandidateRepo, cacheManager.GetKeywordCache(), dateTimeExtractor)
 	eventAutoHandler := handler.NewEventAutoHandler(eventAutoService)
 
 	// スケジューラーサービス（定期実行でポスト内容からイベントを作成）
 	schedulerService := service.NewSchedulerService(eventAutoService)
 	schedulerHandler := handler.NewSchedulerHandler(schedulerService)
 
+	// 通知ディスパッチャー（通知時刻を迎えたイベントのアラームを送信）
+	notificationRepo := repository.NewNotificationRepository(db)
+	notificationDispatcher := service.NewNotificationDispatcher(notificationRepo, service.NewNotifierFromEnv())
+
+	// 管理者向けのユーザー検索
+	adminUserService := service.NewAdminUserService(userRepo)
+	adminUserHandler := handler.NewAdminUserHandler(adminUserService)
+
+	// 退会の猶予期間を過ぎたユーザーの削除
+	accountPurger := service.NewAccountPurger(userRepo)
+
+	// 削除の取り消し期間を過ぎた推しの削除
+	oshiPurger := service.NewOshiPurger(oshiRepo)
+
 	// Echo インスタンスを作成
 	e := echo.New()
 
+	// クライアントのIPアドレスはプロキシが付けたX-Forwarded-Forから取得する（ログイン試行の制限に使う）
+	ipExtractor, err := handler.NewIPExtractorFromEnv()
+	if err != nil {
+		panic("Failed to configure IP extractor: " + err.Error())
+	}
+	e.IPExtractor = ipExtractor
+
 	// ミドルウェア
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORS())
 
 	// ルート設定
-	routes.SetupRoutes(e, userHandler, oshiHandler, oshiGetHandler, commonHandler, eventsHandler, eventAutoHandler, schedulerHandler)
+	routes.SetupRoutes(e, userHandler, oshiHandler, oshiGetHandler, commonHandler, eventsHandler, eventAutoHandler, schedulerHandler, calendarFeedHandler, eventImportHandler, eventCandidateHandler, sessionHandler, accountHandler, adminUserHandler, oidcHandler, personalAccessTokenHandler, dateTimePatternHandler)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -76,6 +150,15 @@ func main() {
 	// スケジューラーサービスを開始
 	schedulerService.Start()
 
+	// 通知ディスパッチャーを開始
+	notificationDispatcher.Start()
+
+	// 退会ユーザーの削除を開始
+	accountPurger.Start()
+
+	// 削除した推しの削除を開始
+	oshiPurger.Start()
+
 	// Graceful shutdown
 	go func() {
 		if err := e.Start(":" + port); err != nil && err != http.ErrServerClosed {
@@ -92,6 +175,15 @@ func main() {
 	// スケジューラーサービスのシャットダウン
 	schedulerService.Stop()
 
+	// 通知ディスパッチャーのシャットダウン
+	notificationDispatcher.Stop()
+
+	// 退会ユーザーの削除のシャットダウン
+	accountPurger.Stop()
+
+	// 削除した推しの削除のシャットダウン
+	oshiPurger.Stop()
+
 	// キャッシュマネージャーのシャットダウン
 	cacheManager.Shutdown()
 
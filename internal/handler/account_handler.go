@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/service"
+	"lovender_backend/pkg/jwtutil"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AccountHandler struct {
+	accountService service.AccountService
+}
+
+func NewAccountHandler(accountService service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// パスワード再設定メールを送信
+// 登録の有無にかかわらず同じレスポンスを返す
+func (h *AccountHandler) ForgotPassword(c echo.Context) error {
+	var req models.ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// バリデーション
+	if req.Email == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Email is required"})
+	}
+
+	if err := h.accountService.ForgotPassword(req.Email, clientInfo(c)); err != nil {
+		var rateLimitErr *service.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))))
+			return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "Too many password reset requests"})
+		}
+		log.Printf("ForgotPassword ERROR: %v", err)
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+// トークンを使ってパスワードを再設定
+func (h *AccountHandler) ResetPassword(c echo.Context) error {
+	var req models.ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// バリデーション
+	if req.Token == "" || req.Password == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Token and password are required"})
+	}
+
+	if len(req.Password) < 8 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Password must be at least 8 characters long"})
+	}
+
+	if err := h.accountService.ResetPassword(req.Token, req.Password); err != nil {
+		if err.Error() == "invalid token" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid or expired token"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password has been reset"})
+}
+
+// トークンを使ってメールアドレスを確認
+func (h *AccountHandler) VerifyEmail(c echo.Context) error {
+	var req models.VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// バリデーション
+	if req.Token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Token is required"})
+	}
+
+	if err := h.accountService.VerifyEmail(req.Token); err != nil {
+		if err.Error() == "invalid token" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid or expired token"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Email has been verified"})
+}
+
+// 確認メールを再送信
+func (h *AccountHandler) ResendEmailVerification(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	if err := h.accountService.ResendEmailVerification(int64(claims.UserID)); err != nil {
+		switch err.Error() {
+		case "user not found":
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		case "email already verified":
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Email already verified"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "Verification email has been sent"})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"lovender_backend/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AdminUserHandler struct {
+	adminUserService service.AdminUserService
+}
+
+func NewAdminUserHandler(adminUserService service.AdminUserService) *AdminUserHandler {
+	return &AdminUserHandler{
+		adminUserService: adminUserService,
+	}
+}
+
+// ユーザー一覧を検索（?q=メールアドレスまたは名前&page=1&per_page=50）
+func (h *AdminUserHandler) SearchUsers(c echo.Context) error {
+	page, err := parseOptionalInt(c.QueryParam("page"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid page"})
+	}
+	perPage, err := parseOptionalInt(c.QueryParam("per_page"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid per_page"})
+	}
+
+	resp, err := h.adminUserService.SearchUsers(c.QueryParam("q"), page, perPage)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// ユーザー情報を取得
+func (h *AdminUserHandler) GetUser(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil || id <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+	}
+
+	user, err := h.adminUserService.GetUser(id)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// 未指定なら0を返す
+func parseOptionalInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/service"
+	"lovender_backend/pkg/jwtutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CalendarFeedHandler struct {
+	calendarFeedService service.CalendarFeedService
+}
+
+func NewCalendarFeedHandler(calendarFeedService service.CalendarFeedService) *CalendarFeedHandler {
+	return &CalendarFeedHandler{
+		calendarFeedService: calendarFeedService,
+	}
+}
+
+// カレンダーフィード一覧を取得
+func (h *CalendarFeedHandler) GetMyCalendarFeeds(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	feeds, err := h.calendarFeedService.GetFeeds(int64(claims.UserID))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, feeds)
+}
+
+// カレンダーフィードを新規作成
+func (h *CalendarFeedHandler) CreateCalendarFeed(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// リクエストBodyのバインド
+	var req models.CreateCalendarFeedRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	feed, err := h.calendarFeedService.CreateFeed(int64(claims.UserID), &req, calendarFeedBaseURL(c))
+	if err != nil {
+		if err.Error() == "oshi not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Oshi not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusCreated, feed)
+}
+
+// カレンダーフィードのトークンを再発行
+func (h *CalendarFeedHandler) RotateCalendarFeed(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからfeedIdを取得
+	feedID, err := strconv.ParseInt(c.Param("feedId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid feed ID"})
+	}
+
+	feed, err := h.calendarFeedService.RotateFeed(feedID, int64(claims.UserID), calendarFeedBaseURL(c))
+	if err != nil {
+		if err.Error() == "calendar feed not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Calendar feed not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, feed)
+}
+
+// カレンダーフィードを無効化
+func (h *CalendarFeedHandler) RevokeCalendarFeed(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからfeedIdを取得
+	feedID, err := strconv.ParseInt(c.Param("feedId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid feed ID"})
+	}
+
+	err = h.calendarFeedService.RevokeFeed(feedID, int64(claims.UserID))
+	if err != nil {
+		if err.Error() == "calendar feed not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Calendar feed not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Calendar feed revoked"})
+}
+
+// iCalendar形式のフィードを配信（認証なし、トークンで識別）
+func (h *CalendarFeedHandler) GetCalendarFeed(c echo.Context) error {
+	token, ok := strings.CutSuffix(c.Param("token"), ".ics")
+	if !ok || token == "" {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Calendar feed not found"})
+	}
+
+	body, err := h.calendarFeedService.RenderFeed(token)
+	if err != nil {
+		if err.Error() == "calendar feed not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Calendar feed not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	c.Response().Header().Set("Cache-Control", "private, max-age=300")
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+// フィードURLのベース（CALENDAR_FEED_BASE_URL が未設定ならリクエストのホストを使う）
+func calendarFeedBaseURL(c echo.Context) string {
+	if baseURL := os.Getenv("CALENDAR_FEED_BASE_URL"); baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
+	return c.Scheme() + "://" + c.Request().Host
+}
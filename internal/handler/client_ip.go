@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewIPExtractorFromEnv クライアントのIPアドレスの取得方法を環境変数から生成する
+// X-Forwarded-For を右から順に見て、信頼できるプロキシ（ループバック・リンクローカル・プライベートアドレスと
+// TRUSTED_PROXY_CIDRS の範囲）を除いた最初のアドレスを使う。クライアントが付けた値は右端に来ないため使われない
+// X-Real-IP は使わない（Echoの既定ではヘッダーの値をそのまま使うため、ログイン試行の制限を回避できてしまう）
+func NewIPExtractorFromEnv() (echo.IPExtractor, error) {
+	options, err := trustedProxyOptions(os.Getenv("TRUSTED_PROXY_CIDRS"))
+	if err != nil {
+		return nil, err
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// カンマ区切りのCIDRを信頼するプロキシの範囲に変換
+func trustedProxyOptions(cidrs string) ([]echo.TrustOption, error) {
+	var options []echo.TrustOption
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXY_CIDRS %q: %w", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return options, nil
+}
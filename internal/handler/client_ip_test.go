@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"lovender_backend/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestIPExtractor(t *testing.T) {
+	t.Setenv("TRUSTED_PROXY_CIDRS", "35.191.0.0/16")
+	extractor, err := NewIPExtractorFromEnv()
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"ヘッダーなし", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"プロキシが付けたアドレス", "169.254.1.1:5000", map[string]string{"X-Forwarded-For": "203.0.113.7"}, "203.0.113.7"},
+		{"クライアントが付けたアドレスは使わない", "169.254.1.1:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7"}, "203.0.113.7"},
+		{"信頼するロードバランサーは除く", "169.254.1.1:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 35.191.0.10"}, "203.0.113.7"},
+		{"X-Real-IPは使わない", "203.0.113.7:5000", map[string]string{"X-Real-IP": "198.51.100.1"}, "203.0.113.7"},
+		{"プロキシを経由しないX-Forwarded-Forは使わない", "203.0.113.7:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			if got := extractor(req); got != tt.want {
+				t.Errorf("期待値 %q, 実際 %q", tt.want, got)
+			}
+		})
+	}
+
+	t.Setenv("TRUSTED_PROXY_CIDRS", "not-a-cidr")
+	if _, err := NewIPExtractorFromEnv(); err == nil {
+		t.Errorf("不正なCIDRはエラーにするべきです")
+	}
+}
+
+// ヘッダーを毎回変えても同じIPの失敗として数えることのテスト
+func TestClientInfo_SpoofedHeadersDoNotResetIPThrottle(t *testing.T) {
+	e := echo.New()
+	extractor, err := NewIPExtractorFromEnv()
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	e.IPExtractor = extractor
+	throttle := service.NewLoginThrottle(service.NewMemoryLoginAttemptStore())
+
+	for i := 0; i < 11; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+		req.RemoteAddr = "169.254.1.1:5000"
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d, 203.0.113.7", i))
+		req.Header.Set("X-Real-IP", fmt.Sprintf("192.0.2.%d", i))
+		info := clientInfo(e.NewContext(req, httptest.NewRecorder()))
+		if info.IPAddress != "203.0.113.7" {
+			t.Fatalf("IPアドレス: 期待値 203.0.113.7, 実際 %q", info.IPAddress)
+		}
+		throttle.RecordFailure(fmt.Sprintf("user%d@example.com", i), info.IPAddress)
+	}
+
+	var rateLimitErr *service.RateLimitError
+	if err := throttle.Check("another@example.com", "203.0.113.7"); !errors.As(err, &rateLimitErr) {
+		t.Errorf("同じIPからの失敗が続いた場合は制限するべきです: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/service"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DateTimePatternHandler struct {
+	dateTimeExtractor *service.DateTimeExtractionService
+}
+
+// NewDateTimePatternHandler コンストラクタ
+func NewDateTimePatternHandler(dateTimeExtractor *service.DateTimeExtractionService) *DateTimePatternHandler {
+	return &DateTimePatternHandler{
+		dateTimeExtractor: dateTimeExtractor,
+	}
+}
+
+// 日時抽出パターンの一覧を優先度順に取得
+func (h *DateTimePatternHandler) GetPatterns(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.DateTimePatternsResponse{
+		Patterns: h.dateTimeExtractor.Patterns(),
+	})
+}
+
+// 投稿内容の例から日時を抽出した結果を確認（patternを指定すると登録せずに追加して試す）
+func (h *DateTimePatternHandler) TestPatterns(c echo.Context) error {
+	var req models.DateTimePatternTestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if req.Text == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "text is required"})
+	}
+
+	postedAt := time.Now()
+	if req.PostedAt != nil {
+		postedAt = *req.PostedAt
+	}
+
+	resp, err := h.dateTimeExtractor.TestText(req.Text, postedAt, req.Pattern)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid pattern") {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
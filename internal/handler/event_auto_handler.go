@@ -2,8 +2,12 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"lovender_backend/internal/service"
+	"lovender_backend/pkg/jwtutil"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -42,3 +46,37 @@ func (h *EventAutoHandler) ProcessAutoEvents(c echo.Context) error {
 		"result":  result,
 	})
 }
+
+// 推し1件の投稿を今すぐ取り込む
+func (h *EventAutoHandler) SyncOshi(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshiIdを取得
+	oshiID, err := strconv.ParseInt(c.Param("oshiId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	// タイムアウト付きのコンテキスト（1分）
+	ctx, cancel := context.WithTimeout(c.Request().Context(), time.Minute)
+	defer cancel()
+
+	result, err := h.eventAutoService.SyncOshi(ctx, int64(claims.UserID), oshiID)
+	if err != nil {
+		var rateLimitErr *service.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))))
+			return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "Too many requests"})
+		}
+		if err.Error() == "oshi not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Oshi not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/service"
+	"lovender_backend/pkg/jwtutil"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type EventCandidateHandler struct {
+	eventCandidateService service.EventCandidateService
+}
+
+func NewEventCandidateHandler(eventCandidateService service.EventCandidateService) *EventCandidateHandler {
+	return &EventCandidateHandler{
+		eventCandidateService: eventCandidateService,
+	}
+}
+
+// 自動検出したイベント候補一覧を取得
+// クエリパラメータ status: pending（デフォルト）, accepted, rejected, all
+func (h *EventCandidateHandler) GetMyEventCandidates(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	candidates, err := h.eventCandidateService.GetCandidates(int64(claims.UserID), c.QueryParam("status"))
+	if err != nil {
+		if err.Error() == "invalid status" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid status"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, candidates)
+}
+
+// イベント候補をそのまま承認してイベントを作成
+func (h *EventCandidateHandler) AcceptEventCandidate(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからcandidateIdを取得
+	candidateID, err := strconv.ParseInt(c.Param("candidateId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid candidate ID"})
+	}
+
+	event, err := h.eventCandidateService.AcceptCandidate(candidateID, int64(claims.UserID), nil)
+	if err != nil {
+		return eventCandidateErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, event)
+}
+
+// イベント候補を編集して承認
+func (h *EventCandidateHandler) EditAndAcceptEventCandidate(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからcandidateIdを取得
+	candidateID, err := strconv.ParseInt(c.Param("candidateId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid candidate ID"})
+	}
+
+	// リクエストBodyのバインド
+	var req models.AcceptEventCandidateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// バリデーション
+	if req.Event.Title == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Title is required"})
+	}
+	if req.Event.Starts_at.IsZero() {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Starts at is required"})
+	}
+	if req.Event.Notification_timing == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Notification timing is required"})
+	}
+	req.Event.Recurrence, err = normalizeRecurrence(req.Event.Recurrence)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid recurrence"})
+	}
+
+	// 日時をUTCに変換
+	req.Event.Starts_at = req.Event.Starts_at.UTC()
+	if req.Event.Ends_at != nil {
+		utcTime := req.Event.Ends_at.UTC()
+		req.Event.Ends_at = &utcTime
+	}
+
+	event, err := h.eventCandidateService.AcceptCandidate(candidateID, int64(claims.UserID), &req.Event)
+	if err != nil {
+		return eventCandidateErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, event)
+}
+
+// イベント候補を却下
+func (h *EventCandidateHandler) RejectEventCandidate(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからcandidateIdを取得
+	candidateID, err := strconv.ParseInt(c.Param("candidateId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid candidate ID"})
+	}
+
+	if err := h.eventCandidateService.RejectCandidate(candidateID, int64(claims.UserID)); err != nil {
+		return eventCandidateErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Event candidate rejected"})
+}
+
+func eventCandidateErrorResponse(c echo.Context, err error) error {
+	switch err.Error() {
+	case "event candidate not found":
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Event candidate not found"})
+	case "event candidate already reviewed":
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Event candidate already reviewed"})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+}
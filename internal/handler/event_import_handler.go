@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"log"
+	"lovender_backend/internal/service"
+	"lovender_backend/pkg/jwtutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// 取り込みファイルのサイズ上限（1MB）
+const maxImportFileSize = 1 << 20
+
+type EventImportHandler struct {
+	eventImportService service.EventImportService
+}
+
+func NewEventImportHandler(eventImportService service.EventImportService) *EventImportHandler {
+	return &EventImportHandler{
+		eventImportService: eventImportService,
+	}
+}
+
+// iCalendar(.ics)またはCSVファイルから推しのイベントを一括登録
+// multipart/form-data の file フィールドでファイルを受け取る
+// 形式は format クエリ（ics|csv）、未指定ならファイルの拡張子で判定する
+func (h *EventImportHandler) ImportEvents(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshiIdを取得
+	oshiID, err := strconv.ParseInt(c.Param("oshiId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "File is required"})
+	}
+	if fileHeader.Size > maxImportFileSize {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": "File is too large"})
+	}
+
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	if format != service.EventImportFormatICS && format != service.EventImportFormatCSV {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unsupported file format"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid file"})
+	}
+	defer file.Close()
+
+	userID := int64(claims.UserID)
+
+	// イベントを取り込み
+	result, err := h.eventImportService.ImportEvents(userID, oshiID, format, file)
+	if err != nil {
+		if err.Error() == "oshi not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Oshi not found"})
+		}
+		if err.Error() == "too many events" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Too many events"})
+		}
+		if strings.HasPrefix(err.Error(), "invalid file") {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid file: " + strings.TrimPrefix(err.Error(), "invalid file: ")})
+		}
+		log.Printf("ImportEvents ERROR: service error: %v", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"lovender_backend/internal/models"
 	"lovender_backend/internal/service"
 	"lovender_backend/pkg/jwtutil"
+	"lovender_backend/pkg/rrule"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -29,15 +33,91 @@ func (h EventsHandler) GetMyOshiEvents(c echo.Context) error {
 
 	userID := int64(claims.UserID)
 
-	// ユーザーの登録した各推しのイベントを全て取得
-	events, err := h.eventsService.GetUserOshiEvents(userID)
+	// クエリパラメータから絞り込み条件を取得
+	filter, err := parseEventFilter(c)
 	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// ユーザーの登録した各推しのイベントを取得
+	events, err := h.eventsService.GetUserOshiEvents(userID, filter, c.QueryParam("cursor"))
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 	}
 
 	return c.JSON(http.StatusOK, events)
 }
 
+// イベント一覧の絞り込み条件をクエリパラメータから組み立てる
+// from, to: RFC3339形式の日時 / oshi_id[]: 推しID / category[]: カテゴリslug
+// has_alarm: true|false / source: manual|auto|import / limit: 取得件数
+func parseEventFilter(c echo.Context) (*models.EventFilter, error) {
+	filter := &models.EventFilter{}
+	params := c.QueryParams()
+
+	if from := c.QueryParam("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, errors.New("Invalid from")
+		}
+		t = t.UTC()
+		filter.From = &t
+	}
+	if to := c.QueryParam("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return nil, errors.New("Invalid to")
+		}
+		t = t.UTC()
+		filter.To = &t
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, errors.New("from must be before to")
+	}
+
+	for _, value := range append(params["oshi_id[]"], params["oshi_id"]...) {
+		oshiID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errors.New("Invalid oshi_id")
+		}
+		filter.OshiIDs = append(filter.OshiIDs, oshiID)
+	}
+
+	for _, value := range append(params["category[]"], params["category"]...) {
+		if value != "" {
+			filter.Categories = append(filter.Categories, value)
+		}
+	}
+
+	if hasAlarm := c.QueryParam("has_alarm"); hasAlarm != "" {
+		b, err := strconv.ParseBool(hasAlarm)
+		if err != nil {
+			return nil, errors.New("Invalid has_alarm")
+		}
+		filter.HasAlarm = &b
+	}
+
+	switch source := c.QueryParam("source"); source {
+	case "", "manual", "auto", "import":
+		filter.Source = source
+	default:
+		return nil, errors.New("Invalid source")
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return nil, errors.New("Invalid limit")
+		}
+		filter.Limit = n
+	}
+
+	return filter, nil
+}
+
 // 特定のイベント詳細を取得
 func (h EventsHandler) GetEventByID(c echo.Context) error {
 	// JWTトークンからユーザー情報を取得
@@ -53,10 +133,16 @@ func (h EventsHandler) GetEventByID(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
 	}
 
+	// 繰り返しイベントの回を展開する期間（from, to, limit）
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
 	userID := int64(claims.UserID)
 
 	// イベント詳細を取得
-	event, err := h.eventsService.GetEventByID(eventID, userID)
+	event, err := h.eventsService.GetEventByID(eventID, userID, filter)
 	if err != nil {
 		if err.Error() == "event not found" {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "Event not found"})
@@ -98,6 +184,10 @@ func (h EventsHandler) UpdateEvent(c echo.Context) error {
 	if req.Event.Notification_timing == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Notification timing is required"})
 	}
+	req.Event.Recurrence, err = normalizeRecurrence(req.Event.Recurrence)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid recurrence"})
+	}
 
 	// 日時をUTCに変換
 	req.Event.Starts_at = req.Event.Starts_at.UTC()
@@ -105,6 +195,9 @@ func (h EventsHandler) UpdateEvent(c echo.Context) error {
 		utcTime := req.Event.Ends_at.UTC()
 		req.Event.Ends_at = &utcTime
 	}
+	if req.Event.Ends_at != nil && req.Event.Ends_at.Before(req.Event.Starts_at) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Ends at must not be before starts at"})
+	}
 
 	userID := int64(claims.UserID)
 
@@ -147,6 +240,10 @@ func (h EventsHandler) CreateEvent(c echo.Context) error {
 	if req.Event.Notification_timing == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Notification timing is required"})
 	}
+	req.Event.Recurrence, err = normalizeRecurrence(req.Event.Recurrence)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid recurrence"})
+	}
 
 	// 日時をUTCに変換
 	req.Event.Starts_at = req.Event.Starts_at.UTC()
@@ -154,6 +251,9 @@ func (h EventsHandler) CreateEvent(c echo.Context) error {
 		utcTime := req.Event.Ends_at.UTC()
 		req.Event.Ends_at = &utcTime
 	}
+	if req.Event.Ends_at != nil && req.Event.Ends_at.Before(req.Event.Starts_at) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Ends at must not be before starts at"})
+	}
 
 	userID := int64(claims.UserID)
 
@@ -171,3 +271,193 @@ func (h EventsHandler) CreateEvent(c echo.Context) error {
 
 	return c.JSON(http.StatusCreated, event)
 }
+
+// イベントを削除（論理削除）
+func (h EventsHandler) DeleteEvent(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからeventIdを取得
+	eventIDStr := c.Param("eventId")
+	eventID, err := strconv.ParseInt(eventIDStr, 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
+	}
+
+	userID := int64(claims.UserID)
+
+	// イベント削除
+	err = h.eventsService.DeleteEvent(eventID, userID)
+	if err != nil {
+		if err.Error() == "event not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Event not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Event deleted"})
+}
+
+// 削除したイベントを復元
+func (h EventsHandler) RestoreEvent(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからeventIdを取得
+	eventIDStr := c.Param("eventId")
+	eventID, err := strconv.ParseInt(eventIDStr, 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
+	}
+
+	userID := int64(claims.UserID)
+
+	// イベント復元
+	event, err := h.eventsService.RestoreEvent(eventID, userID)
+	if err != nil {
+		if err.Error() == "event not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Event not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, event)
+}
+
+// 繰り返しイベントの回を個別に変更
+func (h EventsHandler) UpdateOccurrence(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからeventIdとrecurrenceIdを取得
+	eventID, err := strconv.ParseInt(c.Param("eventId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
+	}
+	recurrenceID, err := parseRecurrenceID(c.Param("recurrenceId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid recurrence ID"})
+	}
+
+	// リクエストBodyのバインド
+	var req models.UpdateOccurrenceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// バリデーション
+	if req.Occurrence.Title != nil && *req.Occurrence.Title == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Title must not be empty"})
+	}
+	for _, field := range req.Occurrence.Reset {
+		if !slices.Contains(models.OccurrenceResetFields, field) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid reset field: " + field})
+		}
+	}
+
+	// 日時をUTCに変換
+	if req.Occurrence.Starts_at != nil {
+		utcTime := req.Occurrence.Starts_at.UTC()
+		req.Occurrence.Starts_at = &utcTime
+	}
+	if req.Occurrence.Ends_at != nil {
+		utcTime := req.Occurrence.Ends_at.UTC()
+		req.Occurrence.Ends_at = &utcTime
+	}
+	if req.Occurrence.Starts_at != nil && req.Occurrence.Ends_at != nil && req.Occurrence.Ends_at.Before(*req.Occurrence.Starts_at) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Ends at must not be before starts at"})
+	}
+
+	userID := int64(claims.UserID)
+
+	// 回を変更
+	occurrence, err := h.eventsService.UpdateOccurrence(eventID, userID, recurrenceID, &req.Occurrence)
+	if err != nil {
+		switch err.Error() {
+		case "event not found":
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Event not found"})
+		case "occurrence not found":
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Occurrence not found"})
+		case "event is not recurring":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Event is not recurring"})
+		case "ends_at before starts_at":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Ends at must not be before starts at"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, occurrence)
+}
+
+// 繰り返しイベントの回を中止
+func (h EventsHandler) CancelOccurrence(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからeventIdとrecurrenceIdを取得
+	eventID, err := strconv.ParseInt(c.Param("eventId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid event ID"})
+	}
+	recurrenceID, err := parseRecurrenceID(c.Param("recurrenceId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid recurrence ID"})
+	}
+
+	userID := int64(claims.UserID)
+
+	// 回を中止
+	err = h.eventsService.CancelOccurrence(eventID, userID, recurrenceID)
+	if err != nil {
+		switch err.Error() {
+		case "event not found":
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Event not found"})
+		case "occurrence not found":
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Occurrence not found"})
+		case "event is not recurring":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Event is not recurring"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Occurrence cancelled"})
+}
+
+// 繰り返しルールを検証して正規化する（空文字なら繰り返しなし）
+func normalizeRecurrence(recurrence *string) (*string, error) {
+	if recurrence == nil || *recurrence == "" {
+		return nil, nil
+	}
+
+	rule, err := rrule.Parse(*recurrence)
+	if err != nil {
+		return nil, err
+	}
+	normalized := rule.String()
+	return &normalized, nil
+}
+
+// 回の指定（本来の開始日時）を解析する
+// 20260110T050000Z 形式またはRFC3339形式
+func parseRecurrenceID(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/service"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+	}
+}
+
+// 利用できるプロバイダー一覧
+func (h *OIDCHandler) GetProviders(c echo.Context) error {
+	return c.JSON(http.StatusOK, models.OIDCProvidersResponse{Providers: h.oidcService.Providers()})
+}
+
+// ログインを開始（認可リクエストのURLを返す）
+func (h *OIDCHandler) Authorize(c echo.Context) error {
+	resp, err := h.oidcService.Authorize(c.Request().Context(), c.Param("provider"))
+	if err != nil {
+		if err.Error() == "provider not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Provider not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// リダイレクト先で受け取ったcodeとstateでログイン
+func (h *OIDCHandler) Callback(c echo.Context) error {
+	var req models.OIDCCallbackRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// バリデーション
+	if req.Code == "" || req.State == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Code and state are required"})
+	}
+
+	resp, err := h.oidcService.Callback(c.Request().Context(), c.Param("provider"), &req, clientInfo(c))
+	if err != nil {
+		switch err.Error() {
+		case "provider not found":
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Provider not found"})
+		case "invalid state":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid or expired state"})
+		case "oidc authentication failed":
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication with the provider failed"})
+		case "verified email required":
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "A verified email address is required"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -1,16 +1,21 @@
 package handler
 
 import (
+	"io"
 	"log"
 	"lovender_backend/internal/models"
 	"lovender_backend/internal/service"
 	"lovender_backend/pkg/jwtutil"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
 
+// 部分更新のボディの上限
+const maxOshiPatchBodySize = 1 << 20
+
 type OshiHandler struct {
 	oshiService service.OshiService
 }
@@ -63,6 +68,9 @@ func (h *OshiHandler) CreateOshi(c echo.Context) error {
 		if err.Error() == "invalid categories provided" {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category"})
 		}
+		if err.Error() == "invalid account url" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid account URL"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create oshi"})
 	}
 
@@ -97,11 +105,290 @@ func (h *OshiHandler) UpdateOshi(c echo.Context) error {
 		if err.Error() == "oshi not found" {
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "Oshi not found"})
 		}
+		if err.Error() == "oshi already exists" {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Oshi already exists"})
+		}
 		if err.Error() == "invalid categories provided" {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category"})
 		}
+		if err.Error() == "invalid account url" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid account URL"})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update oshi"})
 	}
 
 	return c.JSON(http.StatusOK, resp)
 }
+
+// 自動検出イベントの扱い（そのまま登録 / 確認待ち）を更新
+func (h *OshiHandler) UpdateAutoEventMode(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		log.Printf("UpdateAutoEventMode ERROR: invalid token: %v", err)
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshi_idを取得
+	oshiIDStr := c.Param("oshiId")
+	oshiID, err := strconv.ParseInt(oshiIDStr, 10, 64)
+	if err != nil {
+		log.Printf("UpdateAutoEventMode ERROR: invalid oshi_id: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	var req models.UpdateAutoEventModeRequest
+	if err := c.Bind(&req); err != nil {
+		log.Printf("UpdateAutoEventMode ERROR: bind failed: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	resp, err := h.oshiService.UpdateAutoEventMode(oshiID, int64(claims.UserID), &req)
+	if err != nil {
+		if err.Error() == "invalid auto event mode" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid auto event mode"})
+		}
+		if err.Error() == "oshi not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Oshi not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update auto event mode"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// 推しの削除（?dry_run=true の場合は一緒に削除される件数だけ返す）
+func (h *OshiHandler) DeleteOshi(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		log.Printf("DeleteOshi ERROR: invalid token: %v", err)
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshi_idを取得
+	oshiIDStr := c.Param("oshiId")
+	oshiID, err := strconv.ParseInt(oshiIDStr, 10, 64)
+	if err != nil {
+		log.Printf("DeleteOshi ERROR: invalid oshi_id: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	dryRun := false
+	if dryRunStr := c.QueryParam("dry_run"); dryRunStr != "" {
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid dry_run"})
+		}
+	}
+
+	resp, err := h.oshiService.DeleteOshi(oshiID, int64(claims.UserID), dryRun)
+	if err != nil {
+		if err.Error() == "oshi not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Oshi not found"})
+		}
+		log.Printf("DeleteOshi ERROR: oshi_id=%d: %v", oshiID, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete oshi"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// 削除した推しを復元
+func (h *OshiHandler) RestoreOshi(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		log.Printf("RestoreOshi ERROR: invalid token: %v", err)
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshi_idを取得
+	oshiIDStr := c.Param("oshiId")
+	oshiID, err := strconv.ParseInt(oshiIDStr, 10, 64)
+	if err != nil {
+		log.Printf("RestoreOshi ERROR: invalid oshi_id: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	resp, err := h.oshiService.RestoreOshi(oshiID, int64(claims.UserID))
+	if err != nil {
+		if err.Error() == "oshi not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Oshi not found"})
+		}
+		if err.Error() == "oshi already exists" {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Oshi already exists"})
+		}
+		log.Printf("RestoreOshi ERROR: oshi_id=%d: %v", oshiID, err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to restore oshi"})
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// JSON Merge Patchで推しを部分更新（指定した項目のみ変更）
+func (h *OshiHandler) PatchOshi(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		log.Printf("PatchOshi ERROR: invalid token: %v", err)
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshi_idを取得
+	oshiIDStr := c.Param("oshiId")
+	oshiID, err := strconv.ParseInt(oshiIDStr, 10, 64)
+	if err != nil {
+		log.Printf("PatchOshi ERROR: invalid oshi_id: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	// application/merge-patch+json と application/json を受け付ける
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, "application/merge-patch+json") && !strings.HasPrefix(contentType, echo.MIMEApplicationJSON) {
+		return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": "Content-Type must be application/merge-patch+json"})
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxOshiPatchBodySize))
+	if err != nil {
+		log.Printf("PatchOshi ERROR: read body failed: %v", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	resp, err := h.oshiService.PatchOshi(oshiID, int64(claims.UserID), body)
+	if err != nil {
+		switch err.Error() {
+		case "invalid request body":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		case "name is required":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Name must not be empty"})
+		case "invalid color":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid color"})
+		case "invalid auto event mode":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid auto event mode"})
+		}
+		if strings.HasPrefix(err.Error(), "unknown field: ") {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown field: " + strings.TrimPrefix(err.Error(), "unknown field: ")})
+		}
+		return oshiUpdateErrorResponse(c, "PatchOshi", oshiID, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// 推しのアカウントを1件追加
+func (h *OshiHandler) AddAccount(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshi_idを取得
+	oshiID, err := strconv.ParseInt(c.Param("oshiId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	var req models.AddOshiAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	resp, err := h.oshiService.AddAccount(oshiID, int64(claims.UserID), req.URL)
+	if err != nil {
+		return oshiUpdateErrorResponse(c, "AddAccount", oshiID, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// 推しのアカウントを1件削除（?url= で指定）
+func (h *OshiHandler) RemoveAccount(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshi_idを取得
+	oshiID, err := strconv.ParseInt(c.Param("oshiId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	resp, err := h.oshiService.RemoveAccount(oshiID, int64(claims.UserID), c.QueryParam("url"))
+	if err != nil {
+		if err.Error() == "account not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Account not found"})
+		}
+		return oshiUpdateErrorResponse(c, "RemoveAccount", oshiID, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// 推しのカテゴリを1件追加
+func (h *OshiHandler) AddCategory(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshi_idを取得
+	oshiID, err := strconv.ParseInt(c.Param("oshiId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	resp, err := h.oshiService.AddCategory(oshiID, int64(claims.UserID), c.Param("slug"))
+	if err != nil {
+		return oshiUpdateErrorResponse(c, "AddCategory", oshiID, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// 推しのカテゴリを1件削除
+func (h *OshiHandler) RemoveCategory(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからoshi_idを取得
+	oshiID, err := strconv.ParseInt(c.Param("oshiId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid oshi ID"})
+	}
+
+	resp, err := h.oshiService.RemoveCategory(oshiID, int64(claims.UserID), c.Param("slug"))
+	if err != nil {
+		if err.Error() == "category not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Category not found"})
+		}
+		return oshiUpdateErrorResponse(c, "RemoveCategory", oshiID, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// 推しの部分更新で共通のエラーレスポンス
+func oshiUpdateErrorResponse(c echo.Context, name string, oshiID int64, err error) error {
+	switch err.Error() {
+	case "oshi not found":
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Oshi not found"})
+	case "oshi already exists":
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Oshi already exists"})
+	case "invalid categories provided":
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid category"})
+	case "url is required":
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "URL is required"})
+	case "invalid account url":
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid account URL"})
+	}
+	log.Printf("%s ERROR: oshi_id=%d: %v", name, oshiID, err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update oshi"})
+}
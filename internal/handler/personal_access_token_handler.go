@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/service"
+	"lovender_backend/pkg/jwtutil"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type PersonalAccessTokenHandler struct {
+	personalAccessTokenService service.PersonalAccessTokenService
+}
+
+func NewPersonalAccessTokenHandler(personalAccessTokenService service.PersonalAccessTokenService) *PersonalAccessTokenHandler {
+	return &PersonalAccessTokenHandler{
+		personalAccessTokenService: personalAccessTokenService,
+	}
+}
+
+// 個人用アクセストークン一覧を取得
+func (h *PersonalAccessTokenHandler) GetMyTokens(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	tokens, err := h.personalAccessTokenService.GetTokens(int64(claims.UserID))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// 個人用アクセストークンを新規作成（トークンはこのレスポンスでのみ返す）
+func (h *PersonalAccessTokenHandler) CreateToken(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// リクエストBodyのバインド
+	var req models.CreatePersonalAccessTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	token, err := h.personalAccessTokenService.CreateToken(int64(claims.UserID), &req)
+	if err != nil {
+		switch err.Error() {
+		case "name is required":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Name is required"})
+		case "name is too long":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Name must be at most 100 characters"})
+		case "scopes are required":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "At least one scope is required"})
+		case "invalid scope":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid scope"})
+		case "invalid expiration":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "expires_in_days must be between 1 and 365"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusCreated, token)
+}
+
+// 個人用アクセストークンを失効させる
+func (h *PersonalAccessTokenHandler) RevokeToken(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// パスパラメータからtokenIdを取得
+	tokenID, err := strconv.ParseInt(c.Param("tokenId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid token ID"})
+	}
+
+	err = h.personalAccessTokenService.RevokeToken(tokenID, int64(claims.UserID))
+	if err != nil {
+		if err.Error() == "personal access token not found" {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Personal access token not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Personal access token revoked"})
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"lovender_backend/internal/models"
 	"lovender_backend/internal/service"
 	"lovender_backend/pkg/jwtutil"
+	"math"
 	"net/http"
 	"strconv"
 
@@ -40,31 +42,119 @@ func (h *UserHandler) GetMe(c echo.Context) error {
 	}
 
 	response := models.UserInfoResponse{
-		Name:  user.Name,
-		Email: user.Email,
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
 	}
 
 	return c.JSON(http.StatusOK, response)
 }
 
-// API接続テスト用
-func (h *UserHandler) GetUser(c echo.Context) error {
-	idStr := c.Param("id")
+// 名前・メールアドレスを更新
+func (h *UserHandler) UpdateMe(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	var req models.UpdateProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// バリデーション
+	if req.Name == nil && req.Email == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Name or email is required"})
+	}
 
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	user, err := h.userService.UpdateProfile(int64(claims.UserID), &req)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+		switch err.Error() {
+		case "name is required", "email is required":
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Name and email must not be empty"})
+		case "email already exists":
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Email already exists"})
+		case "user not found":
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 	}
 
-	user, err := h.userService.GetUser(id)
+	response := models.UserInfoResponse{
+		Name:          user.Name,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// 現在のパスワードを確認してパスワードを変更
+func (h *UserHandler) ChangePassword(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
 	if err != nil {
-		if err.Error() == "user not found" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// バリデーション
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Current password and new password are required"})
+	}
+
+	if len(req.NewPassword) < 8 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Password must be at least 8 characters long"})
+	}
+
+	if err := h.userService.ChangePassword(int64(claims.UserID), claims.ID, &req); err != nil {
+		switch err.Error() {
+		case "invalid current password":
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Current password is incorrect"})
+		case "user not found":
 			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
 		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 	}
 
-	return c.JSON(http.StatusOK, user)
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password has been changed"})
+}
+
+// 退会を申請（猶予期間後に削除）
+func (h *UserHandler) DeleteMe(c echo.Context) error {
+	// JWTトークンからユーザー情報を取得
+	claims, err := jwtutil.ExtractUser(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	var req models.DeleteAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	// バリデーション
+	if req.Password == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Password is required"})
+	}
+
+	resp, err := h.userService.DeleteAccount(int64(claims.UserID), &req)
+	if err != nil {
+		switch err.Error() {
+		case "invalid password":
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Password is incorrect"})
+		case "user not found":
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusAccepted, resp)
 }
 
 func (h *UserHandler) Register(c echo.Context) error {
@@ -82,7 +172,7 @@ func (h *UserHandler) Register(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Password must be at least 8 characters long"})
 	}
 
-	resp, err := h.userService.Register(&req)
+	resp, err := h.userService.Register(&req, clientInfo(c))
 	if err != nil {
 		if err.Error() == "email already exists" {
 			return c.JSON(http.StatusConflict, map[string]string{"error": "Email already exists"})
@@ -104,8 +194,13 @@ func (h *UserHandler) Login(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Email and password are required"})
 	}
 
-	resp, err := h.userService.Login(&req)
+	resp, err := h.userService.Login(&req, clientInfo(c))
 	if err != nil {
+		var rateLimitErr *service.RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateLimitErr.RetryAfter.Seconds()))))
+			return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "Too many login attempts"})
+		}
 		if err.Error() == "invalid email or password" {
 			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid email or password"})
 		}
@@ -114,3 +209,11 @@ func (h *UserHandler) Login(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, resp)
 }
+
+// セッションに記録する接続元情報
+func clientInfo(c echo.Context) models.ClientInfo {
+	return models.ClientInfo{
+		UserAgent: c.Request().UserAgent(),
+		IPAddress: c.RealIP(),
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// 管理者向けユーザー一覧の検索条件
+type AdminUserFilter struct {
+	Query  string // メールアドレスまたは名前の部分一致
+	Limit  int
+	Offset int
+}
+
+// 管理者向けユーザー情報（推し・イベントの件数付き）
+type AdminUserItem struct {
+	ID                  int64      `json:"id"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	EmailVerified       bool       `json:"email_verified"`
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at"`
+	OshiCount           int64      `json:"oshi_count"`
+	EventCount          int64      `json:"event_count"`
+	ImportedEventCount  int64      `json:"imported_event_count"` // ファイル取り込みで登録したイベント数
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// 管理者向けユーザー一覧レスポンス
+type AdminUsersResponse struct {
+	Users   []*AdminUserItem `json:"users"`
+	Total   int64            `json:"total"`
+	Page    int              `json:"page"`
+	PerPage int              `json:"per_page"`
+}
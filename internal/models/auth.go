@@ -11,7 +11,7 @@ type RegisterRequest struct {
 type RegisterResponse struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
-	Token string `json:"token"`
+	AuthTokens
 }
 
 // ログインリクエスト
@@ -22,5 +22,5 @@ type LoginRequest struct {
 
 // ログインレスポンス
 type LoginResponse struct {
-	Token string `json:"token"`
+	AuthTokens
 }
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// カレンダーフィード情報
+type CalendarFeed struct {
+	ID        int64      `json:"id" db:"id"`
+	UserID    int64      `json:"user_id" db:"user_id"`
+	OshiID    *int64     `json:"oshi_id" db:"oshi_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at" db:"rotated_at"`
+}
+
+// カレンダーフィード作成リクエスト
+type CreateCalendarFeedRequest struct {
+	OshiID *int64 `json:"oshi_id"` // 指定した場合はその推しのイベントのみ配信
+}
+
+// カレンダーフィードレスポンス内のフィード情報
+// トークンはハッシュ化して保存するため、URLは作成時とローテーション時にのみ返す
+type CalendarFeedItem struct {
+	ID        int64      `json:"id"`
+	OshiID    *int64     `json:"oshi_id"`
+	URL       string     `json:"url,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at"`
+}
+
+// カレンダーフィードレスポンス
+type CalendarFeedResponse struct {
+	Feed CalendarFeedItem `json:"feed"`
+}
+
+// カレンダーフィード一覧レスポンス
+type CalendarFeedsResponse struct {
+	Feeds []CalendarFeedItem `json:"feeds"`
+}
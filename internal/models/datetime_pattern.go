@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// 日時抽出パターンの定義
+// groupsには役割ごとにキャプチャグループの番号を指定する
+// （year, month, day, hour, minute, end_hour, end_minute, weekday, duration_hours, duration_minutes）
+type DateTimePatternDefinition struct {
+	Name            string         `json:"name"`
+	Priority        int            `json:"priority"` // 小さいほど優先
+	Description     string         `json:"description,omitempty"`
+	Example         string         `json:"example,omitempty"` // 正規表現に一致する例（登録時に一致するか確認する）
+	Regex           string         `json:"regex"`
+	Groups          map[string]int `json:"groups,omitempty"`
+	RelativeDays    *int           `json:"relative_days,omitempty"`    // 投稿日からの日数（今日=0、明日=1）
+	WeekdayMode     string         `json:"weekday_mode,omitempty"`     // weekdayの解釈（this_week: 今週、next: 次のその曜日、next_week: 来週）
+	Meridiem        string         `json:"meridiem,omitempty"`         // 時の解釈（am: 午前、pm: 午後、night: 12未満を午後）
+	Time            string         `json:"time,omitempty"`             // 時刻の記載がない時間帯表現の時刻（"18:00"）
+	DurationMinutes int            `json:"duration_minutes,omitempty"` // 終了の記載がない場合の長さ（分、省略時は60）
+	Until           bool           `json:"until,omitempty"`            // 一致した日時を終了とし、開始は投稿日時とする（"まで"）
+}
+
+// 日時抽出パターン一覧レスポンス
+type DateTimePatternsResponse struct {
+	Patterns []DateTimePatternDefinition `json:"patterns"`
+}
+
+// 日時抽出パターンの試行リクエスト
+type DateTimePatternTestRequest struct {
+	Text     string                     `json:"text"`
+	PostedAt *time.Time                 `json:"posted_at"` // 投稿日時（省略時は現在時刻）
+	Pattern  *DateTimePatternDefinition `json:"pattern"`   // 登録せずに追加して試すパターン（同じ名前のパターンは置き換える）
+}
+
+// 日時抽出パターンの試行レスポンス
+type DateTimePatternTestResponse struct {
+	NormalizedText string            `json:"normalized_text"` // 全角数字・漢数字などを正規化した内容（パターンはこの内容に一致させる）
+	Matches        []EventExtraction `json:"matches"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// イベント候補の状態
+const (
+	EventCandidateStatusPending  = "pending"
+	EventCandidateStatusAccepted = "accepted"
+	EventCandidateStatusRejected = "rejected"
+)
+
+// 投稿から自動検出したイベント候補
+type EventCandidate struct {
+	ID              int64            `json:"id"`
+	Oshi            EventOshi        `json:"oshi"`
+	PostID          int64            `json:"post_id"`
+	Category        *CategoryItem    `json:"category"`
+	Title           string           `json:"title"`
+	Content         string           `json:"content"`
+	AccountName     string           `json:"account_name"`
+	PostCreatedAt   time.Time        `json:"post_created_at"`
+	MatchedKeywords []string         `json:"matched_keywords"`
+	MatchedPattern  string           `json:"matched_pattern"`
+	MatchedText     string           `json:"matched_text"`
+	Extraction      *EventExtraction `json:"extraction"` // 日時抽出の根拠（記録前の候補はnull）
+	StartsAt        time.Time        `json:"starts_at"`
+	EndsAt          *time.Time       `json:"ends_at"`
+	Status          string           `json:"status"`
+	EventID         *int64           `json:"event_id"` // 承認して作成したイベント
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// イベント候補一覧レスポンス
+type EventCandidatesResponse struct {
+	Candidates []EventCandidate `json:"candidates"`
+}
+
+// イベント候補を編集して承認するリクエスト
+type AcceptEventCandidateRequest struct {
+	Event UpdateEventData `json:"event"`
+}
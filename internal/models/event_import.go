@@ -0,0 +1,30 @@
+package models
+
+// イベント取り込み結果の種別
+const (
+	EventImportStatusCreated   = "created"
+	EventImportStatusDuplicate = "duplicate"
+	EventImportStatusInvalid   = "invalid"
+)
+
+// イベント取り込みの行ごとの結果
+type EventImportRowResult struct {
+	Row     int    `json:"row"` // CSVはヘッダーを除いたデータ行、iCalendarはVEVENTの順番（1始まり）
+	Status  string `json:"status"`
+	Title   string `json:"title,omitempty"`
+	EventID *int64 `json:"event_id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// イベント取り込みの件数集計
+type EventImportSummary struct {
+	Created   int `json:"created"`
+	Duplicate int `json:"duplicate"`
+	Invalid   int `json:"invalid"`
+}
+
+// イベント取り込みレスポンス
+type EventImportResponse struct {
+	Summary EventImportSummary     `json:"summary"`
+	Rows    []EventImportRowResult `json:"rows"`
+}
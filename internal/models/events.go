@@ -14,6 +14,8 @@ type Event struct {
 	Notification_timing   string        `json:"notification_timing"`
 	Has_notification_sent bool          `json:"has_notification_sent"`
 	Category              *CategoryItem `json:"category"`
+	Recurrence            *string       `json:"recurrence"`    // 繰り返しルール（RRULE）
+	Recurrence_id         *time.Time    `json:"recurrence_id"` // 繰り返しの回の本来の開始日時（単発ならnull）
 }
 
 // 各推しのイベントのカテゴリ情報
@@ -25,7 +27,24 @@ type CategoryItem struct {
 
 // 推しのイベント情報レスポンス
 type OshiEventsResponse struct {
-	Oshis []OshiEventsResponseItem `json:"oshis"`
+	Oshis      []OshiEventsResponseItem `json:"oshis"`
+	NextCursor *string                  `json:"next_cursor"`
+}
+
+// イベント一覧の絞り込み条件
+type EventFilter struct {
+	From       *time.Time // starts_at >= From
+	To         *time.Time // starts_at < To
+	OshiIDs    []int64
+	Categories []string // カテゴリのslug
+	HasAlarm   *bool
+	Source     string // "manual"（手動登録）、"auto"（自動登録）または "import"（CSVから取り込み）、空ならすべて
+	Limit      int
+	Newest     bool // 上限を超える場合に開始日時が新しい方を残す（結果は開始日時順。Toと組み合わせ、カーソルには使わない）
+
+	// カーソル（前ページ最後のイベントの開始日時とID）
+	AfterStartsAt *time.Time
+	AfterID       int64
 }
 
 // 推しのイベント情報レスポンス内の各推し情報
@@ -38,16 +57,31 @@ type OshiEventsResponseItem struct {
 
 // イベント詳細情報
 type EventDetail struct {
-	ID                    int64      `json:"id"`
-	Title                 string     `json:"title"`
-	Description           *string    `json:"description"`
-	URL                   *string    `json:"url"`
-	Starts_at             time.Time  `json:"starts_at"`
-	Ends_at               *time.Time `json:"ends_at"`
-	Has_alarm             bool       `json:"has_alarm"`
-	Notification_timing   string     `json:"notification_timing"`
-	Has_notification_sent bool       `json:"has_notification_sent"`
-	Oshi                  EventOshi  `json:"oshi"`
+	ID                    int64             `json:"id"`
+	Title                 string            `json:"title"`
+	Description           *string           `json:"description"`
+	URL                   *string           `json:"url"`
+	Starts_at             time.Time         `json:"starts_at"`
+	Ends_at               *time.Time        `json:"ends_at"`
+	Has_alarm             bool              `json:"has_alarm"`
+	Notification_timing   string            `json:"notification_timing"`
+	Has_notification_sent bool              `json:"has_notification_sent"`
+	Recurrence            *string           `json:"recurrence"`
+	Oshi                  EventOshi         `json:"oshi"`
+	Occurrences           []EventOccurrence `json:"occurrences,omitempty"` // 繰り返しイベントの場合のみ
+	Extraction            *EventExtraction  `json:"extraction,omitempty"`  // 投稿から自動登録したイベントの場合のみ
+}
+
+// 投稿から日時を抽出した結果（自動登録したイベントの根拠）
+type EventExtraction struct {
+	Pattern     string     `json:"pattern"`      // 一致したパターン名（日付と時刻を組み合わせた場合は "+" で連結）
+	MatchedText string     `json:"matched_text"` // パターンに一致した文字列
+	SpanStart   int        `json:"span_start"`   // 投稿内での開始位置（文字数）
+	SpanEnd     int        `json:"span_end"`     // 投稿内での終了位置（文字数、この位置の文字は含まない）
+	StartsAt    time.Time  `json:"starts_at"`    // 抽出した開始日時
+	EndsAt      *time.Time `json:"ends_at"`      // 抽出した終了日時
+	Confidence  float64    `json:"confidence"`   // 信頼度（0〜1）
+	Assumptions []string   `json:"assumptions"`  // 推測した内容（year_inferred, duration_inferred など）
 }
 
 // イベント詳細レスポンス用の推し情報
@@ -76,6 +110,7 @@ type UpdateEventData struct {
 	Ends_at             *time.Time `json:"ends_at"`
 	Has_alarm           bool       `json:"has_alarm"`
 	Notification_timing string     `json:"notification_timing" validate:"required"`
+	Recurrence          *string    `json:"recurrence"` // RRULE（FREQ, INTERVAL, BYDAY, COUNT, UNTIL）
 }
 
 // イベント更新レスポンス
@@ -94,6 +129,7 @@ type UpdatedEventDetail struct {
 	Has_alarm             bool       `json:"has_alarm"`
 	Notification_timing   string     `json:"notification_timing"`
 	Has_notification_sent bool       `json:"has_notification_sent"`
+	Recurrence            *string    `json:"recurrence"`
 }
 
 // イベント作成リクエスト
@@ -111,9 +147,56 @@ type CreateEventData struct {
 	Ends_at             *time.Time `json:"ends_at"`
 	Has_alarm           bool       `json:"has_alarm"`
 	Notification_timing string     `json:"notification_timing" validate:"required"`
+	Recurrence          *string    `json:"recurrence"` // RRULE（FREQ, INTERVAL, BYDAY, COUNT, UNTIL）
 }
 
 // イベント作成レスポンス
 type CreateEventResponse struct {
 	Event EventDetail `json:"event"`
 }
+
+// 繰り返しイベントの各回
+type EventOccurrence struct {
+	Recurrence_id time.Time  `json:"recurrence_id"` // 本来の開始日時（個別変更・中止の指定に使う）
+	Title         string     `json:"title"`
+	Description   *string    `json:"description"`
+	URL           *string    `json:"url"`
+	Starts_at     time.Time  `json:"starts_at"`
+	Ends_at       *time.Time `json:"ends_at"`
+	Is_overridden bool       `json:"is_overridden"`
+}
+
+// 繰り返しイベントの個別変更・中止
+type EventOccurrenceOverride struct {
+	Recurrence_id time.Time
+	Is_cancelled  bool
+	Title         *string
+	Description   *string
+	URL           *string
+	Starts_at     *time.Time
+	Ends_at       *time.Time
+	Reset         []string // 保存済みの変更から除いて繰り返し元の値に戻す項目（OccurrenceResetFields）
+}
+
+// 繰り返しイベントの回の変更で、繰り返し元の値に戻せる項目
+var OccurrenceResetFields = []string{"title", "description", "url", "starts_at", "ends_at"}
+
+// 繰り返しイベントの回の変更リクエスト
+type UpdateOccurrenceRequest struct {
+	Occurrence UpdateOccurrenceData `json:"occurrence"`
+}
+
+// 繰り返しイベントの回の変更データ（指定しない項目はこれまでの変更を引き継ぐ）
+type UpdateOccurrenceData struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	URL         *string    `json:"url"`
+	Starts_at   *time.Time `json:"starts_at"`
+	Ends_at     *time.Time `json:"ends_at"`
+	Reset       []string   `json:"reset"` // 繰り返し元の値に戻す項目（title, description, url, starts_at, ends_at）
+}
+
+// 繰り返しイベントの回のレスポンス
+type EventOccurrenceResponse struct {
+	Occurrence EventOccurrence `json:"occurrence"`
+}
@@ -29,8 +29,16 @@ type ExternalPostUser struct {
 type PostEventCandidate struct {
 	OshiID      int64
 	PostID      int64
+	SpanStart   int // 投稿内で日時が見つかった位置（文字数）
 	Content     string
 	CreatedAt   time.Time
 	AccountName string
 	Keywords    []string // マッチしたキーワード
+	CategoryID  *uint16  // 最初にマッチしたキーワードのカテゴリ
+	Title       string
+	StartsAt    time.Time
+	EndsAt      *time.Time
+	Pattern     string           // 日時抽出に使った正規表現
+	MatchedText string           // 正規表現に一致した文字列
+	Extraction  *EventExtraction // 日時抽出の根拠
 }
@@ -0,0 +1,24 @@
+package models
+
+// 送信するメール
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string // プレーンテキスト
+}
+
+// パスワード再設定メールの送信リクエスト
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// パスワード再設定リクエスト
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// メールアドレス確認リクエスト
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// NotificationTiming notification_timingと開始前の時間の対応
+type NotificationTiming struct {
+	Timing string
+	Before time.Duration
+}
+
+// NotificationTimings 指定できるnotification_timing（短い順）
+// 通知の送信（SQL）とカレンダーのアラームの両方がこの対応を使う
+var NotificationTimings = []NotificationTiming{
+	{"0", 0},
+	{"5m", 5 * time.Minute},
+	{"10m", 10 * time.Minute},
+	{"15m", 15 * time.Minute},
+	{"30m", 30 * time.Minute},
+	{"1h", time.Hour},
+	{"2h", 2 * time.Hour},
+	{"1d", 24 * time.Hour},
+	{"2d", 48 * time.Hour},
+	{"1w", 7 * 24 * time.Hour},
+}
+
+// 通知対象のイベント情報
+type NotificationEvent struct {
+	EventID            int64      `json:"event_id"`
+	RecurrenceID       *time.Time `json:"recurrence_id,omitempty"` // 繰り返しイベントの回（本来の開始日時）
+	Title              string     `json:"title"`
+	Description        *string    `json:"description"`
+	URL                *string    `json:"url"`
+	StartsAt           time.Time  `json:"starts_at"`
+	EndsAt             *time.Time `json:"ends_at"`
+	NotificationTiming string     `json:"notification_timing"`
+	NotifyAt           time.Time  `json:"notify_at"`
+	OshiID             int64      `json:"oshi_id"`
+	OshiName           string     `json:"oshi_name"`
+	UserID             int64      `json:"user_id"`
+	UserName           string     `json:"user_name"`
+	UserEmail          string     `json:"user_email"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// OpenID Connectログインの途中状態
+type OIDCLoginState struct {
+	ID           int64     `json:"id" db:"id"`
+	Provider     string    `json:"provider" db:"provider"`
+	StateHash    string    `json:"-" db:"state_hash"`
+	Nonce        string    `json:"-" db:"nonce"`
+	CodeVerifier string    `json:"-" db:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// 利用できるプロバイダー一覧レスポンス
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// 認可リクエストのURLレスポンス（クライアントはこのURLに遷移する）
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// コールバックリクエスト（リダイレクト先で受け取ったcodeとstate）
+type OIDCCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
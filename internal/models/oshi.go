@@ -4,30 +4,48 @@ import "time"
 
 // 推し情報
 type Oshi struct {
-	ID          int64     `json:"id" db:"id"`
-	UserID      int64     `json:"user_id" db:"user_id"`
-	Name        string    `json:"name" db:"name"`
-	Description *string   `json:"description" db:"description"`
-	ThemeColor  string    `json:"color" db:"theme_color"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID            int64     `json:"id" db:"id"`
+	UserID        int64     `json:"user_id" db:"user_id"`
+	Name          string    `json:"name" db:"name"`
+	Description   *string   `json:"description" db:"description"`
+	ThemeColor    string    `json:"color" db:"theme_color"`
+	AutoEventMode string    `json:"auto_event_mode" db:"auto_event_mode"` // publish: 自動検出したイベントをそのまま登録, review: 確認待ちにする
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// 自動検出イベントの扱い
+const (
+	AutoEventModePublish = "publish"
+	AutoEventModeReview  = "review"
+)
+
 // 推しのアカウント情報
 type OshiAccount struct {
 	ID        int64     `json:"id" db:"id"`
 	OshiID    int64     `json:"oshi_id" db:"oshi_id"`
-	URL       string    `json:"url" db:"url"`
+	URL       string    `json:"url" db:"url"`           // 正規化したURL
+	Platform  string    `json:"platform" db:"platform"` // x, instagram, youtube, tiktok, bluesky, custom
+	Handle    *string   `json:"handle" db:"handle"`     // customの場合はNULL
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// 推しのアカウント（レスポンス用）
+type OshiAccountResponse struct {
+	URL      string `json:"url"`
+	Platform string `json:"platform"`
+	Handle   string `json:"handle,omitempty"`
+}
+
 // 推しレスポンス
 type OshiResponse struct {
-	ID         int64    `json:"id"`
-	Name       string   `json:"name"`
-	Color      string   `json:"color"`
-	URLs       []string `json:"urls"`
-	Categories []string `json:"categories"`
+	ID            int64                 `json:"id"`
+	Name          string                `json:"name"`
+	Color         string                `json:"color"`
+	URLs          []string              `json:"urls"`
+	Accounts      []OshiAccountResponse `json:"accounts"`
+	Categories    []string              `json:"categories"`
+	AutoEventMode string                `json:"auto_event_mode"`
 }
 
 // 推し作成リクエスト
@@ -45,11 +63,12 @@ type CreateOshiResponse struct {
 
 // 推し作成レスポンス内の推し情報
 type CreateOshiResponseItem struct {
-	ID         int64    `json:"id"`
-	Name       string   `json:"name"`
-	Color      string   `json:"color"`
-	URLs       []string `json:"urls"`
-	Categories []string `json:"categories"`
+	ID         int64                 `json:"id"`
+	Name       string                `json:"name"`
+	Color      string                `json:"color"`
+	URLs       []string              `json:"urls"`
+	Accounts   []OshiAccountResponse `json:"accounts"`
+	Categories []string              `json:"categories"`
 }
 
 // 推し詳細情報
@@ -79,9 +98,60 @@ type UpdateOshiResponse struct {
 
 // 推し更新レスポンス内の推し情報
 type UpdateOshiResponseItem struct {
-	ID         int64    `json:"id"`
-	Name       string   `json:"name"`
-	Color      string   `json:"color"`
-	URLs       []string `json:"urls"`
-	Categories []string `json:"categories"`
+	ID         int64                 `json:"id"`
+	Name       string                `json:"name"`
+	Color      string                `json:"color"`
+	URLs       []string              `json:"urls"`
+	Accounts   []OshiAccountResponse `json:"accounts"`
+	Categories []string              `json:"categories"`
+}
+
+// 自動検出イベントの扱いの更新リクエスト
+type UpdateAutoEventModeRequest struct {
+	AutoEventMode string `json:"auto_event_mode"` // "publish" または "review"
+}
+
+// 自動検出イベントの扱いの更新レスポンス
+type UpdateAutoEventModeResponse struct {
+	OshiID        int64  `json:"oshi_id"`
+	AutoEventMode string `json:"auto_event_mode"`
+}
+
+// 推しの削除で一緒に削除される件数
+type OshiDeletionCounts struct {
+	Events          int64 `json:"events"`
+	DeletedEvents   int64 `json:"deleted_events"` // 削除済み（復元可能）のイベント
+	Accounts        int64 `json:"accounts"`
+	Categories      int64 `json:"categories"`
+	EventCandidates int64 `json:"event_candidates"`
+	CalendarFeeds   int64 `json:"calendar_feeds"` // この推しだけを配信するカレンダーフィード
+}
+
+// 推し削除レスポンス
+// dry_run の場合は件数のみ返し、削除しない
+type DeleteOshiResponse struct {
+	OshiID          int64              `json:"oshi_id"`
+	DryRun          bool               `json:"dry_run"`
+	Counts          OshiDeletionCounts `json:"counts"`
+	RestorableUntil *time.Time         `json:"restorable_until,omitempty"`
+}
+
+// 推し1件のレスポンス（部分更新・復元など）
+type OshiDetailResponse struct {
+	Oshi OshiResponse `json:"oshi"`
+}
+
+// 推しの部分更新（JSON Merge Patch）
+// nilの項目は変更しない。Accounts・Categoriesは指定した内容に置き換える
+type OshiPatch struct {
+	Name          *string
+	Color         *string
+	AutoEventMode *string
+	Accounts      *[]*OshiAccount
+	Categories    *[]string
+}
+
+// 推しのアカウント追加リクエスト
+type AddOshiAccountRequest struct {
+	URL string `json:"url"`
 }
@@ -6,9 +6,10 @@ type GetOshiResponse struct {
 }
 
 type GetOshiResponseItem struct {
-	ID         int64    `json:"id"`
-	Name       string   `json:"name"`
-	Color      string   `json:"color"`
-	URLs       []string `json:"urls"`
-	Categories []string `json:"categories"`
+	ID         int64                 `json:"id"`
+	Name       string                `json:"name"`
+	Color      string                `json:"color"`
+	URLs       []string              `json:"urls"`
+	Accounts   []OshiAccountResponse `json:"accounts"`
+	Categories []string              `json:"categories"`
 }
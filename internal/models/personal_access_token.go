@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// 個人用アクセストークン（PAT）情報
+type PersonalAccessToken struct {
+	ID          int64      `json:"id" db:"id"`
+	UserID      int64      `json:"user_id" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	TokenPrefix string     `json:"token_prefix" db:"token_prefix"`
+	Scopes      []string   `json:"scopes" db:"scopes"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PAT作成リクエスト
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays *int     `json:"expires_in_days"` // 省略時は30日
+}
+
+// PATレスポンス内のトークン情報
+// トークンはハッシュ化して保存するため、作成時にのみ返す
+type PersonalAccessTokenItem struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Token       string     `json:"token,omitempty"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// PATレスポンス
+type PersonalAccessTokenResponse struct {
+	PersonalAccessToken PersonalAccessTokenItem `json:"personal_access_token"`
+}
+
+// PAT一覧レスポンス
+type PersonalAccessTokensResponse struct {
+	PersonalAccessTokens []PersonalAccessTokenItem `json:"personal_access_tokens"`
+}
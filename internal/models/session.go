@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// ログインセッション
+type Session struct {
+	ID               int64      `json:"id" db:"id"`
+	UserID           int64      `json:"user_id" db:"user_id"`
+	JTI              string     `json:"-" db:"jti"`
+	RefreshTokenHash string     `json:"-" db:"refresh_token_hash"`
+	UserAgent        *string    `json:"user_agent" db:"user_agent"`
+	IPAddress        *string    `json:"ip_address" db:"ip_address"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt       time.Time  `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt        time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at" db:"revoked_at"`
+}
+
+// セッション作成時の接続元情報
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// 発行したトークン
+type AuthTokens struct {
+	Token        string `json:"token"`         // アクセストークン
+	RefreshToken string `json:"refresh_token"` // アクセストークンの再発行用
+	ExpiresIn    int    `json:"expires_in"`    // アクセストークンの有効期間（秒）
+}
+
+// トークン再発行リクエスト
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// セッション一覧レスポンス内のセッション情報
+type SessionItem struct {
+	ID         int64     `json:"id"`
+	UserAgent  *string   `json:"user_agent"`
+	IPAddress  *string   `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // リクエストに使ったセッション
+}
+
+// セッション一覧レスポンス
+type SessionsResponse struct {
+	Sessions []SessionItem `json:"sessions"`
+}
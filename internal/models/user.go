@@ -3,16 +3,49 @@ package models
 import "time"
 
 type User struct {
-	ID           int64     `json:"id" db:"id"`
-	Name         string    `json:"name" db:"name"`
-	Email        string    `json:"email" db:"email"`
-	PasswordHash string    `json:"-" db:"password_hash"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID                  int64      `json:"id" db:"id"`
+	Name                string     `json:"name" db:"name"`
+	Email               string     `json:"email" db:"email"`
+	PasswordHash        string     `json:"-" db:"password_hash"`
+	Role                string     `json:"role" db:"role"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at" db:"email_verified_at"`
+	DeletionRequestedAt *time.Time `json:"deletion_requested_at" db:"deletion_requested_at"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// ユーザーの権限
+const (
+	UserRoleUser  = "user"
+	UserRoleAdmin = "admin"
+)
+
 type UserInfoResponse struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// プロフィール更新リクエスト（指定した項目のみ更新）
+type UpdateProfileRequest struct {
+	Name  *string `json:"name"`
+	Email *string `json:"email"`
+}
+
+// パスワード変更リクエスト
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// 退会リクエスト（本人確認のためパスワードを再入力）
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// 退会レスポンス
+type DeleteAccountResponse struct {
+	Message             string    `json:"message"`
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
 }
 
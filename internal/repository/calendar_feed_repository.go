@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"lovender_backend/internal/models"
+)
+
+type CalendarFeedRepository interface {
+	Create(userID int64, oshiID *int64, tokenHash string) (*models.CalendarFeed, error)
+	GetByUserID(userID int64) ([]*models.CalendarFeed, error)
+	GetByTokenHash(tokenHash string) (*models.CalendarFeed, error)
+	Rotate(feedID int64, userID int64, tokenHash string) (*models.CalendarFeed, error)
+	Revoke(feedID int64, userID int64) error
+}
+
+type calendarFeedRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarFeedRepository(db *sql.DB) CalendarFeedRepository {
+	return &calendarFeedRepository{db: db}
+}
+
+// カレンダーフィードを作成
+func (r *calendarFeedRepository) Create(userID int64, oshiID *int64, tokenHash string) (*models.CalendarFeed, error) {
+	// 推し指定の場合はユーザーの所有する推しか確認
+	if oshiID != nil {
+		var oshiExists int
+		err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM oshis WHERE id = ? AND user_id = ? AND deleted_at IS NULL)`, *oshiID, userID).Scan(&oshiExists)
+		if err != nil {
+			return nil, err
+		}
+		if oshiExists == 0 {
+			return nil, fmt.Errorf("oshi not found")
+		}
+	}
+
+	result, err := r.db.Exec(
+		`INSERT INTO calendar_feeds (user_id, oshi_id, token_hash) VALUES (?, ?, ?)`,
+		userID, oshiID, tokenHash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calendar feed: %w", err)
+	}
+
+	feedID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.getByID(feedID, userID)
+}
+
+// ユーザーの有効なカレンダーフィード一覧を取得
+func (r *calendarFeedRepository) GetByUserID(userID int64) ([]*models.CalendarFeed, error) {
+	query := `
+		SELECT id, user_id, oshi_id, token_hash, created_at, rotated_at
+		FROM calendar_feeds
+		WHERE user_id = ? AND revoked_at IS NULL
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := make([]*models.CalendarFeed, 0)
+	for rows.Next() {
+		var feed models.CalendarFeed
+		err := rows.Scan(&feed.ID, &feed.UserID, &feed.OshiID, &feed.TokenHash, &feed.CreatedAt, &feed.RotatedAt)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, &feed)
+	}
+
+	return feeds, rows.Err()
+}
+
+// トークンのハッシュから有効なカレンダーフィードを取得
+func (r *calendarFeedRepository) GetByTokenHash(tokenHash string) (*models.CalendarFeed, error) {
+	query := `
+		SELECT id, user_id, oshi_id, token_hash, created_at, rotated_at
+		FROM calendar_feeds
+		WHERE token_hash = ? AND revoked_at IS NULL
+	`
+
+	var feed models.CalendarFeed
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&feed.ID, &feed.UserID, &feed.OshiID, &feed.TokenHash, &feed.CreatedAt, &feed.RotatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("calendar feed not found")
+		}
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+// トークンを再発行（古いURLは無効になる）
+func (r *calendarFeedRepository) Rotate(feedID int64, userID int64, tokenHash string) (*models.CalendarFeed, error) {
+	query := `
+		UPDATE calendar_feeds
+		SET token_hash = ?, rotated_at = NOW(3)
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, tokenHash, feedID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate calendar feed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("calendar feed not found")
+	}
+
+	return r.getByID(feedID, userID)
+}
+
+// カレンダーフィードを無効化
+func (r *calendarFeedRepository) Revoke(feedID int64, userID int64) error {
+	query := `
+		UPDATE calendar_feeds
+		SET revoked_at = NOW(3)
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, feedID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke calendar feed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("calendar feed not found")
+	}
+
+	return nil
+}
+
+// IDでカレンダーフィードを取得
+func (r *calendarFeedRepository) getByID(feedID int64, userID int64) (*models.CalendarFeed, error) {
+	query := `
+		SELECT id, user_id, oshi_id, token_hash, created_at, rotated_at
+		FROM calendar_feeds
+		WHERE id = ? AND user_id = ?
+	`
+
+	var feed models.CalendarFeed
+	err := r.db.QueryRow(query, feedID, userID).Scan(
+		&feed.ID, &feed.UserID, &feed.OshiID, &feed.TokenHash, &feed.CreatedAt, &feed.RotatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("calendar feed not found")
+		}
+		return nil, err
+	}
+
+	return &feed, nil
+}
@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"lovender_backend/internal/models"
+	"time"
+)
+
+type EventCandidateRepository interface {
+	Create(candidate *models.PostEventCandidate) (int64, error)
+	ExistsByPostSpanAndOshiID(postID int64, spanStart int, startsAt time.Time, oshiID int64) (bool, error)
+	GetByUserID(userID int64, status string) ([]*models.EventCandidate, error)
+	Accept(candidateID int64, userID int64, data *models.UpdateEventData) (int64, error)
+	Reject(candidateID int64, userID int64) error
+}
+
+type eventCandidateRepository struct {
+	db *sql.DB
+}
+
+func NewEventCandidateRepository(db *sql.DB) EventCandidateRepository {
+	return &eventCandidateRepository{db: db}
+}
+
+// 投稿から検出したイベント候補を確認待ちとして保存し、候補IDを返す
+func (r *eventCandidateRepository) Create(candidate *models.PostEventCandidate) (int64, error) {
+	keywords, err := json.Marshal(candidate.Keywords)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal keywords: %w", err)
+	}
+	extraction, err := marshalEventExtraction(candidate.Extraction)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO event_candidates (
+			oshi_id, post_id, post_span_start, category_id, title, content, account_name, post_created_at,
+			matched_keywords, matched_pattern, matched_text, extraction, starts_at, ends_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(
+		query,
+		candidate.OshiID,
+		candidate.PostID,
+		candidate.SpanStart,
+		candidate.CategoryID,
+		candidate.Title,
+		candidate.Content,
+		candidate.AccountName,
+		candidate.CreatedAt.UTC(),
+		string(keywords),
+		candidate.Pattern,
+		candidate.MatchedText,
+		extraction,
+		candidate.StartsAt,
+		candidate.EndsAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create event candidate: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+// 投稿内の同じ位置の日時、または同じ開始日時のイベント候補が既に存在するかチェック
+// 却下済みの候補も対象に含め、同じ日時が再び確認待ちにならないようにする
+// 位置を記録していない旧データ（post_span_startがNULL）がある投稿は処理済みとして扱う
+func (r *eventCandidateRepository) ExistsByPostSpanAndOshiID(postID int64, spanStart int, startsAt time.Time, oshiID int64) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM event_candidates
+			WHERE post_id = ? AND oshi_id = ? AND (post_span_start = ? OR post_span_start IS NULL OR starts_at = ?)
+		) AS candidate_exists
+	`
+
+	var exists bool
+	err := r.db.QueryRow(query, postID, oshiID, spanStart, startsAt).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check event candidate existence by post span and oshi_id: %w", err)
+	}
+
+	return exists, nil
+}
+
+// ユーザーの推しのイベント候補一覧を取得（statusが空なら全件）
+func (r *eventCandidateRepository) GetByUserID(userID int64, status string) ([]*models.EventCandidate, error) {
+	query := `
+		SELECT
+			ec.id, ec.post_id, ec.title, ec.content, ec.account_name, ec.post_created_at,
+			ec.matched_keywords, ec.matched_pattern, ec.matched_text, ec.extraction,
+			ec.starts_at, ec.ends_at, ec.status, ec.event_id, ec.created_at,
+			o.id, o.name, o.theme_color,
+			c.id, c.slug, c.name
+		FROM event_candidates ec
+		INNER JOIN oshis o ON ec.oshi_id = o.id
+		LEFT JOIN categories c ON ec.category_id = c.id
+		WHERE o.user_id = ? AND o.deleted_at IS NULL
+	`
+	args := []interface{}{userID}
+	if status != "" {
+		query += " AND ec.status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY ec.starts_at ASC, ec.id ASC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query event candidates: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := make([]*models.EventCandidate, 0)
+	for rows.Next() {
+		var (
+			candidate                  models.EventCandidate
+			keywords, extraction       []byte
+			categoryID                 *int64
+			categorySlug, categoryName *string
+		)
+		err := rows.Scan(
+			&candidate.ID, &candidate.PostID, &candidate.Title, &candidate.Content,
+			&candidate.AccountName, &candidate.PostCreatedAt,
+			&keywords, &candidate.MatchedPattern, &candidate.MatchedText, &extraction,
+			&candidate.StartsAt, &candidate.EndsAt, &candidate.Status, &candidate.EventID, &candidate.CreatedAt,
+			&candidate.Oshi.ID, &candidate.Oshi.Name, &candidate.Oshi.Color,
+			&categoryID, &categorySlug, &categoryName,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(keywords, &candidate.MatchedKeywords); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal matched keywords: %w", err)
+		}
+		if candidate.Extraction, err = unmarshalEventExtraction(extraction); err != nil {
+			return nil, err
+		}
+		if categoryID != nil && categorySlug != nil && categoryName != nil {
+			candidate.Category = &models.CategoryItem{
+				ID:   *categoryID,
+				Slug: *categorySlug,
+				Name: *categoryName,
+			}
+		}
+
+		candidates = append(candidates, &candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// イベント候補を承認してイベントを作成し、作成したイベントIDを返す
+// dataがnilの場合は候補の内容をそのまま登録する
+func (r *eventCandidateRepository) Accept(candidateID int64, userID int64, data *models.UpdateEventData) (int64, error) {
+	// トランザクション開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Printf("AcceptEventCandidate ERROR: failed to begin transaction: %v", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("AcceptEventCandidate ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// 同時に承認されないよう候補をロック
+	var (
+		oshiID, postID int64
+		spanStart      *int64
+		categoryID     *int64
+		extraction     *string
+		status         string
+		candidateData  models.UpdateEventData
+	)
+	err = tx.QueryRow(`
+		SELECT ec.oshi_id, ec.post_id, ec.post_span_start, ec.category_id, ec.extraction, ec.status,
+			ec.title, ec.content, ec.starts_at, ec.ends_at
+		FROM event_candidates ec
+		INNER JOIN oshis o ON ec.oshi_id = o.id
+		WHERE ec.id = ? AND o.user_id = ? AND o.deleted_at IS NULL
+		FOR UPDATE
+	`, candidateID, userID).Scan(
+		&oshiID, &postID, &spanStart, &categoryID, &extraction, &status,
+		&candidateData.Title, &candidateData.Description, &candidateData.Starts_at, &candidateData.Ends_at,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("event candidate not found")
+		}
+		return 0, err
+	}
+	if status != models.EventCandidateStatusPending {
+		err = fmt.Errorf("event candidate already reviewed")
+		return 0, err
+	}
+
+	// 自動登録と同じ通知設定を初期値にする
+	if data == nil {
+		candidateData.Has_alarm = true
+		candidateData.Notification_timing = "15m"
+		data = &candidateData
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO events (
+			oshi_id, category_id, post_id, post_span_start, source, title, description, url,
+			starts_at, ends_at, has_alarm, notification_timing, rrule, extraction
+		) VALUES (?, ?, ?, ?, 'auto', ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		oshiID, categoryID, postID, spanStart, data.Title, data.Description, data.URL,
+		data.Starts_at, data.Ends_at, data.Has_alarm, data.Notification_timing, data.Recurrence, extraction,
+	)
+	if err != nil {
+		log.Printf("AcceptEventCandidate ERROR: failed to insert event for candidate_id=%d: %v", candidateID, err)
+		return 0, fmt.Errorf("failed to create event: %w", err)
+	}
+
+	eventID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE event_candidates SET status = ?, event_id = ? WHERE id = ?`,
+		models.EventCandidateStatusAccepted, eventID, candidateID,
+	)
+	if err != nil {
+		log.Printf("AcceptEventCandidate ERROR: failed to update candidate_id=%d: %v", candidateID, err)
+		return 0, fmt.Errorf("failed to update event candidate: %w", err)
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		log.Printf("AcceptEventCandidate ERROR: failed to commit transaction: %v", err)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return eventID, nil
+}
+
+// 確認待ちのイベント候補を却下
+func (r *eventCandidateRepository) Reject(candidateID int64, userID int64) error {
+	var status string
+	err := r.db.QueryRow(`
+		SELECT ec.status
+		FROM event_candidates ec
+		INNER JOIN oshis o ON ec.oshi_id = o.id
+		WHERE ec.id = ? AND o.user_id = ? AND o.deleted_at IS NULL
+	`, candidateID, userID).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("event candidate not found")
+		}
+		return err
+	}
+
+	result, err := r.db.Exec(
+		`UPDATE event_candidates SET status = ? WHERE id = ? AND status = ?`,
+		models.EventCandidateStatusRejected, candidateID, models.EventCandidateStatusPending,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reject event candidate: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("event candidate already reviewed")
+	}
+
+	return nil
+}
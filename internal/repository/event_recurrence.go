@@ -0,0 +1,526 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"lovender_backend/internal/models"
+	"lovender_backend/pkg/rrule"
+	"sort"
+	"time"
+)
+
+// イベント詳細で返す繰り返しの回数のデフォルト
+const defaultOccurrenceLimit = 50
+
+// 繰り返しの展開に使うタイムゾーン（曜日や日付は日本時間で判定する）
+func loadRecurrenceLocation() *time.Location {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		log.Printf("Warning: Failed to load JST location, using UTC: %v", err)
+		return time.UTC
+	}
+	return jst
+}
+
+// 展開した繰り返しの1回分
+type occurrence struct {
+	recurrenceID time.Time
+	title        string
+	description  *string
+	url          *string
+	startsAt     time.Time
+	endsAt       *time.Time
+	overridden   bool
+}
+
+// 繰り返しイベントを [from, to) に開始する回に展開し、個別変更・中止を反映する
+// toがゼロ値なら上限なし。変更後の開始日時で期間を判定する
+// （本来の開始日時が期間外でも、変更後の開始日時が期間内の回は含める）
+func expandOccurrences(
+	rule *rrule.Rule,
+	title string, description, url *string,
+	startsAt time.Time, endsAt *time.Time,
+	overrides map[int64]*models.EventOccurrenceOverride,
+	from, to time.Time,
+	loc *time.Location,
+	limit int,
+) []occurrence {
+	// 中止された回の分だけ多めに展開する
+	expandLimit := 0
+	if limit > 0 {
+		expandLimit = limit + len(overrides)
+	}
+
+	var duration *time.Duration
+	if endsAt != nil {
+		d := endsAt.Sub(startsAt)
+		duration = &d
+	}
+
+	inRange := func(t time.Time) bool {
+		return !t.Before(from) && (to.IsZero() || t.Before(to))
+	}
+	newOccurrence := func(start time.Time) occurrence {
+		o := occurrence{
+			recurrenceID: start,
+			title:        title,
+			description:  description,
+			url:          url,
+			startsAt:     start,
+		}
+		if duration != nil {
+			end := start.Add(*duration)
+			o.endsAt = &end
+		}
+		return o
+	}
+
+	result := make([]occurrence, 0)
+	expanded := make(map[int64]bool)
+	for _, start := range rule.Between(startsAt, from, to, loc, expandLimit) {
+		start = start.UTC()
+		expanded[start.UnixMilli()] = true
+		o := newOccurrence(start)
+
+		if override, ok := overrides[start.UnixMilli()]; ok {
+			if override.Is_cancelled {
+				continue
+			}
+			applyOccurrenceOverride(&o, override)
+		}
+
+		if !inRange(o.startsAt) {
+			continue
+		}
+		result = append(result, o)
+	}
+
+	// 期間外の回から期間内に移動した回
+	for key, override := range overrides {
+		if expanded[key] || override.Is_cancelled || override.Starts_at == nil || !inRange(*override.Starts_at) {
+			continue
+		}
+		recurrenceID := override.Recurrence_id.UTC()
+		if !rule.Contains(startsAt, recurrenceID, loc) {
+			continue
+		}
+		o := newOccurrence(recurrenceID)
+		applyOccurrenceOverride(&o, override)
+		result = append(result, o)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].startsAt.Equal(result[j].startsAt) {
+			return result[i].startsAt.Before(result[j].startsAt)
+		}
+		return result[i].recurrenceID.Before(result[j].recurrenceID)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func applyOccurrenceOverride(o *occurrence, override *models.EventOccurrenceOverride) {
+	o.overridden = true
+	if override.Title != nil {
+		o.title = *override.Title
+	}
+	if override.Description != nil {
+		o.description = override.Description
+	}
+	if override.URL != nil {
+		o.url = override.URL
+	}
+	if override.Starts_at != nil {
+		// 開始日時だけ変えた場合は元の長さを保つ
+		if o.endsAt != nil {
+			end := override.Starts_at.Add(o.endsAt.Sub(o.startsAt))
+			o.endsAt = &end
+		}
+		o.startsAt = *override.Starts_at
+	}
+	if override.Ends_at != nil {
+		o.endsAt = override.Ends_at
+	}
+}
+
+func (o occurrence) toModel() models.EventOccurrence {
+	return models.EventOccurrence{
+		Recurrence_id: o.recurrenceID,
+		Title:         o.title,
+		Description:   o.description,
+		URL:           o.url,
+		Starts_at:     o.startsAt,
+		Ends_at:       o.endsAt,
+		Is_overridden: o.overridden,
+	}
+}
+
+// 通知済みの回を取得（event_id → 本来の開始日時のUnixミリ秒）
+// sinceを指定した場合はそれ以降に通知した回のみ
+func querySentOccurrences(db *sql.DB, eventIDs []int64, since *time.Time) (map[int64]map[int64]bool, error) {
+	result := make(map[int64]map[int64]bool)
+	if len(eventIDs) == 0 {
+		return result, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT event_id, recurrence_id
+		FROM event_occurrence_notifications
+		WHERE event_id IN (%s)
+	`, buildPlaceholders(len(eventIDs)))
+
+	args := make([]interface{}, 0, len(eventIDs)+1)
+	for _, id := range eventIDs {
+		args = append(args, id)
+	}
+	if since != nil {
+		query += " AND sent_at >= ?"
+		args = append(args, *since)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sent occurrence notifications: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			eventID      int64
+			recurrenceID time.Time
+		)
+		if err := rows.Scan(&eventID, &recurrenceID); err != nil {
+			return nil, err
+		}
+		if result[eventID] == nil {
+			result[eventID] = make(map[int64]bool)
+		}
+		result[eventID][recurrenceID.UnixMilli()] = true
+	}
+
+	return result, rows.Err()
+}
+
+// 繰り返しイベントの回が通知済みか
+// 回ごとの通知はevent_occurrence_notificationsに記録する。回ごとに記録する前に通知した最初の回はイベントのフラグで判定する
+func occurrenceNotificationSent(master *models.Event, recurrenceID time.Time, sent map[int64]bool) bool {
+	if sent[recurrenceID.UnixMilli()] {
+		return true
+	}
+	return master.Has_notification_sent && recurrenceID.Equal(master.Starts_at)
+}
+
+// 繰り返しイベントの個別変更・中止を取得（event_id → 本来の開始日時のUnixミリ秒 → 変更内容）
+func (r *eventsRepository) getOccurrenceOverrides(eventIDs []int64) (map[int64]map[int64]*models.EventOccurrenceOverride, error) {
+	return queryOccurrenceOverrides(r.db, eventIDs)
+}
+
+func queryOccurrenceOverrides(db *sql.DB, eventIDs []int64) (map[int64]map[int64]*models.EventOccurrenceOverride, error) {
+	result := make(map[int64]map[int64]*models.EventOccurrenceOverride)
+	if len(eventIDs) == 0 {
+		return result, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT event_id, recurrence_id, is_cancelled, title, description, url, starts_at, ends_at
+		FROM event_occurrence_overrides
+		WHERE event_id IN (%s)
+	`, buildPlaceholders(len(eventIDs)))
+
+	args := make([]interface{}, 0, len(eventIDs))
+	for _, id := range eventIDs {
+		args = append(args, id)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query occurrence overrides: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			eventID  int64
+			override models.EventOccurrenceOverride
+		)
+		err := rows.Scan(
+			&eventID, &override.Recurrence_id, &override.Is_cancelled,
+			&override.Title, &override.Description, &override.URL, &override.Starts_at, &override.Ends_at,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if result[eventID] == nil {
+			result[eventID] = make(map[int64]*models.EventOccurrenceOverride)
+		}
+		result[eventID][override.Recurrence_id.UnixMilli()] = &override
+	}
+
+	return result, rows.Err()
+}
+
+// 新しい開始日時・繰り返しに含まれなくなった回の個別変更・中止を削除
+// 繰り返しがなくなった場合はすべて削除する
+func (r *eventsRepository) deleteStaleOccurrenceOverrides(tx *sql.Tx, eventID int64, startsAt time.Time, recurrence *string) error {
+	if recurrence == nil {
+		if _, err := tx.Exec(`DELETE FROM event_occurrence_overrides WHERE event_id = ?`, eventID); err != nil {
+			return fmt.Errorf("failed to delete occurrence overrides: %w", err)
+		}
+		return nil
+	}
+
+	rule, err := rrule.Parse(*recurrence)
+	if err != nil {
+		return fmt.Errorf("invalid recurrence: %w", err)
+	}
+
+	rows, err := tx.Query(`SELECT recurrence_id FROM event_occurrence_overrides WHERE event_id = ?`, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to query occurrence overrides: %w", err)
+	}
+	var stale []time.Time
+	for rows.Next() {
+		var recurrenceID time.Time
+		if err := rows.Scan(&recurrenceID); err != nil {
+			rows.Close()
+			return err
+		}
+		if !rule.Contains(startsAt, recurrenceID, r.recurrenceLocation) {
+			stale = append(stale, recurrenceID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, recurrenceID := range stale {
+		_, err := tx.Exec(
+			`DELETE FROM event_occurrence_overrides WHERE event_id = ? AND recurrence_id = ?`,
+			eventID, recurrenceID.UTC(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to delete occurrence override: %w", err)
+		}
+	}
+	return nil
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// 繰り返しイベントの回を変更・中止する
+// 同じ回に対する変更は、指定した項目だけを保存済みの変更に重ねる（Resetの項目は繰り返し元の値に戻す）
+func (r *eventsRepository) UpsertOccurrenceOverride(eventID int64, userID int64, override *models.EventOccurrenceOverride) (result *models.EventOccurrence, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("UpsertOccurrenceOverride ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// 同じイベントへの変更が同時に来ても保存済みの変更を取りこぼさないようロックする
+	query := `
+		SELECT e.title, e.description, e.url, e.starts_at, e.ends_at, e.rrule
+		FROM events e
+		INNER JOIN oshis o ON e.oshi_id = o.id
+		WHERE e.id = ? AND o.user_id = ? AND o.deleted_at IS NULL AND e.deleted_at IS NULL
+		FOR UPDATE
+	`
+
+	var (
+		title       string
+		description *string
+		url         *string
+		startsAt    time.Time
+		endsAt      *time.Time
+		recurrence  *string
+	)
+	err = tx.QueryRow(query, eventID, userID).Scan(&title, &description, &url, &startsAt, &endsAt, &recurrence)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("event not found")
+		}
+		return nil, err
+	}
+	if recurrence == nil {
+		return nil, fmt.Errorf("event is not recurring")
+	}
+
+	rule, err := rrule.Parse(*recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence: %w", err)
+	}
+	if !rule.Contains(startsAt, override.Recurrence_id, r.recurrenceLocation) {
+		return nil, fmt.Errorf("occurrence not found")
+	}
+
+	existing, err := getOccurrenceOverride(tx, eventID, override.Recurrence_id.UTC())
+	if err != nil {
+		return nil, err
+	}
+	merged := mergeOccurrenceOverride(existing, override)
+
+	// 変更後の回を組み立て
+	hasChanges := merged.Title != nil || merged.Description != nil || merged.URL != nil ||
+		merged.Starts_at != nil || merged.Ends_at != nil
+	o := occurrence{
+		recurrenceID: merged.Recurrence_id.UTC(),
+		title:        title,
+		description:  description,
+		url:          url,
+		startsAt:     merged.Recurrence_id.UTC(),
+	}
+	if endsAt != nil {
+		end := o.startsAt.Add(endsAt.Sub(startsAt))
+		o.endsAt = &end
+	}
+	if hasChanges {
+		applyOccurrenceOverride(&o, merged)
+	}
+	if !merged.Is_cancelled && o.endsAt != nil && o.endsAt.Before(o.startsAt) {
+		return nil, fmt.Errorf("ends_at before starts_at")
+	}
+
+	// 変更内容がない場合は個別変更を削除して元に戻す
+	if !merged.Is_cancelled && !hasChanges {
+		_, err = tx.Exec(
+			`DELETE FROM event_occurrence_overrides WHERE event_id = ? AND recurrence_id = ?`,
+			eventID, merged.Recurrence_id.UTC(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete occurrence override: %w", err)
+		}
+	} else {
+		if err = saveOccurrenceOverride(tx, eventID, merged); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	occurrenceModel := o.toModel()
+	return &occurrenceModel, nil
+}
+
+// 保存済みの個別変更を取得（なければnil）
+func getOccurrenceOverride(tx *sql.Tx, eventID int64, recurrenceID time.Time) (*models.EventOccurrenceOverride, error) {
+	var override models.EventOccurrenceOverride
+	err := tx.QueryRow(`
+		SELECT recurrence_id, is_cancelled, title, description, url, starts_at, ends_at
+		FROM event_occurrence_overrides
+		WHERE event_id = ? AND recurrence_id = ?
+		FOR UPDATE
+	`, eventID, recurrenceID).Scan(
+		&override.Recurrence_id, &override.Is_cancelled,
+		&override.Title, &override.Description, &override.URL, &override.Starts_at, &override.Ends_at,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query occurrence override: %w", err)
+	}
+	return &override, nil
+}
+
+// 保存済みの個別変更に今回の変更を重ねる
+// 指定しなかった項目はそのまま残し、Resetの項目は繰り返し元の値に戻す
+// 中止の有無は今回の指定に従う（変更すると中止は取り消される）
+func mergeOccurrenceOverride(existing *models.EventOccurrenceOverride, override *models.EventOccurrenceOverride) *models.EventOccurrenceOverride {
+	merged := models.EventOccurrenceOverride{
+		Recurrence_id: override.Recurrence_id,
+		Is_cancelled:  override.Is_cancelled,
+	}
+	if existing != nil {
+		merged.Title = existing.Title
+		merged.Description = existing.Description
+		merged.URL = existing.URL
+		merged.Starts_at = existing.Starts_at
+		merged.Ends_at = existing.Ends_at
+	}
+
+	for _, field := range override.Reset {
+		switch field {
+		case "title":
+			merged.Title = nil
+		case "description":
+			merged.Description = nil
+		case "url":
+			merged.URL = nil
+		case "starts_at":
+			merged.Starts_at = nil
+		case "ends_at":
+			merged.Ends_at = nil
+		}
+	}
+
+	if override.Title != nil {
+		merged.Title = override.Title
+	}
+	if override.Description != nil {
+		merged.Description = override.Description
+	}
+	if override.URL != nil {
+		merged.URL = override.URL
+	}
+	if override.Starts_at != nil {
+		merged.Starts_at = override.Starts_at
+	}
+	if override.Ends_at != nil {
+		merged.Ends_at = override.Ends_at
+	}
+	return &merged
+}
+
+// 個別変更を保存（同じ回は上書き。保存済みの変更と重ねた値を渡す）
+func saveOccurrenceOverride(tx *sql.Tx, eventID int64, override *models.EventOccurrenceOverride) error {
+	upsertQuery := `
+		INSERT INTO event_occurrence_overrides (
+			event_id, recurrence_id, is_cancelled,
+			title, description, url, starts_at, ends_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			is_cancelled = VALUES(is_cancelled),
+			title = VALUES(title),
+			description = VALUES(description),
+			url = VALUES(url),
+			starts_at = VALUES(starts_at),
+			ends_at = VALUES(ends_at),
+			updated_at = NOW(3)
+	`
+
+	_, err := tx.Exec(
+		upsertQuery,
+		eventID,
+		override.Recurrence_id.UTC(),
+		override.Is_cancelled,
+		override.Title,
+		override.Description,
+		override.URL,
+		override.Starts_at,
+		override.Ends_at,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert occurrence override: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"lovender_backend/internal/models"
+	"lovender_backend/pkg/rrule"
+	"testing"
+	"time"
+)
+
+func TestExpandOccurrences(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	rule, err := rrule.Parse("FREQ=WEEKLY;COUNT=4")
+	if err != nil {
+		t.Fatalf("Parseでエラーになりました: %v", err)
+	}
+
+	startsAt := time.Date(2026, 1, 4, 16, 0, 0, 0, time.UTC) // 日本時間の月曜1時
+	endsAt := startsAt.Add(time.Hour)
+	movedStart := time.Date(2026, 1, 12, 12, 0, 0, 0, time.UTC)
+	specialTitle := "特番"
+	overrides := map[int64]*models.EventOccurrenceOverride{
+		startsAt.AddDate(0, 0, 7).UnixMilli(): {
+			Recurrence_id: startsAt.AddDate(0, 0, 7),
+			Title:         &specialTitle,
+			Starts_at:     &movedStart,
+		},
+		startsAt.AddDate(0, 0, 14).UnixMilli(): {
+			Recurrence_id: startsAt.AddDate(0, 0, 14),
+			Is_cancelled:  true,
+		},
+	}
+
+	actual := expandOccurrences(rule, "ラジオ", nil, nil, startsAt, &endsAt, overrides, time.Time{}, time.Time{}, jst, 0)
+	if len(actual) != 3 {
+		t.Fatalf("件数が一致しません: %d", len(actual))
+	}
+
+	if actual[0].title != "ラジオ" || !actual[0].startsAt.Equal(startsAt) || actual[0].overridden {
+		t.Errorf("1回目が一致しません: %+v", actual[0])
+	}
+	if actual[1].title != specialTitle || !actual[1].startsAt.Equal(movedStart) || !actual[1].overridden {
+		t.Errorf("変更した2回目が一致しません: %+v", actual[1])
+	}
+	if actual[1].endsAt == nil || !actual[1].endsAt.Equal(movedStart.Add(time.Hour)) {
+		t.Errorf("変更した2回目の終了日時が一致しません: %v", actual[1].endsAt)
+	}
+	if !actual[2].recurrenceID.Equal(startsAt.AddDate(0, 0, 21)) {
+		t.Errorf("中止した3回目が除かれていません: %+v", actual[2])
+	}
+
+	// 変更後の開始日時で期間を判定する
+	from := time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 13, 0, 0, 0, 0, time.UTC)
+	actual = expandOccurrences(rule, "ラジオ", nil, nil, startsAt, &endsAt, overrides, from, to, jst, 0)
+	if len(actual) != 1 || !actual[0].startsAt.Equal(movedStart) {
+		t.Errorf("期間内の回が一致しません: %+v", actual)
+	}
+
+	// 本来の開始日時が期間外で、変更後の開始日時が期間内の回も含める
+	movedIn := time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)
+	overrides[startsAt.AddDate(0, 0, 21).UnixMilli()] = &models.EventOccurrenceOverride{
+		Recurrence_id: startsAt.AddDate(0, 0, 21),
+		Starts_at:     &movedIn,
+	}
+	// 繰り返しに含まれない日時の変更は無視する
+	notOccurrence := startsAt.AddDate(0, 0, 1)
+	overrides[notOccurrence.UnixMilli()] = &models.EventOccurrenceOverride{
+		Recurrence_id: notOccurrence,
+		Starts_at:     &movedIn,
+	}
+	from = time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)
+	to = time.Date(2026, 1, 21, 0, 0, 0, 0, time.UTC)
+	actual = expandOccurrences(rule, "ラジオ", nil, nil, startsAt, &endsAt, overrides, from, to, jst, 0)
+	if len(actual) != 1 || !actual[0].startsAt.Equal(movedIn) || !actual[0].recurrenceID.Equal(startsAt.AddDate(0, 0, 21)) {
+		t.Errorf("期間内に移動した回が一致しません: %+v", actual)
+	}
+	if len(actual) == 1 && (actual[0].endsAt == nil || !actual[0].endsAt.Equal(movedIn.Add(time.Hour))) {
+		t.Errorf("期間内に移動した回の終了日時が一致しません: %v", actual[0].endsAt)
+	}
+}
+
+func TestOccurrenceNotificationSent(t *testing.T) {
+	startsAt := time.Date(2026, 1, 4, 16, 0, 0, 0, time.UTC)
+	second := startsAt.AddDate(0, 0, 7)
+	third := startsAt.AddDate(0, 0, 14)
+	sent := map[int64]bool{second.UnixMilli(): true}
+
+	master := &models.Event{Starts_at: startsAt}
+	tests := []struct {
+		name         string
+		legacySent   bool
+		recurrenceID time.Time
+		want         bool
+	}{
+		{"最初の回は未通知", false, startsAt, false},
+		{"2回目は回ごとの記録で通知済み", false, second, true},
+		{"3回目は未通知", false, third, false},
+		{"回ごとに記録する前に通知した最初の回はイベントのフラグで判定", true, startsAt, true},
+		{"イベントのフラグは最初の回以外に使わない", true, third, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			master.Has_notification_sent = tt.legacySent
+			if got := occurrenceNotificationSent(master, tt.recurrenceID, sent); got != tt.want {
+				t.Errorf("期待値 %v, 実際 %v", tt.want, got)
+			}
+		})
+	}
+
+	if occurrenceNotificationSent(&models.Event{Starts_at: startsAt}, second, nil) {
+		t.Errorf("通知済みの記録がない場合は未通知にするべきです")
+	}
+}
+
+func TestMergeOccurrenceOverride(t *testing.T) {
+	recurrenceID := time.Date(2026, 1, 11, 11, 0, 0, 0, time.UTC)
+	title := "特別回"
+	url := "https://example.com/special"
+	startsAt := recurrenceID.Add(time.Hour)
+
+	// 1回目の変更: タイトルだけ
+	first := mergeOccurrenceOverride(nil, &models.EventOccurrenceOverride{
+		Recurrence_id: recurrenceID,
+		Title:         &title,
+	})
+
+	// 2回目の変更: 開始日時とURLだけ（タイトルは1回目の変更を引き継ぐ）
+	second := mergeOccurrenceOverride(first, &models.EventOccurrenceOverride{
+		Recurrence_id: recurrenceID,
+		URL:           &url,
+		Starts_at:     &startsAt,
+	})
+	if second.Title == nil || *second.Title != title {
+		t.Errorf("1回目に変更したタイトルが残っていません: %v", second.Title)
+	}
+	if second.URL == nil || *second.URL != url {
+		t.Errorf("URLが変更されていません: %v", second.URL)
+	}
+	if second.Starts_at == nil || !second.Starts_at.Equal(startsAt) {
+		t.Errorf("開始日時が変更されていません: %v", second.Starts_at)
+	}
+	if second.Description != nil || second.Ends_at != nil {
+		t.Errorf("指定していない項目が設定されています: %+v", second)
+	}
+
+	// Resetで指定した項目だけ繰り返し元の値に戻す
+	third := mergeOccurrenceOverride(second, &models.EventOccurrenceOverride{
+		Recurrence_id: recurrenceID,
+		Reset:         []string{"title", "starts_at"},
+	})
+	if third.Title != nil || third.Starts_at != nil {
+		t.Errorf("Resetした項目が残っています: title=%v, starts_at=%v", third.Title, third.Starts_at)
+	}
+	if third.URL == nil || *third.URL != url {
+		t.Errorf("Resetしていない項目が消えています: %v", third.URL)
+	}
+
+	// 中止しても変更内容は残り、変更し直すと中止は取り消される
+	cancelled := mergeOccurrenceOverride(third, &models.EventOccurrenceOverride{
+		Recurrence_id: recurrenceID,
+		Is_cancelled:  true,
+	})
+	if !cancelled.Is_cancelled || cancelled.URL == nil {
+		t.Errorf("中止の記録が正しくありません: %+v", cancelled)
+	}
+	restored := mergeOccurrenceOverride(cancelled, &models.EventOccurrenceOverride{
+		Recurrence_id: recurrenceID,
+		Title:         &title,
+	})
+	if restored.Is_cancelled {
+		t.Errorf("変更し直した回が中止のままです")
+	}
+}
@@ -2,35 +2,216 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"log"
 	"lovender_backend/internal/models"
+	"lovender_backend/pkg/rrule"
+	"sort"
+	"strings"
 	"time"
 )
 
 type EventsRepository interface {
-	GetOshiEventsByUserID(userID int64) (*models.OshiEventsResponse, error)
-	GetEventByIDWithOshi(eventID int64, userID int64) (*models.EventDetail, error)
+	GetOshiEventsByUserID(userID int64, filter *models.EventFilter) (*models.OshiEventsResponse, bool, error)
+	GetEventByIDWithOshi(eventID int64, userID int64, filter *models.EventFilter) (*models.EventDetail, error)
 	UpdateEventByID(eventID int64, userID int64, req *models.UpdateEventData) (*models.UpdatedEventDetail, error)
 	CreateEventWithOshi(userID int64, req *models.CreateEventData) (*models.EventDetail, error)
-	CheckEventExistsByPostIDAndOshiID(postID int64, oshiID int64) (bool, error)
-	CreateAutoEvent(oshiID int64, postID int64, title, content string, categoryID *uint16, startsAt time.Time, endsAt *time.Time) error
+	CheckEventExistsByPostSpanAndOshiID(postID int64, spanStart int, startsAt time.Time, oshiID int64) (bool, error)
+	CreateAutoEvent(oshiID int64, postID int64, spanStart int, title, content string, categoryID *uint16, startsAt time.Time, endsAt *time.Time, extraction *models.EventExtraction) (int64, error)
 	GetAllOshisWithAccountsAndCategories() ([]*models.OshiWithDetails, error)
+	GetOshiWithAccountsAndCategoriesByID(oshiID int64, userID int64) (*models.OshiWithDetails, error)
+	ClaimOshiSync(userID int64, now time.Time, interval time.Duration) (bool, time.Time, error)
+	DeleteEventByID(eventID int64, userID int64) error
+	RestoreEventByID(eventID int64, userID int64) (*models.EventDetail, error)
+	ImportEventsWithOshi(userID int64, oshiID int64, events []*models.CreateEventData) ([]int64, error)
+	UpsertOccurrenceOverride(eventID int64, userID int64, override *models.EventOccurrenceOverride) (*models.EventOccurrence, error)
 }
 
 type eventsRepository struct {
-	db *sql.DB
+	db                 *sql.DB
+	recurrenceLocation *time.Location
 }
 
 func NewEventsRepository(db *sql.DB) EventsRepository {
-	return &eventsRepository{db: db}
+	return &eventsRepository{
+		db:                 db,
+		recurrenceLocation: loadRecurrenceLocation(),
+	}
 }
 
-func (r *eventsRepository) GetOshiEventsByUserID(userID int64) (*models.OshiEventsResponse, error) {
-	query := `
+func (r *eventsRepository) GetOshiEventsByUserID(userID int64, filter *models.EventFilter) (*models.OshiEventsResponse, bool, error) {
+	// 対象の推し一覧を取得
+	oshiQuery := `
+		SELECT id, name, theme_color
+		FROM oshis
+		WHERE user_id = ? AND deleted_at IS NULL
+	`
+	oshiArgs := []interface{}{userID}
+	if len(filter.OshiIDs) > 0 {
+		oshiQuery += fmt.Sprintf(" AND id IN (%s)", buildPlaceholders(len(filter.OshiIDs)))
+		for _, id := range filter.OshiIDs {
+			oshiArgs = append(oshiArgs, id)
+		}
+	}
+	oshiQuery += " ORDER BY id ASC"
+
+	oshiRows, err := r.db.Query(oshiQuery, oshiArgs...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer oshiRows.Close()
+
+	response := &models.OshiEventsResponse{
+		Oshis: make([]models.OshiEventsResponseItem, 0),
+	}
+	indexByOshi := make(map[int64]int)
+
+	for oshiRows.Next() {
+		var item models.OshiEventsResponseItem
+		if err := oshiRows.Scan(&item.ID, &item.Name, &item.Color); err != nil {
+			return nil, false, err
+		}
+		item.Events = make([]models.Event, 0)
+		response.Oshis = append(response.Oshis, item)
+		indexByOshi[item.ID] = len(response.Oshis) - 1
+	}
+	if err := oshiRows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	if len(response.Oshis) == 0 {
+		return response, false, nil
+	}
+
+	// 単発・繰り返しに共通の絞り込み条件
+	oshiIDs := make([]int64, 0, len(response.Oshis))
+	for _, oshi := range response.Oshis {
+		oshiIDs = append(oshiIDs, oshi.ID)
+	}
+	conditions, args := oshiEventConditions(oshiIDs, filter)
+
+	// 単発イベントを開始日時順に取得
+	singleConditions := append(append([]string{}, conditions...), "e.rrule IS NULL")
+	singleArgs := append([]interface{}{}, args...)
+	if filter.From != nil {
+		singleConditions = append(singleConditions, "e.starts_at >= ?")
+		singleArgs = append(singleArgs, *filter.From)
+	}
+	if filter.To != nil {
+		singleConditions = append(singleConditions, "e.starts_at < ?")
+		singleArgs = append(singleArgs, *filter.To)
+	}
+	if filter.AfterStartsAt != nil {
+		singleConditions = append(singleConditions, "(e.starts_at > ? OR (e.starts_at = ? AND e.id > ?))")
+		singleArgs = append(singleArgs, *filter.AfterStartsAt, *filter.AfterStartsAt, filter.AfterID)
+	}
+
+	// 次ページの有無を判定するため1件多く取得
+	singleArgs = append(singleArgs, filter.Limit+1)
+	events, err := r.queryOshiEvents(singleConditions, singleArgs, filter.Newest, "LIMIT ?")
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 繰り返しイベントは期間内の回に展開する
+	recurringConditions := append(append([]string{}, conditions...), "e.rrule IS NOT NULL")
+	recurringArgs := append([]interface{}{}, args...)
+	if filter.To != nil {
+		recurringConditions = append(recurringConditions, "e.starts_at < ?")
+		recurringArgs = append(recurringArgs, *filter.To)
+	}
+	series, err := r.queryOshiEvents(recurringConditions, recurringArgs, false, "")
+	if err != nil {
+		return nil, false, err
+	}
+	occurrences, err := r.expandOshiEvents(series, filter)
+	if err != nil {
+		return nil, false, err
+	}
+	events = append(events, occurrences...)
+
+	sortOshiEvents(events, filter.Newest)
+
+	// 上限を超えた分は次ページがあることの確認用
+	hasMore := false
+	if len(events) > filter.Limit {
+		hasMore = true
+		events = events[:filter.Limit]
+	}
+	if filter.Newest {
+		sortOshiEvents(events, false)
+	}
+
+	// 推しごとに結果を集計
+	for _, item := range events {
+		index := indexByOshi[item.oshiID]
+		response.Oshis[index].Events = append(response.Oshis[index].Events, item.event)
+	}
+
+	return response, hasMore, nil
+}
+
+// イベントを開始日時順（descなら新しい順）に並べる。同じ開始日時はイベントIDの順
+func sortOshiEvents(events []oshiEvent, desc bool) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i].event, events[j].event
+		if desc {
+			a, b = b, a
+		}
+		if !a.Starts_at.Equal(b.Starts_at) {
+			return a.Starts_at.Before(b.Starts_at)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// 推しのイベント一覧の単発・繰り返しに共通の絞り込み条件
+func oshiEventConditions(oshiIDs []int64, filter *models.EventFilter) ([]string, []interface{}) {
+	conditions := []string{
+		fmt.Sprintf("e.oshi_id IN (%s)", buildPlaceholders(len(oshiIDs))),
+		"e.deleted_at IS NULL",
+	}
+	args := make([]interface{}, 0, len(oshiIDs))
+	for _, oshiID := range oshiIDs {
+		args = append(args, oshiID)
+	}
+
+	if len(filter.Categories) > 0 {
+		conditions = append(conditions, fmt.Sprintf("c.slug IN (%s)", buildPlaceholders(len(filter.Categories))))
+		for _, slug := range filter.Categories {
+			args = append(args, slug)
+		}
+	}
+	if filter.HasAlarm != nil {
+		conditions = append(conditions, "e.has_alarm = ?")
+		args = append(args, *filter.HasAlarm)
+	}
+	// 登録元はsourceで判定する（CSVから取り込んだイベントもpost_idがNULLのため、post_idでは手動と区別できない）
+	if filter.Source != "" {
+		conditions = append(conditions, "e.source = ?")
+		args = append(args, filter.Source)
+	}
+
+	return conditions, args
+}
+
+// 推しIDとイベントの組
+type oshiEvent struct {
+	oshiID int64
+	event  models.Event
+}
+
+// 条件に合うイベントを開始日時順（descなら新しい順）に取得（idx_events_oshi_starts を使う）
+func (r *eventsRepository) queryOshiEvents(conditions []string, args []interface{}, desc bool, limitClause string) ([]oshiEvent, error) {
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+
+	eventQuery := fmt.Sprintf(`
 		SELECT
-			o.id as oshi_id,
-			o.name as oshi_name,
-			o.theme_color,
+			e.oshi_id,
 			e.id as event_id,
 			e.title as event_title,
 			e.description as event_description,
@@ -40,119 +221,135 @@ func (r *eventsRepository) GetOshiEventsByUserID(userID int64) (*models.OshiEven
 			e.has_alarm as event_has_alarm,
 			e.notification_timing as event_notification_timing,
 			e.has_notification_sent as event_has_notification_sent,
+			e.rrule as event_rrule,
 			c.id as category_id,
 			c.slug as category_slug,
 			c.name as category_name
-		FROM oshis o
-		LEFT JOIN events e ON o.id = e.oshi_id
+		FROM events e USE INDEX (idx_events_oshi_starts)
 		LEFT JOIN categories c ON e.category_id = c.id
-		WHERE o.user_id = ?
-		ORDER BY o.id ASC, e.starts_at ASC, e.id ASC
-	`
+		WHERE %s
+		ORDER BY e.starts_at %[3]s, e.id %[3]s
+		%[2]s
+	`, strings.Join(conditions, " AND "), limitClause, order)
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.Query(eventQuery, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	response := &models.OshiEventsResponse{
-		Oshis: make([]models.OshiEventsResponseItem, 0),
-	}
-	indexByOshi := make(map[int64]int)
-
+	events := make([]oshiEvent, 0)
 	for rows.Next() {
 		var (
-			oshiID                   int64
-			oshiName                 string
-			themeColor               string
-			eventID                  *int64
-			eventTitle               *string
-			eventDescription         *string
-			eventURL                 *string
-			eventStartsAt            *time.Time
-			eventEndsAt              *time.Time
-			eventHasAlarm            *bool
-			eventNotificationTiming  *string
-			eventHasNotificationSent *bool
-			categoryID               *int64
-			categorySlug             *string
-			categoryName             *string
+			item         oshiEvent
+			categoryID   *int64
+			categorySlug *string
+			categoryName *string
 		)
 
+		event := &item.event
 		err := rows.Scan(
-			&oshiID, &oshiName, &themeColor,
-			&eventID, &eventTitle, &eventDescription, &eventURL, &eventStartsAt, &eventEndsAt,
-			&eventHasAlarm, &eventNotificationTiming, &eventHasNotificationSent,
+			&item.oshiID,
+			&event.ID, &event.Title, &event.Description, &event.URL, &event.Starts_at, &event.Ends_at,
+			&event.Has_alarm, &event.Notification_timing, &event.Has_notification_sent, &event.Recurrence,
 			&categoryID, &categorySlug, &categoryName,
 		)
-
 		if err != nil {
 			return nil, err
 		}
 
-		// 推しごとに結果を集計
-		index, exists := indexByOshi[oshiID]
-		if !exists {
-			response.Oshis = append(response.Oshis, models.OshiEventsResponseItem{
-				ID:     oshiID,
-				Name:   oshiName,
-				Color:  themeColor,
-				Events: make([]models.Event, 0),
-			})
-			index = len(response.Oshis) - 1
-			indexByOshi[oshiID] = index
-		}
-
-		// イベントが無い行はスキップ
-		if eventID == nil {
-			continue
-		}
-
 		// Category: あれば詰める
-		var category *models.CategoryItem
 		if categoryID != nil && categorySlug != nil && categoryName != nil {
-			category = &models.CategoryItem{
+			event.Category = &models.CategoryItem{
 				ID:   *categoryID,
 				Slug: *categorySlug,
 				Name: *categoryName,
 			}
 		}
 
-		// モデルへ整形（nilは安全なデフォルトに落とす）
-		event := models.Event{
-			ID:                    *eventID,
-			Title:                 *eventTitle,
-			Description:           eventDescription,
-			URL:                   eventURL,
-			Starts_at:             *eventStartsAt,
-			Ends_at:               eventEndsAt,
-			Has_alarm:             *eventHasAlarm,
-			Notification_timing:   *eventNotificationTiming,
-			Has_notification_sent: *eventHasNotificationSent,
-			Category:              category,
+		events = append(events, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// 繰り返しイベントを絞り込み期間・カーソル以降の回に展開
+// 各シリーズから最大でLimit+1件（次ページ判定用）を取り出す
+func (r *eventsRepository) expandOshiEvents(series []oshiEvent, filter *models.EventFilter) ([]oshiEvent, error) {
+	if len(series) == 0 {
+		return nil, nil
+	}
+
+	eventIDs := make([]int64, 0, len(series))
+	for _, item := range series {
+		eventIDs = append(eventIDs, item.event.ID)
+	}
+	overrides, err := r.getOccurrenceOverrides(eventIDs)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := querySentOccurrences(r.db, eventIDs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var from, to time.Time
+	if filter.From != nil {
+		from = *filter.From
+	}
+	if filter.AfterStartsAt != nil && filter.AfterStartsAt.After(from) {
+		from = *filter.AfterStartsAt
+	}
+	if filter.To != nil {
+		to = *filter.To
+	}
+	// 新しい方を残す場合は期間の最後の回まで展開する（期間の指定がなければ先頭から）
+	limit := filter.Limit + 1
+	if filter.Newest && !to.IsZero() {
+		limit = 0
+	}
+
+	result := make([]oshiEvent, 0)
+	for _, item := range series {
+		rule, err := rrule.Parse(*item.event.Recurrence)
+		if err != nil {
+			log.Printf("GetOshiEventsByUserID WARNING: invalid rrule (event_id=%d): %v", item.event.ID, err)
+			continue
 		}
 
-		// 重複チェック
-		found := false
-		for _, existing := range response.Oshis[index].Events {
-			if existing.ID == event.ID {
-				found = true
-				break
+		master := item.event
+		for _, o := range expandOccurrences(
+			rule, master.Title, master.Description, master.URL, master.Starts_at, master.Ends_at,
+			overrides[master.ID], from, to, r.recurrenceLocation, limit,
+		) {
+			// カーソルと同じ開始日時の回はイベントIDで判定
+			if filter.AfterStartsAt != nil && !o.startsAt.After(*filter.AfterStartsAt) &&
+				!(o.startsAt.Equal(*filter.AfterStartsAt) && master.ID > filter.AfterID) {
+				continue
 			}
-		}
-		if !found {
-			response.Oshis[index].Events = append(response.Oshis[index].Events, event)
+
+			event := master
+			recurrenceID := o.recurrenceID
+			event.Title = o.title
+			event.Description = o.description
+			event.URL = o.url
+			event.Starts_at = o.startsAt
+			event.Ends_at = o.endsAt
+			event.Recurrence_id = &recurrenceID
+			event.Has_notification_sent = occurrenceNotificationSent(&master, recurrenceID, sent[master.ID])
+
+			result = append(result, oshiEvent{oshiID: item.oshiID, event: event})
 		}
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return response, nil
+	return result, nil
 }
 
-func (r *eventsRepository) GetEventByIDWithOshi(eventID int64, userID int64) (*models.EventDetail, error) {
+// 繰り返しイベントの場合はfilterの期間（未指定なら現在以降）の回を展開して返す
+func (r *eventsRepository) GetEventByIDWithOshi(eventID int64, userID int64, filter *models.EventFilter) (*models.EventDetail, error) {
 	query := `
 		SELECT
 			e.id as event_id,
@@ -164,12 +361,14 @@ func (r *eventsRepository) GetEventByIDWithOshi(eventID int64, userID int64) (*m
 			e.has_alarm as event_has_alarm,
 			e.notification_timing as event_notification_timing,
 			e.has_notification_sent as event_has_notification_sent,
+			e.rrule as event_rrule,
+			e.extraction as event_extraction,
 			o.id as oshi_id,
 			o.name as oshi_name,
 			o.theme_color as oshi_color
 		FROM events e
 		INNER JOIN oshis o ON e.oshi_id = o.id
-		WHERE e.id = ? AND o.user_id = ?
+		WHERE e.id = ? AND o.user_id = ? AND o.deleted_at IS NULL AND e.deleted_at IS NULL
 	`
 
 	row := r.db.QueryRow(query, eventID, userID)
@@ -183,6 +382,8 @@ func (r *eventsRepository) GetEventByIDWithOshi(eventID int64, userID int64) (*m
 		eventHasAlarm            bool
 		eventNotificationTiming  string
 		eventHasNotificationSent bool
+		eventRecurrence          *string
+		eventExtraction          []byte
 		oshiID                   int64
 		oshiName                 string
 		oshiColor                string
@@ -190,8 +391,8 @@ func (r *eventsRepository) GetEventByIDWithOshi(eventID int64, userID int64) (*m
 
 	err := row.Scan(
 		&eventID, &eventTitle, &eventDescription, &eventURL, &eventStartsAt, &eventEndsAt,
-		&eventHasAlarm, &eventNotificationTiming, &eventHasNotificationSent,
-		&oshiID, &oshiName, &oshiColor,
+		&eventHasAlarm, &eventNotificationTiming, &eventHasNotificationSent, &eventRecurrence,
+		&eventExtraction, &oshiID, &oshiName, &oshiColor,
 	)
 
 	if err != nil {
@@ -201,6 +402,11 @@ func (r *eventsRepository) GetEventByIDWithOshi(eventID int64, userID int64) (*m
 		return nil, err
 	}
 
+	extraction, err := unmarshalEventExtraction(eventExtraction)
+	if err != nil {
+		return nil, err
+	}
+
 	// EventOshiを組み立て
 	oshi := models.EventOshi{
 		ID:    oshiID,
@@ -219,48 +425,123 @@ func (r *eventsRepository) GetEventByIDWithOshi(eventID int64, userID int64) (*m
 		Has_alarm:             eventHasAlarm,
 		Notification_timing:   eventNotificationTiming,
 		Has_notification_sent: eventHasNotificationSent,
+		Recurrence:            eventRecurrence,
 		Oshi:                  oshi,
+		Extraction:            extraction,
+	}
+
+	if eventRecurrence != nil {
+		occurrences, err := r.getEventOccurrences(eventDetail, filter)
+		if err != nil {
+			return nil, err
+		}
+		eventDetail.Occurrences = occurrences
 	}
 
 	return eventDetail, nil
 }
 
+// イベント詳細用に繰り返しの回を展開
+func (r *eventsRepository) getEventOccurrences(event *models.EventDetail, filter *models.EventFilter) ([]models.EventOccurrence, error) {
+	rule, err := rrule.Parse(*event.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurrence: %w", err)
+	}
+
+	overrides, err := r.getOccurrenceOverrides([]int64{event.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	from := time.Now().UTC()
+	var to time.Time
+	limit := defaultOccurrenceLimit
+	if filter != nil {
+		if filter.From != nil {
+			from = *filter.From
+		}
+		if filter.To != nil {
+			to = *filter.To
+		}
+		if filter.Limit > 0 {
+			limit = filter.Limit
+		}
+	}
+
+	expanded := expandOccurrences(
+		rule, event.Title, event.Description, event.URL, event.Starts_at, event.Ends_at,
+		overrides[event.ID], from, to, r.recurrenceLocation, limit,
+	)
+
+	occurrences := make([]models.EventOccurrence, 0, len(expanded))
+	for _, o := range expanded {
+		occurrences = append(occurrences, o.toModel())
+	}
+	return occurrences, nil
+}
+
 func (r *eventsRepository) UpdateEventByID(eventID int64, userID int64, req *models.UpdateEventData) (*models.UpdatedEventDetail, error) {
-	// イベントがユーザーの所有する推しか確認
+	// トランザクション開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("UpdateEventByID ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// イベントがユーザーの所有する推しか確認し、変更前の開始日時と繰り返しを取得
 	checkQuery := `
-		SELECT EXISTS (
-		SELECT 1
+		SELECT e.starts_at, e.rrule
 		FROM events e
 		INNER JOIN oshis o ON e.oshi_id = o.id
-		WHERE e.id = ? AND o.user_id = ?
-		) AS event_exists
+		WHERE e.id = ? AND o.user_id = ? AND o.deleted_at IS NULL AND e.deleted_at IS NULL
+		FOR UPDATE
 	`
 
-	var count int
-	err := r.db.QueryRow(checkQuery, eventID, userID).Scan(&count)
+	var (
+		oldStartsAt   time.Time
+		oldRecurrence *string
+	)
+	err = tx.QueryRow(checkQuery, eventID, userID).Scan(&oldStartsAt, &oldRecurrence)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("event not found")
+		}
 		return nil, err
 	}
-	if count == 0 {
-		return nil, fmt.Errorf("event not found")
-	}
 
 	// イベント更新
+	// 開始日時か通知タイミングが変わった場合は新しい通知時刻で再通知できるよう通知済みフラグを戻す
+	// （MySQLはSET句を左から評価するため、starts_atの更新より前に判定する）
 	updateQuery := `
 		UPDATE events 
-		SET title = ?,
+		SET has_notification_sent = CASE
+		        WHEN starts_at <> ? OR NOT (notification_timing <=> ?) THEN 0
+		        ELSE has_notification_sent
+		    END,
+		    title = ?,
 		    description = ?,
 		    url = ?,
 		    starts_at = ?,
 		    ends_at = ?,
 		    has_alarm = ?,
 		    notification_timing = ?,
+		    rrule = ?,
 		    updated_at = NOW(3)
 		WHERE id = ?
 	`
 
-	_, err = r.db.Exec(
+	_, err = tx.Exec(
 		updateQuery,
+		req.Starts_at,
+		req.Notification_timing,
 		req.Title,
 		req.Description,
 		req.URL,
@@ -268,16 +549,29 @@ func (r *eventsRepository) UpdateEventByID(eventID int64, userID int64, req *mod
 		req.Ends_at,
 		req.Has_alarm,
 		req.Notification_timing,
+		req.Recurrence,
 		eventID)
 	if err != nil {
 		return nil, err
 	}
 
+	// 開始日時か繰り返しが変わった場合、存在しなくなった回の個別変更・中止を削除
+	if !oldStartsAt.Equal(req.Starts_at) || !equalStringPtr(oldRecurrence, req.Recurrence) {
+		if err = r.deleteStaleOccurrenceOverrides(tx, eventID, req.Starts_at, req.Recurrence); err != nil {
+			return nil, err
+		}
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	// 更新されたイベント情報を取得
 	selectQuery := `
 		SELECT 
 			id, title, description, url, starts_at, ends_at, 
-			has_alarm, notification_timing, has_notification_sent
+			has_alarm, notification_timing, has_notification_sent, rrule
 		FROM events 
 		WHERE id = ?
 	`
@@ -294,10 +588,11 @@ func (r *eventsRepository) UpdateEventByID(eventID int64, userID int64, req *mod
 		hasAlarm            bool
 		notificationTiming  string
 		hasNotificationSent bool
+		recurrence          *string
 	)
 
 	err = row.Scan(&id, &title, &description, &url, &startsAt, &endsAt,
-		&hasAlarm, &notificationTiming, &hasNotificationSent)
+		&hasAlarm, &notificationTiming, &hasNotificationSent, &recurrence)
 	if err != nil {
 		return nil, err
 	}
@@ -312,6 +607,7 @@ func (r *eventsRepository) UpdateEventByID(eventID int64, userID int64, req *mod
 		Has_alarm:             hasAlarm,
 		Notification_timing:   notificationTiming,
 		Has_notification_sent: hasNotificationSent,
+		Recurrence:            recurrence,
 	}, nil
 }
 
@@ -321,7 +617,7 @@ func (r *eventsRepository) CreateEventWithOshi(userID int64, req *models.CreateE
 		SELECT EXISTS (
 		SELECT 1
 		FROM oshis
-		WHERE id = ? AND user_id = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
 		) AS oshi_exists
 	`
 
@@ -337,8 +633,8 @@ func (r *eventsRepository) CreateEventWithOshi(userID int64, req *models.CreateE
 	insertQuery := `
 		INSERT INTO events (
 			oshi_id, title, description, url,
-			starts_at, ends_at, has_alarm, notification_timing
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			starts_at, ends_at, has_alarm, notification_timing, rrule
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.Exec(
@@ -351,6 +647,7 @@ func (r *eventsRepository) CreateEventWithOshi(userID int64, req *models.CreateE
 		req.Ends_at,
 		req.Has_alarm,
 		req.Notification_timing,
+		req.Recurrence,
 	)
 	if err != nil {
 		return nil, err
@@ -363,52 +660,143 @@ func (r *eventsRepository) CreateEventWithOshi(userID int64, req *models.CreateE
 	}
 
 	// 作成されたイベント詳細を取得
-	return r.GetEventByIDWithOshi(eventID, userID)
+	return r.GetEventByIDWithOshi(eventID, userID, nil)
 }
 
-// 投稿IDと推しIDでイベントが既に存在するかチェック
-func (r *eventsRepository) CheckEventExistsByPostIDAndOshiID(postID int64, oshiID int64) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM events WHERE post_id = ? AND oshi_id = ?) AS event_exists`
+// 投稿内の同じ位置の日時、または同じ開始日時で作成したイベントが既に存在するかチェック
+// 投稿の編集やパターンの変更で位置がずれた場合や、同じ日時が投稿内に複数回書かれている場合も重複して作成しない
+// 位置を記録していない旧データ（post_span_startがNULL）がある投稿は処理済みとして扱う
+func (r *eventsRepository) CheckEventExistsByPostSpanAndOshiID(postID int64, spanStart int, startsAt time.Time, oshiID int64) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM events
+			WHERE post_id = ? AND oshi_id = ? AND (post_span_start = ? OR post_span_start IS NULL OR starts_at = ?)
+		) AS event_exists
+	`
 
 	var exists bool
-	err := r.db.QueryRow(query, postID, oshiID).Scan(&exists)
+	err := r.db.QueryRow(query, postID, oshiID, spanStart, startsAt).Scan(&exists)
 	if err != nil {
-		return false, fmt.Errorf("failed to check event existence by post_id and oshi_id: %w", err)
+		return false, fmt.Errorf("failed to check event existence by post span and oshi_id: %w", err)
 	}
 
 	return exists, nil
 }
 
-// 自動イベント作成
-func (r *eventsRepository) CreateAutoEvent(oshiID int64, postID int64, title, content string, categoryID *uint16, startsAt time.Time, endsAt *time.Time) error {
+// 自動イベント作成（作成したイベントIDを返す）
+func (r *eventsRepository) CreateAutoEvent(oshiID int64, postID int64, spanStart int, title, content string, categoryID *uint16, startsAt time.Time, endsAt *time.Time, extraction *models.EventExtraction) (int64, error) {
 	query := `
 		INSERT INTO events (
-			oshi_id, category_id, post_id, title, description, 
-			starts_at, ends_at, has_alarm, notification_timing
-		) VALUES (?, ?, ?, ?, ?, ?, ?, 1, '15m')
+			oshi_id, category_id, post_id, post_span_start, source, title, description, 
+			starts_at, ends_at, has_alarm, notification_timing, extraction
+		) VALUES (?, ?, ?, ?, 'auto', ?, ?, ?, ?, 1, '15m', ?)
 	`
 
-	_, err := r.db.Exec(query, oshiID, categoryID, postID, title, content, startsAt, endsAt)
+	extractionJSON, err := marshalEventExtraction(extraction)
 	if err != nil {
-		return fmt.Errorf("failed to create auto event: %w", err)
+		return 0, err
 	}
 
-	return nil
+	result, err := r.db.Exec(query, oshiID, categoryID, postID, spanStart, title, content, startsAt, endsAt, extractionJSON)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create auto event: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+// 日時抽出の根拠をJSON文字列に変換（nilの場合はNULL）
+func marshalEventExtraction(extraction *models.EventExtraction) (*string, error) {
+	if extraction == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(extraction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal extraction: %w", err)
+	}
+	extractionJSON := string(data)
+	return &extractionJSON, nil
+}
+
+// JSONから日時抽出の根拠を復元（NULLの場合はnil）
+func unmarshalEventExtraction(data []byte) (*models.EventExtraction, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var extraction models.EventExtraction
+	if err := json.Unmarshal(data, &extraction); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal extraction: %w", err)
+	}
+	return &extraction, nil
+}
+
+// ユーザーの推しの投稿の手動取り込みを記録する（全インスタンスで共有するためDBで判定）
+// 前回からintervalが経過していない場合は記録せずにfalseと前回の日時を返す
+func (r *eventsRepository) ClaimOshiSync(userID int64, now time.Time, interval time.Duration) (bool, time.Time, error) {
+	now = now.UTC()
+	result, err := r.db.Exec(`
+		UPDATE users
+		SET oshi_synced_at = ?, updated_at = updated_at
+		WHERE id = ? AND (oshi_synced_at IS NULL OR oshi_synced_at <= ?)
+	`, now, userID, now.Add(-interval))
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("failed to update oshi_synced_at: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected > 0 {
+		return true, time.Time{}, nil
+	}
+
+	var syncedAt sql.NullTime
+	err = r.db.QueryRow(`SELECT oshi_synced_at FROM users WHERE id = ?`, userID).Scan(&syncedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, time.Time{}, fmt.Errorf("user not found")
+		}
+		return false, time.Time{}, fmt.Errorf("failed to query oshi_synced_at: %w", err)
+	}
+	if !syncedAt.Valid {
+		return false, time.Time{}, fmt.Errorf("failed to record oshi sync")
+	}
+	return false, syncedAt.Time, nil
 }
 
 // 全ユーザーの推し情報を取得（アカウントとカテゴリ付き）
 func (r *eventsRepository) GetAllOshisWithAccountsAndCategories() ([]*models.OshiWithDetails, error) {
-	query := `
+	return r.queryOshisWithAccountsAndCategories("1 = 1")
+}
+
+// ユーザーの推し1件を取得（アカウントとカテゴリ付き）
+func (r *eventsRepository) GetOshiWithAccountsAndCategoriesByID(oshiID int64, userID int64) (*models.OshiWithDetails, error) {
+	oshis, err := r.queryOshisWithAccountsAndCategories("o.id = ? AND o.user_id = ?", oshiID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(oshis) == 0 {
+		return nil, fmt.Errorf("oshi not found")
+	}
+	return oshis[0], nil
+}
+
+// 推し情報をアカウントとカテゴリ付きで取得
+func (r *eventsRepository) queryOshisWithAccountsAndCategories(whereClause string, args ...interface{}) ([]*models.OshiWithDetails, error) {
+	query := fmt.Sprintf(`
 		SELECT 
 			o.id as oshi_id,
 			o.user_id,
 			o.name as oshi_name,
 			o.description as oshi_description,
 			o.theme_color,
+			o.auto_event_mode,
 			o.created_at as oshi_created_at,
 			o.updated_at as oshi_updated_at,
 			oa.id as account_id,
 			oa.url as account_url,
+			oa.platform as account_platform,
+			oa.handle as account_handle,
 			oa.created_at as account_created_at,
 			c.id as category_id,
 			c.slug as category_slug,
@@ -420,12 +808,13 @@ func (r *eventsRepository) GetAllOshisWithAccountsAndCategories() ([]*models.Osh
 		LEFT JOIN oshi_accounts oa ON o.id = oa.oshi_id
 		LEFT JOIN oshi_categories oc ON o.id = oc.oshi_id
 		LEFT JOIN categories c ON oc.category_id = c.id
+		WHERE o.deleted_at IS NULL AND (%s)
 		ORDER BY o.id ASC, oa.created_at ASC, c.name ASC
-	`
+	`, whereClause)
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query all oshis: %w", err)
+		return nil, fmt.Errorf("failed to query oshis: %w", err)
 	}
 	defer rows.Close()
 
@@ -435,19 +824,19 @@ func (r *eventsRepository) GetAllOshisWithAccountsAndCategories() ([]*models.Osh
 		var (
 			oshiID, userIDResult                            int64
 			accountID, categoryID                           *int64
-			oshiName, themeColor                            string
+			oshiName, themeColor, autoEventMode             string
 			oshiDescription                                 *string
 			oshiCreatedAt, oshiUpdatedAt                    time.Time
-			accountURL                                      *string
+			accountURL, accountPlatform, accountHandle      *string
 			accountCreatedAt                                *time.Time
 			categorySlug, categoryName, categoryDescription *string
 			categoryCreatedAt, categoryUpdatedAt            *time.Time
 		)
 
 		err := rows.Scan(
-			&oshiID, &userIDResult, &oshiName, &oshiDescription, &themeColor,
+			&oshiID, &userIDResult, &oshiName, &oshiDescription, &themeColor, &autoEventMode,
 			&oshiCreatedAt, &oshiUpdatedAt,
-			&accountID, &accountURL, &accountCreatedAt,
+			&accountID, &accountURL, &accountPlatform, &accountHandle, &accountCreatedAt,
 			&categoryID, &categorySlug, &categoryName, &categoryDescription,
 			&categoryCreatedAt, &categoryUpdatedAt,
 		)
@@ -459,13 +848,14 @@ func (r *eventsRepository) GetAllOshisWithAccountsAndCategories() ([]*models.Osh
 		if _, exists := oshiMap[oshiID]; !exists {
 			oshiMap[oshiID] = &models.OshiWithDetails{
 				Oshi: &models.Oshi{
-					ID:          oshiID,
-					UserID:      userIDResult,
-					Name:        oshiName,
-					Description: oshiDescription,
-					ThemeColor:  themeColor,
-					CreatedAt:   oshiCreatedAt,
-					UpdatedAt:   oshiUpdatedAt,
+					ID:            oshiID,
+					UserID:        userIDResult,
+					Name:          oshiName,
+					Description:   oshiDescription,
+					ThemeColor:    themeColor,
+					AutoEventMode: autoEventMode,
+					CreatedAt:     oshiCreatedAt,
+					UpdatedAt:     oshiUpdatedAt,
 				},
 				Accounts:   []*models.OshiAccount{},
 				Categories: []*models.Category{},
@@ -473,11 +863,13 @@ func (r *eventsRepository) GetAllOshisWithAccountsAndCategories() ([]*models.Osh
 		}
 
 		// アカウント情報を追加
-		if accountID != nil && accountURL != nil && accountCreatedAt != nil {
+		if accountID != nil && accountURL != nil && accountPlatform != nil && accountCreatedAt != nil {
 			account := &models.OshiAccount{
 				ID:        *accountID,
 				OshiID:    oshiID,
 				URL:       *accountURL,
+				Platform:  *accountPlatform,
+				Handle:    accountHandle,
 				CreatedAt: *accountCreatedAt,
 			}
 			// 重複チェック
@@ -529,3 +921,148 @@ func (r *eventsRepository) GetAllOshisWithAccountsAndCategories() ([]*models.Osh
 
 	return result, nil
 }
+
+// イベントを論理削除
+func (r *eventsRepository) DeleteEventByID(eventID int64, userID int64) error {
+	query := `
+		UPDATE events e
+		INNER JOIN oshis o ON e.oshi_id = o.id
+		SET e.deleted_at = NOW(3)
+		WHERE e.id = ? AND o.user_id = ? AND o.deleted_at IS NULL AND e.deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, eventID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete event: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("event not found")
+	}
+
+	return nil
+}
+
+// 論理削除したイベントを復元
+func (r *eventsRepository) RestoreEventByID(eventID int64, userID int64) (*models.EventDetail, error) {
+	query := `
+		UPDATE events e
+		INNER JOIN oshis o ON e.oshi_id = o.id
+		SET e.deleted_at = NULL
+		WHERE e.id = ? AND o.user_id = ? AND o.deleted_at IS NULL AND e.deleted_at IS NOT NULL
+	`
+
+	result, err := r.db.Exec(query, eventID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore event: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("event not found")
+	}
+
+	// 復元されたイベント詳細を取得
+	return r.GetEventByIDWithOshi(eventID, userID, nil)
+}
+
+// 推しのイベントを一括登録（1トランザクション）
+// 戻り値は入力と同じ順番の作成されたイベントIDで、同じタイトル・開始日時のイベントが既にある場合は0
+func (r *eventsRepository) ImportEventsWithOshi(userID int64, oshiID int64, events []*models.CreateEventData) (eventIDs []int64, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("ImportEventsWithOshi ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// 推しがユーザーの所有するものか確認（取り込み中の削除を防ぐためロック）
+	var lockedOshiID int64
+	err = tx.QueryRow(`SELECT id FROM oshis WHERE id = ? AND user_id = ? AND deleted_at IS NULL FOR UPDATE`, oshiID, userID).Scan(&lockedOshiID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("oshi not found")
+		}
+		return nil, err
+	}
+
+	existsQuery := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM events
+			WHERE oshi_id = ? AND title = ? AND starts_at = ? AND deleted_at IS NULL
+		) AS event_exists
+	`
+	insertQuery := `
+		INSERT INTO events (
+			oshi_id, source, title, description, url,
+			starts_at, ends_at, has_alarm, notification_timing, rrule
+		) VALUES (?, 'import', ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	eventIDs = make([]int64, len(events))
+	for i, event := range events {
+		// 取り込みファイル内の重複も登録済みのイベントとして判定される
+		var exists bool
+		err = tx.QueryRow(existsQuery, oshiID, event.Title, event.Starts_at).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check duplicate event: %w", err)
+		}
+		if exists {
+			continue
+		}
+
+		var result sql.Result
+		result, err = tx.Exec(
+			insertQuery,
+			oshiID,
+			event.Title,
+			event.Description,
+			event.URL,
+			event.Starts_at,
+			event.Ends_at,
+			event.Has_alarm,
+			event.Notification_timing,
+			event.Recurrence,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert event: %w", err)
+		}
+
+		eventIDs[i], err = result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return eventIDs, nil
+}
+
+// IN句用のプレースホルダーを生成 (例: "?,?,?")
+func buildPlaceholders(count int) string {
+	if count <= 0 {
+		return ""
+	}
+	placeholders := strings.Repeat("?,", count)
+	// 最後のカンマを削除
+	return placeholders[:len(placeholders)-1]
+}
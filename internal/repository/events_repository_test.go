@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"lovender_backend/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOshiEventConditions(t *testing.T) {
+	hasAlarm := true
+
+	tests := []struct {
+		name           string
+		filter         *models.EventFilter
+		wantConditions []string
+		wantArgs       []interface{}
+	}{
+		{
+			name:           "絞り込みなし",
+			filter:         &models.EventFilter{},
+			wantConditions: []string{"e.oshi_id IN (?,?)", "e.deleted_at IS NULL"},
+			wantArgs:       []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:           "手動登録のみ（取り込んだイベントは含めない）",
+			filter:         &models.EventFilter{Source: "manual"},
+			wantConditions: []string{"e.oshi_id IN (?,?)", "e.deleted_at IS NULL", "e.source = ?"},
+			wantArgs:       []interface{}{int64(1), int64(2), "manual"},
+		},
+		{
+			name:           "取り込んだイベントのみ",
+			filter:         &models.EventFilter{Source: "import"},
+			wantConditions: []string{"e.oshi_id IN (?,?)", "e.deleted_at IS NULL", "e.source = ?"},
+			wantArgs:       []interface{}{int64(1), int64(2), "import"},
+		},
+		{
+			name:           "カテゴリ・アラーム・登録元",
+			filter:         &models.EventFilter{Categories: []string{"live", "tv"}, HasAlarm: &hasAlarm, Source: "auto"},
+			wantConditions: []string{"e.oshi_id IN (?,?)", "e.deleted_at IS NULL", "c.slug IN (?,?)", "e.has_alarm = ?", "e.source = ?"},
+			wantArgs:       []interface{}{int64(1), int64(2), "live", "tv", true, "auto"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conditions, args := oshiEventConditions([]int64{1, 2}, tt.filter)
+			if !reflect.DeepEqual(conditions, tt.wantConditions) {
+				t.Errorf("条件: 期待値 %v, 実際 %v", tt.wantConditions, conditions)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("引数: 期待値 %v, 実際 %v", tt.wantArgs, args)
+			}
+		})
+	}
+}
+
+func TestSortOshiEvents(t *testing.T) {
+	at := func(day int) time.Time { return time.Date(2026, 10, day, 12, 0, 0, 0, time.UTC) }
+	events := []oshiEvent{
+		{oshiID: 1, event: models.Event{ID: 3, Starts_at: at(2)}},
+		{oshiID: 1, event: models.Event{ID: 1, Starts_at: at(3)}},
+		{oshiID: 2, event: models.Event{ID: 2, Starts_at: at(2)}},
+		{oshiID: 2, event: models.Event{ID: 4, Starts_at: at(1)}},
+	}
+	ids := func() []int64 {
+		result := make([]int64, 0, len(events))
+		for _, item := range events {
+			result = append(result, item.event.ID)
+		}
+		return result
+	}
+
+	// 新しい順に並べて上限で切ると、古いものから除かれる
+	sortOshiEvents(events, true)
+	if want := []int64{1, 3, 2, 4}; !reflect.DeepEqual(ids(), want) {
+		t.Errorf("新しい順: 期待値 %v, 実際 %v", want, ids())
+	}
+	events = events[:3]
+
+	sortOshiEvents(events, false)
+	if want := []int64{2, 3, 1}; !reflect.DeepEqual(ids(), want) {
+		t.Errorf("開始日時順: 期待値 %v, 実際 %v", want, ids())
+	}
+}
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"lovender_backend/internal/models"
+	"lovender_backend/pkg/rrule"
+	"sort"
+	"strings"
+	"time"
+)
+
+type NotificationRepository interface {
+	GetDueNotificationEvents(now time.Time, grace time.Duration, limit int) ([]*models.NotificationEvent, error)
+	MarkNotificationSent(event *models.NotificationEvent) (bool, error)
+	ResetNotificationSent(event *models.NotificationEvent) error
+}
+
+type notificationRepository struct {
+	db                 *sql.DB
+	recurrenceLocation *time.Location
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{
+		db:                 db,
+		recurrenceLocation: loadRecurrenceLocation(),
+	}
+}
+
+// 通知する期間（開始の何日前まで通知タイミングを指定できるか）
+var notificationMaxLead = models.NotificationTimings[len(models.NotificationTimings)-1].Before
+
+// notification_timingを分に変換するSQL式
+var notificationOffsetMinutesExpr = buildNotificationOffsetMinutesExpr(models.NotificationTimings)
+
+// models.NotificationTimingsからCASE式を組み立てる（未知の値は開始時刻に通知する）
+func buildNotificationOffsetMinutesExpr(timings []models.NotificationTiming) string {
+	var b strings.Builder
+	b.WriteString("\n\tCASE e.notification_timing")
+	for _, t := range timings {
+		if t.Before == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\t\tWHEN '%s' THEN %d", t.Timing, int64(t.Before/time.Minute))
+	}
+	b.WriteString("\n\t\tELSE 0\n\tEND")
+	return b.String()
+}
+
+// 通知時刻を過ぎた未通知のイベントを取得（繰り返しイベントは回ごと）
+// 開始からgraceを超えて経過したイベントは通知しても意味がないため対象外とする
+func (r *notificationRepository) GetDueNotificationEvents(now time.Time, grace time.Duration, limit int) ([]*models.NotificationEvent, error) {
+	events, err := r.getDueSingleEvents(now, grace, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := r.getDueOccurrences(now, grace)
+	if err != nil {
+		return nil, err
+	}
+
+	events = append(events, occurrences...)
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].StartsAt.Equal(events[j].StartsAt) {
+			return events[i].StartsAt.Before(events[j].StartsAt)
+		}
+		return events[i].EventID < events[j].EventID
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+// 通知時刻を過ぎた未通知の繰り返しのないイベントを取得
+func (r *notificationRepository) getDueSingleEvents(now time.Time, grace time.Duration, limit int) ([]*models.NotificationEvent, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			e.id,
+			e.title,
+			e.description,
+			e.url,
+			e.starts_at,
+			e.ends_at,
+			COALESCE(e.notification_timing, '0') as notification_timing,
+			DATE_SUB(e.starts_at, INTERVAL %[1]s MINUTE) as notify_at,
+			o.id as oshi_id,
+			o.name as oshi_name,
+			u.id as user_id,
+			u.name as user_name,
+			u.email as user_email
+		FROM events e
+		INNER JOIN oshis o ON e.oshi_id = o.id
+		INNER JOIN users u ON o.user_id = u.id
+		WHERE e.has_alarm = 1
+		  AND e.deleted_at IS NULL
+		  AND o.deleted_at IS NULL
+		  AND e.rrule IS NULL
+		  AND COALESCE(e.has_notification_sent, 0) = 0
+		  AND e.starts_at >= ?
+		  AND e.starts_at <= DATE_ADD(?, INTERVAL 1 WEEK)
+		  AND DATE_SUB(e.starts_at, INTERVAL %[1]s MINUTE) <= ?
+		ORDER BY e.starts_at ASC, e.id ASC
+		LIMIT ?
+	`, notificationOffsetMinutesExpr)
+
+	rows, err := r.db.Query(query, now.Add(-grace), now, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query due notification events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.NotificationEvent
+	for rows.Next() {
+		var event models.NotificationEvent
+		err := rows.Scan(
+			&event.EventID, &event.Title, &event.Description, &event.URL,
+			&event.StartsAt, &event.EndsAt, &event.NotificationTiming, &event.NotifyAt,
+			&event.OshiID, &event.OshiName,
+			&event.UserID, &event.UserName, &event.UserEmail,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan notification event: %w", err)
+		}
+		events = append(events, &event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification rows: %w", err)
+	}
+
+	return events, nil
+}
+
+// 通知時刻を過ぎた未通知の繰り返しイベントの回を取得
+// 回ごとの個別変更（開始日時の変更・中止）を反映し、通知済みの回は event_occurrence_notifications で判定する
+func (r *notificationRepository) getDueOccurrences(now time.Time, grace time.Duration) ([]*models.NotificationEvent, error) {
+	query := fmt.Sprintf(`
+		SELECT
+			e.id,
+			e.title,
+			e.description,
+			e.url,
+			e.starts_at,
+			e.ends_at,
+			COALESCE(e.notification_timing, '0') as notification_timing,
+			%[1]s as notification_offset_minutes,
+			e.rrule,
+			o.id as oshi_id,
+			o.name as oshi_name,
+			u.id as user_id,
+			u.name as user_name,
+			u.email as user_email
+		FROM events e
+		INNER JOIN oshis o ON e.oshi_id = o.id
+		INNER JOIN users u ON o.user_id = u.id
+		WHERE e.has_alarm = 1
+		  AND e.deleted_at IS NULL
+		  AND o.deleted_at IS NULL
+		  AND e.rrule IS NOT NULL
+		  AND e.starts_at <= DATE_ADD(?, INTERVAL 1 WEEK)
+		ORDER BY e.id ASC
+	`, notificationOffsetMinutesExpr)
+
+	rows, err := r.db.Query(query, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recurring notification events: %w", err)
+	}
+	defer rows.Close()
+
+	type recurringEvent struct {
+		event  models.NotificationEvent
+		offset time.Duration
+		rule   *rrule.Rule
+	}
+
+	var series []recurringEvent
+	var eventIDs []int64
+	for rows.Next() {
+		var (
+			item          recurringEvent
+			offsetMinutes int
+			recurrence    string
+		)
+		err := rows.Scan(
+			&item.event.EventID, &item.event.Title, &item.event.Description, &item.event.URL,
+			&item.event.StartsAt, &item.event.EndsAt, &item.event.NotificationTiming, &offsetMinutes, &recurrence,
+			&item.event.OshiID, &item.event.OshiName,
+			&item.event.UserID, &item.event.UserName, &item.event.UserEmail,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recurring notification event: %w", err)
+		}
+
+		item.rule, err = rrule.Parse(recurrence)
+		if err != nil {
+			log.Printf("GetDueNotificationEvents ERROR: invalid recurrence for event_id=%d: %v", item.event.EventID, err)
+			continue
+		}
+		item.offset = time.Duration(offsetMinutes) * time.Minute
+		series = append(series, item)
+		eventIDs = append(eventIDs, item.event.EventID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recurring notification rows: %w", err)
+	}
+	if len(series) == 0 {
+		return nil, nil
+	}
+
+	overrides, err := queryOccurrenceOverrides(r.db, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := r.getSentOccurrences(eventIDs, now.Add(-grace-notificationMaxLead))
+	if err != nil {
+		return nil, err
+	}
+
+	var events []*models.NotificationEvent
+	for _, item := range series {
+		events = append(events, dueOccurrenceNotifications(
+			&item.event, item.rule, item.offset, overrides[item.event.EventID], sent[item.event.EventID],
+			now, grace, r.recurrenceLocation,
+		)...)
+	}
+	return events, nil
+}
+
+// 通知済みの回を取得（event_id → 本来の開始日時のUnixミリ秒）
+// 通知時刻は開始の1週間前より後のため、since（graceと1週間を引いた時刻）より前に通知した回は対象外とする
+func (r *notificationRepository) getSentOccurrences(eventIDs []int64, since time.Time) (map[int64]map[int64]bool, error) {
+	return querySentOccurrences(r.db, eventIDs, &since)
+}
+
+// 繰り返しイベントのうち、通知時刻を過ぎて開始から grace 以内の未通知の回を返す
+func dueOccurrenceNotifications(
+	series *models.NotificationEvent,
+	rule *rrule.Rule,
+	offset time.Duration,
+	overrides map[int64]*models.EventOccurrenceOverride,
+	sent map[int64]bool,
+	now time.Time,
+	grace time.Duration,
+	loc *time.Location,
+) []*models.NotificationEvent {
+	// 変更後の開始日時が [now-grace, now+1週間] の回
+	from := now.Add(-grace)
+	to := now.Add(notificationMaxLead + time.Millisecond)
+	occurrences := expandOccurrences(rule, series.Title, series.Description, series.URL, series.StartsAt, series.EndsAt, overrides, from, to, loc, 0)
+
+	var events []*models.NotificationEvent
+	for _, o := range occurrences {
+		notifyAt := o.startsAt.Add(-offset)
+		if notifyAt.After(now) || sent[o.recurrenceID.UnixMilli()] {
+			continue
+		}
+
+		event := *series
+		recurrenceID := o.recurrenceID
+		event.RecurrenceID = &recurrenceID
+		event.Title = o.title
+		event.Description = o.description
+		event.URL = o.url
+		event.StartsAt = o.startsAt
+		event.EndsAt = o.endsAt
+		event.NotifyAt = notifyAt
+		events = append(events, &event)
+	}
+	return events
+}
+
+// 通知済みにする（繰り返しイベントは回ごと）
+// 未通知の場合のみ更新するため、複数インスタンスが同じイベントを取得しても送信権を得るのは1つだけ
+func (r *notificationRepository) MarkNotificationSent(event *models.NotificationEvent) (bool, error) {
+	var (
+		result sql.Result
+		err    error
+	)
+	if event.RecurrenceID != nil {
+		result, err = r.db.Exec(
+			`INSERT IGNORE INTO event_occurrence_notifications (event_id, recurrence_id) VALUES (?, ?)`,
+			event.EventID, event.RecurrenceID.UTC(),
+		)
+	} else {
+		result, err = r.db.Exec(`
+			UPDATE events
+			SET has_notification_sent = 1
+			WHERE id = ? AND COALESCE(has_notification_sent, 0) = 0
+		`, event.EventID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to mark notification sent: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// 送信に失敗した場合に通知済みを戻す（次回の実行で再送される）
+func (r *notificationRepository) ResetNotificationSent(event *models.NotificationEvent) error {
+	var err error
+	if event.RecurrenceID != nil {
+		_, err = r.db.Exec(
+			`DELETE FROM event_occurrence_notifications WHERE event_id = ? AND recurrence_id = ?`,
+			event.EventID, event.RecurrenceID.UTC(),
+		)
+	} else {
+		_, err = r.db.Exec(`UPDATE events SET has_notification_sent = 0 WHERE id = ?`, event.EventID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reset notification sent: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"fmt"
+	"lovender_backend/internal/models"
+	"lovender_backend/pkg/rrule"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDueOccurrenceNotifications(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	rule, err := rrule.Parse("FREQ=WEEKLY")
+	if err != nil {
+		t.Fatalf("Parseでエラーになりました: %v", err)
+	}
+
+	// 毎週日曜 日本時間20時、15分前に通知
+	startsAt := time.Date(2026, 1, 4, 11, 0, 0, 0, time.UTC)
+	series := &models.NotificationEvent{EventID: 1, Title: "ラジオ", StartsAt: startsAt}
+	offset := 15 * time.Minute
+	grace := 10 * time.Minute
+
+	// 3回目の通知時刻
+	third := startsAt.AddDate(0, 0, 14)
+	now := third.Add(-offset)
+	actual := dueOccurrenceNotifications(series, rule, offset, nil, nil, now, grace, jst)
+	if len(actual) != 1 {
+		t.Fatalf("件数が一致しません: %d", len(actual))
+	}
+	if actual[0].RecurrenceID == nil || !actual[0].RecurrenceID.Equal(third) || !actual[0].StartsAt.Equal(third) {
+		t.Errorf("3回目が通知されません: %+v", actual[0])
+	}
+	if !actual[0].NotifyAt.Equal(now) {
+		t.Errorf("通知時刻が一致しません: %v", actual[0].NotifyAt)
+	}
+
+	// 通知時刻の前は対象外
+	if actual := dueOccurrenceNotifications(series, rule, offset, nil, nil, now.Add(-time.Minute), grace, jst); len(actual) != 0 {
+		t.Errorf("通知時刻の前に通知されました: %+v", actual)
+	}
+
+	// 通知済みの回は対象外
+	sent := map[int64]bool{third.UnixMilli(): true}
+	if actual := dueOccurrenceNotifications(series, rule, offset, nil, sent, now, grace, jst); len(actual) != 0 {
+		t.Errorf("通知済みの回が通知されました: %+v", actual)
+	}
+
+	// 中止した回は対象外
+	overrides := map[int64]*models.EventOccurrenceOverride{
+		third.UnixMilli(): {Recurrence_id: third, Is_cancelled: true},
+	}
+	if actual := dueOccurrenceNotifications(series, rule, offset, overrides, nil, now, grace, jst); len(actual) != 0 {
+		t.Errorf("中止した回が通知されました: %+v", actual)
+	}
+
+	// 開始日時を変更した回は変更後の時刻で通知する
+	moved := third.Add(2 * time.Hour)
+	title := "特番"
+	overrides = map[int64]*models.EventOccurrenceOverride{
+		third.UnixMilli(): {Recurrence_id: third, Title: &title, Starts_at: &moved},
+	}
+	if actual := dueOccurrenceNotifications(series, rule, offset, overrides, nil, now, grace, jst); len(actual) != 0 {
+		t.Errorf("変更前の時刻で通知されました: %+v", actual)
+	}
+	actual = dueOccurrenceNotifications(series, rule, offset, overrides, nil, moved.Add(-offset), grace, jst)
+	if len(actual) != 1 || !actual[0].StartsAt.Equal(moved) || actual[0].Title != title || !actual[0].RecurrenceID.Equal(third) {
+		t.Errorf("変更後の時刻で通知されません: %+v", actual)
+	}
+}
+
+func TestBuildNotificationOffsetMinutesExpr(t *testing.T) {
+	expr := buildNotificationOffsetMinutesExpr([]models.NotificationTiming{
+		{Timing: "0", Before: 0},
+		{Timing: "15m", Before: 15 * time.Minute},
+		{Timing: "1d", Before: 24 * time.Hour},
+	})
+	expected := "\n\tCASE e.notification_timing\n\t\tWHEN '15m' THEN 15\n\t\tWHEN '1d' THEN 1440\n\t\tELSE 0\n\tEND"
+	if expr != expected {
+		t.Errorf("CASE式が違います: %q, 期待値 %q", expr, expected)
+	}
+
+	// 通知の送信とカレンダーのアラームで同じタイミングを扱う
+	for _, timing := range models.NotificationTimings {
+		if timing.Before == 0 {
+			continue
+		}
+		when := fmt.Sprintf("WHEN '%s' THEN %d", timing.Timing, int64(timing.Before/time.Minute))
+		if !strings.Contains(notificationOffsetMinutesExpr, when) {
+			t.Errorf("%sがCASE式に含まれていません", timing.Timing)
+		}
+	}
+	if notificationMaxLead != 7*24*time.Hour {
+		t.Errorf("notificationMaxLead = %v, 期待値 1週間", notificationMaxLead)
+	}
+}
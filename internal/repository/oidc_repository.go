@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"lovender_backend/internal/models"
+)
+
+type OIDCRepository interface {
+	CreateLoginState(state *models.OIDCLoginState) error
+	ConsumeLoginState(stateHash string, provider string) (*models.OIDCLoginState, error)
+	GetUserIDByIdentity(provider string, subject string) (int64, error)
+	LinkIdentity(userID int64, provider string, subject string, email string) error
+	LinkIdentityResettingCredentials(userID int64, provider string, subject string, email string, passwordHash string) error
+	CreateUserWithIdentity(user *models.User, provider string, subject string) error
+}
+
+type oidcRepository struct {
+	db *sql.DB
+}
+
+func NewOIDCRepository(db *sql.DB) OIDCRepository {
+	return &oidcRepository{db: db}
+}
+
+// ログインの途中状態を保存（期限切れの状態はついでに削除する）
+func (r *oidcRepository) CreateLoginState(state *models.OIDCLoginState) error {
+	if _, err := r.db.Exec(`DELETE FROM oidc_login_states WHERE expires_at < NOW(3) LIMIT 100`); err != nil {
+		log.Printf("CreateLoginState WARN: failed to delete expired states: %v", err)
+	}
+
+	result, err := r.db.Exec(
+		`INSERT INTO oidc_login_states (provider, state_hash, nonce, code_verifier, expires_at) VALUES (?, ?, ?, ?, ?)`,
+		state.Provider, state.StateHash, state.Nonce, state.CodeVerifier, state.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create oidc login state: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	state.ID = id
+	return nil
+}
+
+// 有効な途中状態を取得して削除する（1回限り）
+func (r *oidcRepository) ConsumeLoginState(stateHash string, provider string) (*models.OIDCLoginState, error) {
+	query := `
+		SELECT id, provider, state_hash, nonce, code_verifier, expires_at
+		FROM oidc_login_states
+		WHERE state_hash = ? AND provider = ? AND expires_at > NOW(3)
+	`
+
+	var state models.OIDCLoginState
+	err := r.db.QueryRow(query, stateHash, provider).Scan(
+		&state.ID,
+		&state.Provider,
+		&state.StateHash,
+		&state.Nonce,
+		&state.CodeVerifier,
+		&state.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid state")
+		}
+		return nil, err
+	}
+
+	// 同時に同じstateが使われた場合は削除できた方だけを有効にする
+	result, err := r.db.Exec(`DELETE FROM oidc_login_states WHERE id = ?`, state.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume oidc login state: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("invalid state")
+	}
+
+	return &state, nil
+}
+
+// プロバイダーのアカウントに紐付いたユーザーIDを取得（未登録なら0）
+func (r *oidcRepository) GetUserIDByIdentity(provider string, subject string) (int64, error) {
+	var userID int64
+	err := r.db.QueryRow(
+		`SELECT user_id FROM user_identities WHERE provider = ? AND subject = ?`,
+		provider, subject,
+	).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if _, err := r.db.Exec(
+		`UPDATE user_identities SET last_login_at = NOW(3) WHERE provider = ? AND subject = ?`,
+		provider, subject,
+	); err != nil {
+		log.Printf("GetUserIDByIdentity WARN: failed to update last_login_at: %v", err)
+	}
+
+	return userID, nil
+}
+
+// メールアドレスを確認済みの既存ユーザーにプロバイダーのアカウントを紐付ける
+func (r *oidcRepository) LinkIdentity(userID int64, provider string, subject string, email string) error {
+	return r.linkIdentity("LinkIdentity", userID, provider, subject, email, "")
+}
+
+// メールアドレスが未確認の既存ユーザーにプロバイダーのアカウントを紐付ける
+// 第三者がメールアドレスを確認せずに先に登録した可能性があるため、パスワードを使えない値に置き換え、
+// すべてのセッションと個人用アクセストークンを失効させてから紐付ける
+func (r *oidcRepository) LinkIdentityResettingCredentials(userID int64, provider string, subject string, email string, passwordHash string) error {
+	return r.linkIdentity("LinkIdentityResettingCredentials", userID, provider, subject, email, passwordHash)
+}
+
+// プロバイダーで確認済みのメールアドレスで紐付けるため、未確認ならメールアドレスも確認済みにする
+// passwordHashを指定した場合はパスワードを置き換え、既存の認証情報をすべて失効させる
+func (r *oidcRepository) linkIdentity(funcName string, userID int64, provider string, subject string, email string, passwordHash string) error {
+	// トランザクション開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("%s ERROR: failed to rollback transaction: %v", funcName, rollbackErr)
+			}
+		}
+	}()
+
+	if passwordHash != "" {
+		if _, err = tx.Exec(`UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, userID); err != nil {
+			return fmt.Errorf("failed to reset password: %w", err)
+		}
+		if _, err = tx.Exec(`UPDATE sessions SET revoked_at = NOW(3) WHERE user_id = ? AND revoked_at IS NULL`, userID); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		if _, err = tx.Exec(`UPDATE personal_access_tokens SET revoked_at = NOW(3) WHERE user_id = ? AND revoked_at IS NULL`, userID); err != nil {
+			return fmt.Errorf("failed to revoke personal access tokens: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO user_identities (user_id, provider, subject, email) VALUES (?, ?, ?, ?)`,
+		userID, provider, subject, email,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW(3)) WHERE id = ? AND email = ?`,
+		userID, email,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// プロバイダーのアカウントで新しいユーザーを作成（メールアドレスは確認済み）
+func (r *oidcRepository) CreateUserWithIdentity(user *models.User, provider string, subject string) error {
+	// トランザクション開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("CreateUserWithIdentity ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	result, err := tx.Exec(
+		`INSERT INTO users (name, email, password_hash, email_verified_at) VALUES (?, ?, ?, NOW(3))`,
+		user.Name, user.Email, user.PasswordHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	userID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO user_identities (user_id, provider, subject, email) VALUES (?, ?, ?, ?)`,
+		userID, provider, subject, user.Email,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	user.ID = userID
+	user.Role = models.UserRoleUser
+	return nil
+}
@@ -5,15 +5,26 @@ import (
 	"fmt"
 	"log"
 	"lovender_backend/internal/models"
+	"sort"
 	"strings"
 	"time"
 )
 
 type OshiRepository interface {
 	GetOshisWithDetailsByUserID(userID int64) ([]*models.OshiWithDetails, error)
-	CreateOshiWithTransaction(oshi *models.Oshi, urls []string, categories []string) (int64, error)
+	CreateOshiWithTransaction(oshi *models.Oshi, accounts []*models.OshiAccount, categories []string) (int64, error)
 	GetOshiByIDAndUserID(oshiID int64, userID int64) (*models.OshiWithDetails, error)
-	UpdateOshiWithTransaction(oshiID int64, userID int64, oshi *models.Oshi, urls []string, categories []string) error
+	UpdateOshiWithTransaction(oshiID int64, userID int64, oshi *models.Oshi, accounts []*models.OshiAccount, categories []string) error
+	PatchOshi(oshiID int64, userID int64, patch *models.OshiPatch) error
+	AddAccount(oshiID int64, userID int64, account *models.OshiAccount) error
+	RemoveAccount(oshiID int64, userID int64, url string) error
+	AddCategory(oshiID int64, userID int64, slug string) error
+	RemoveCategory(oshiID int64, userID int64, slug string) error
+	UpdateAutoEventMode(oshiID int64, userID int64, mode string) error
+	GetDeletionCounts(oshiID int64, userID int64) (*models.OshiDeletionCounts, error)
+	DeleteOshi(oshiID int64, userID int64) (*models.OshiDeletionCounts, time.Time, error)
+	RestoreOshi(oshiID int64, userID int64, deletedAfter time.Time) error
+	PurgeDeleted(before time.Time, limit int) (int64, error)
 }
 
 type oshiRepository struct {
@@ -26,12 +37,12 @@ func NewOshiRepository(db *sql.DB) OshiRepository {
 
 // ユーザーIDで推し一覧を取得
 func (r *oshiRepository) GetOshisWithDetailsByUserID(userID int64) ([]*models.OshiWithDetails, error) {
-	return r.queryOshisWithDetails("o.user_id = ?", userID)
+	return r.queryOshisWithDetails("o.user_id = ? AND o.deleted_at IS NULL", userID)
 }
 
 // 推しをIDとユーザーIDで取得
 func (r *oshiRepository) GetOshiByIDAndUserID(oshiID int64, userID int64) (*models.OshiWithDetails, error) {
-	results, err := r.queryOshisWithDetails("o.id = ? AND o.user_id = ?", oshiID, userID)
+	results, err := r.queryOshisWithDetails("o.id = ? AND o.user_id = ? AND o.deleted_at IS NULL", oshiID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +53,7 @@ func (r *oshiRepository) GetOshiByIDAndUserID(oshiID int64, userID int64) (*mode
 }
 
 // 推し、アカウント、カテゴリを作成
-func (r *oshiRepository) CreateOshiWithTransaction(oshi *models.Oshi, urls []string, categories []string) (int64, error) {
+func (r *oshiRepository) CreateOshiWithTransaction(oshi *models.Oshi, accounts []*models.OshiAccount, categories []string) (int64, error) {
 	// トランザクション開始
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -79,8 +90,8 @@ func (r *oshiRepository) CreateOshiWithTransaction(oshi *models.Oshi, urls []str
 	}
 
 	// アカウントを一括追加
-	if len(urls) > 0 {
-		err = r.addAccountsInTransaction(tx, oshiID, urls)
+	if len(accounts) > 0 {
+		err = r.addAccountsInTransaction(tx, oshiID, accounts)
 		if err != nil {
 			return 0, err
 		}
@@ -100,32 +111,32 @@ func (r *oshiRepository) CreateOshiWithTransaction(oshi *models.Oshi, urls []str
 		return 0, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("CreateOshiWithTransaction SUCCESS: created oshi_id=%d with %d urls and %d categories",
-		oshiID, len(urls), len(categories))
+	log.Printf("CreateOshiWithTransaction SUCCESS: created oshi_id=%d with %d accounts and %d categories",
+		oshiID, len(accounts), len(categories))
 	return oshiID, nil
 }
 
 // アカウントを一括追加
-func (r *oshiRepository) addAccountsInTransaction(tx *sql.Tx, oshiID int64, urls []string) error {
-	if len(urls) == 0 {
+func (r *oshiRepository) addAccountsInTransaction(tx *sql.Tx, oshiID int64, accounts []*models.OshiAccount) error {
+	if len(accounts) == 0 {
 		return nil
 	}
 
 	// バッチINSERTのためのクエリ構築
-	urlCount := len(urls)
-	const paramsPerURL = 3 // oshi_id, url, created_at
+	accountCount := len(accounts)
+	const paramsPerAccount = 5 // oshi_id, url, platform, handle, created_at
 
-	valueStrings := make([]string, 0, urlCount)
-	valueArgs := make([]interface{}, 0, urlCount*paramsPerURL)
+	valueStrings := make([]string, 0, accountCount)
+	valueArgs := make([]interface{}, 0, accountCount*paramsPerAccount)
 	now := time.Now()
 
-	for _, url := range urls {
-		valueStrings = append(valueStrings, "(?, ?, ?)")
-		valueArgs = append(valueArgs, oshiID, url, now)
+	for _, account := range accounts {
+		valueStrings = append(valueStrings, "(?, ?, ?, ?, ?)")
+		valueArgs = append(valueArgs, oshiID, account.URL, account.Platform, account.Handle, now)
 	}
 
 	query := fmt.Sprintf(`
-		INSERT INTO oshi_accounts (oshi_id, url, created_at) 
+		INSERT INTO oshi_accounts (oshi_id, url, platform, handle, created_at) 
 		VALUES %s
 	`, strings.Join(valueStrings, ","))
 
@@ -214,8 +225,8 @@ func (r *oshiRepository) addCategoriesInTransaction(tx *sql.Tx, oshiID int64, ca
 	return nil
 }
 
-// 推し情報を更新
-func (r *oshiRepository) UpdateOshiWithTransaction(oshiID int64, userID int64, oshi *models.Oshi, urls []string, categories []string) error {
+// 推し情報を更新（アカウント・カテゴリは指定した内容に置き換える）
+func (r *oshiRepository) UpdateOshiWithTransaction(oshiID int64, userID int64, oshi *models.Oshi, accounts []*models.OshiAccount, categories []string) error {
 	// トランザクション開始
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -237,7 +248,7 @@ func (r *oshiRepository) UpdateOshiWithTransaction(oshiID int64, userID int64, o
 	oshiQuery := `
 		UPDATE oshis 
 		SET name = ?, description = ?, theme_color = ?, updated_at = ?
-		WHERE id = ? AND user_id = ?
+		WHERE id = ? AND user_id = ? AND deleted_at IS NULL
 	`
 	result, err := tx.Exec(oshiQuery, oshi.Name, oshi.Description, oshi.ThemeColor, now, oshiID, userID)
 	if err != nil {
@@ -255,46 +266,468 @@ func (r *oshiRepository) UpdateOshiWithTransaction(oshiID int64, userID int64, o
 		return fmt.Errorf("oshi not found or not owned by user")
 	}
 
-	// 既存のアカウントを削除
-	_, err = tx.Exec("DELETE FROM oshi_accounts WHERE oshi_id = ?", oshiID)
-	if err != nil {
-		log.Printf("UpdateOshiWithTransaction ERROR: failed to delete existing accounts for oshi_id=%d: %v", oshiID, err)
-		return fmt.Errorf("failed to delete existing accounts: %w", err)
+	// アカウントとカテゴリは差分のみ反映（既存の行のIDと作成日時を残す）
+	if err = r.syncAccountsInTransaction(tx, oshiID, accounts); err != nil {
+		return err
+	}
+	if err = r.syncCategoriesInTransaction(tx, oshiID, categories); err != nil {
+		return err
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		log.Printf("UpdateOshiWithTransaction ERROR: failed to commit transaction: %v", err)
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// 新しいアカウントを追加
-	if len(urls) > 0 {
-		err = r.addAccountsInTransaction(tx, oshiID, urls)
+	log.Printf("UpdateOshiWithTransaction SUCCESS: updated oshi_id=%d with %d accounts and %d categories", oshiID, len(accounts), len(categories))
+	return nil
+}
+
+// 指定した項目のみ更新（アカウント・カテゴリは指定した場合のみ差分を反映）
+func (r *oshiRepository) PatchOshi(oshiID int64, userID int64, patch *models.OshiPatch) error {
+	return r.updateInTransaction("PatchOshi", oshiID, userID, func(tx *sql.Tx) error {
+		setClauses := []string{"updated_at = ?"}
+		args := []interface{}{time.Now()}
+		if patch.Name != nil {
+			setClauses = append(setClauses, "name = ?")
+			args = append(args, *patch.Name)
+		}
+		if patch.Color != nil {
+			setClauses = append(setClauses, "theme_color = ?")
+			args = append(args, *patch.Color)
+		}
+		if patch.AutoEventMode != nil {
+			setClauses = append(setClauses, "auto_event_mode = ?")
+			args = append(args, *patch.AutoEventMode)
+		}
+		args = append(args, oshiID)
+
+		query := fmt.Sprintf(`UPDATE oshis SET %s WHERE id = ?`, strings.Join(setClauses, ", "))
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("failed to update oshi: %w", err)
+		}
+
+		if patch.Accounts != nil {
+			if err := r.syncAccountsInTransaction(tx, oshiID, *patch.Accounts); err != nil {
+				return err
+			}
+		}
+		if patch.Categories != nil {
+			if err := r.syncCategoriesInTransaction(tx, oshiID, *patch.Categories); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// アカウントを1件追加（登録済みの場合は何もしない）
+func (r *oshiRepository) AddAccount(oshiID int64, userID int64, account *models.OshiAccount) error {
+	return r.updateInTransaction("AddAccount", oshiID, userID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO oshi_accounts (oshi_id, url, platform, handle, created_at) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE id = id`,
+			oshiID, account.URL, account.Platform, account.Handle, time.Now(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert account: %w", err)
+		}
+		return r.touchInTransaction(tx, oshiID)
+	})
+}
+
+// アカウントを1件削除
+func (r *oshiRepository) RemoveAccount(oshiID int64, userID int64, url string) error {
+	return r.updateInTransaction("RemoveAccount", oshiID, userID, func(tx *sql.Tx) error {
+		result, err := tx.Exec(`DELETE FROM oshi_accounts WHERE oshi_id = ? AND url = ?`, oshiID, url)
+		if err != nil {
+			return fmt.Errorf("failed to delete account: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("account not found")
+		}
+		return r.touchInTransaction(tx, oshiID)
+	})
+}
+
+// カテゴリを1件追加（登録済みの場合は何もしない）
+func (r *oshiRepository) AddCategory(oshiID int64, userID int64, slug string) error {
+	return r.updateInTransaction("AddCategory", oshiID, userID, func(tx *sql.Tx) error {
+		var categoryID int64
+		err := tx.QueryRow(`SELECT id FROM categories WHERE slug = ?`, slug).Scan(&categoryID)
 		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("invalid categories: %s", slug)
+			}
 			return err
 		}
-	}
 
-	// 既存のカテゴリを削除
-	_, err = tx.Exec("DELETE FROM oshi_categories WHERE oshi_id = ?", oshiID)
+		_, err = tx.Exec(
+			`INSERT INTO oshi_categories (oshi_id, category_id) VALUES (?, ?) ON DUPLICATE KEY UPDATE oshi_id = oshi_id`,
+			oshiID, categoryID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert category: %w", err)
+		}
+		return r.touchInTransaction(tx, oshiID)
+	})
+}
+
+// カテゴリを1件削除
+func (r *oshiRepository) RemoveCategory(oshiID int64, userID int64, slug string) error {
+	return r.updateInTransaction("RemoveCategory", oshiID, userID, func(tx *sql.Tx) error {
+		result, err := tx.Exec(`
+			DELETE oc FROM oshi_categories oc
+			INNER JOIN categories c ON oc.category_id = c.id
+			WHERE oc.oshi_id = ? AND c.slug = ?
+		`, oshiID, slug)
+		if err != nil {
+			return fmt.Errorf("failed to delete category: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("category not found")
+		}
+		return r.touchInTransaction(tx, oshiID)
+	})
+}
+
+// 推しの所有者を確認してロックし、applyを同じトランザクションで実行する
+func (r *oshiRepository) updateInTransaction(name string, oshiID int64, userID int64, apply func(tx *sql.Tx) error) (err error) {
+	// トランザクション開始
+	tx, err := r.db.Begin()
 	if err != nil {
-		log.Printf("UpdateOshiWithTransaction ERROR: failed to delete existing categories for oshi_id=%d: %v", oshiID, err)
-		return fmt.Errorf("failed to delete existing categories: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	// 新しいカテゴリを追加
-	if len(categories) > 0 {
-		err = r.addCategoriesInTransaction(tx, oshiID, categories)
+	// エラー時ロールバック
+	defer func() {
 		if err != nil {
-			return err
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("%s ERROR: failed to rollback transaction: %v", name, rollbackErr)
+			}
 		}
+	}()
+
+	var lockedOshiID int64
+	err = tx.QueryRow(`SELECT id FROM oshis WHERE id = ? AND user_id = ? AND deleted_at IS NULL FOR UPDATE`, oshiID, userID).Scan(&lockedOshiID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("oshi not found")
+		}
+		return err
+	}
+
+	if err = apply(tx); err != nil {
+		return err
 	}
 
 	// トランザクションをコミット
 	if err = tx.Commit(); err != nil {
-		log.Printf("UpdateOshiWithTransaction ERROR: failed to commit transaction: %v", err)
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	log.Printf("UpdateOshiWithTransaction SUCCESS: updated oshi_id=%d with %d urls and %d categories", oshiID, len(urls), len(categories))
 	return nil
 }
 
+// アカウント・カテゴリの変更を推しの更新日時に反映
+func (r *oshiRepository) touchInTransaction(tx *sql.Tx, oshiID int64) error {
+	if _, err := tx.Exec(`UPDATE oshis SET updated_at = ? WHERE id = ?`, time.Now(), oshiID); err != nil {
+		return fmt.Errorf("failed to update oshi: %w", err)
+	}
+	return nil
+}
+
+// アカウントを指定した内容に合わせる（URLで比較し、なくなったURLのみ削除し、新しいURLのみ追加）
+func (r *oshiRepository) syncAccountsInTransaction(tx *sql.Tx, oshiID int64, accounts []*models.OshiAccount) error {
+	rows, err := tx.Query(`SELECT id, url FROM oshi_accounts WHERE oshi_id = ?`, oshiID)
+	if err != nil {
+		return fmt.Errorf("failed to query accounts: %w", err)
+	}
+	current := make(map[string]int64)
+	for rows.Next() {
+		var id int64
+		var url string
+		if err := rows.Scan(&id, &url); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan account: %w", err)
+		}
+		current[url] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	urls := make([]string, 0, len(accounts))
+	accountsByURL := make(map[string]*models.OshiAccount, len(accounts))
+	for _, account := range accounts {
+		urls = append(urls, account.URL)
+		accountsByURL[account.URL] = account
+	}
+	added, removed := diffStrings(keysOf(current), urls)
+
+	if len(removed) > 0 {
+		ids := make([]interface{}, 0, len(removed))
+		for _, url := range removed {
+			ids = append(ids, current[url])
+		}
+		query := fmt.Sprintf(`DELETE FROM oshi_accounts WHERE id IN (%s)`, buildPlaceholders(len(ids)))
+		if _, err := tx.Exec(query, ids...); err != nil {
+			log.Printf("syncAccountsInTransaction ERROR: failed to delete accounts for oshi_id=%d: %v", oshiID, err)
+			return fmt.Errorf("failed to delete accounts: %w", err)
+		}
+	}
+
+	addedAccounts := make([]*models.OshiAccount, 0, len(added))
+	for _, url := range added {
+		addedAccounts = append(addedAccounts, accountsByURL[url])
+	}
+	return r.addAccountsInTransaction(tx, oshiID, addedAccounts)
+}
+
+// カテゴリを指定したslugに合わせる（外したカテゴリのみ削除し、新しいカテゴリのみ追加）
+func (r *oshiRepository) syncCategoriesInTransaction(tx *sql.Tx, oshiID int64, categories []string) error {
+	rows, err := tx.Query(`
+		SELECT c.id, c.slug
+		FROM oshi_categories oc
+		INNER JOIN categories c ON oc.category_id = c.id
+		WHERE oc.oshi_id = ?
+	`, oshiID)
+	if err != nil {
+		return fmt.Errorf("failed to query categories: %w", err)
+	}
+	current := make(map[string]int64)
+	for rows.Next() {
+		var id int64
+		var slug string
+		if err := rows.Scan(&id, &slug); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan category: %w", err)
+		}
+		current[slug] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	added, removed := diffStrings(keysOf(current), categories)
+
+	if len(removed) > 0 {
+		args := []interface{}{oshiID}
+		for _, slug := range removed {
+			args = append(args, current[slug])
+		}
+		query := fmt.Sprintf(`DELETE FROM oshi_categories WHERE oshi_id = ? AND category_id IN (%s)`, buildPlaceholders(len(removed)))
+		if _, err := tx.Exec(query, args...); err != nil {
+			log.Printf("syncCategoriesInTransaction ERROR: failed to delete categories for oshi_id=%d: %v", oshiID, err)
+			return fmt.Errorf("failed to delete categories: %w", err)
+		}
+	}
+
+	return r.addCategoriesInTransaction(tx, oshiID, added)
+}
+
+// currentからdesiredにするために追加・削除する値（addedはdesiredの順番で重複なし）
+func diffStrings(current []string, desired []string) (added []string, removed []string) {
+	currentSet := make(map[string]bool, len(current))
+	for _, value := range current {
+		currentSet[value] = true
+	}
+	desiredSet := make(map[string]bool, len(desired))
+	for _, value := range desired {
+		if desiredSet[value] {
+			continue
+		}
+		desiredSet[value] = true
+		if !currentSet[value] {
+			added = append(added, value)
+		}
+	}
+	for _, value := range current {
+		if !desiredSet[value] {
+			removed = append(removed, value)
+		}
+	}
+	sort.Strings(removed)
+	return added, removed
+}
+
+func keysOf(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// 自動検出イベントの扱いを更新
+func (r *oshiRepository) UpdateAutoEventMode(oshiID int64, userID int64, mode string) error {
+	// 同じ値での更新も成功扱いにするため先に存在確認する
+	var oshiExists int
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM oshis WHERE id = ? AND user_id = ? AND deleted_at IS NULL)`, oshiID, userID).Scan(&oshiExists)
+	if err != nil {
+		return err
+	}
+	if oshiExists == 0 {
+		return fmt.Errorf("oshi not found")
+	}
+
+	_, err = r.db.Exec(
+		`UPDATE oshis SET auto_event_mode = ?, updated_at = ? WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+		mode, time.Now(), oshiID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update auto event mode: %w", err)
+	}
+
+	return nil
+}
+
+// 推しの削除で一緒に削除される件数
+const oshiDeletionCountsQuery = `
+	SELECT
+		(SELECT COUNT(*) FROM events WHERE oshi_id = ? AND deleted_at IS NULL),
+		(SELECT COUNT(*) FROM events WHERE oshi_id = ? AND deleted_at IS NOT NULL),
+		(SELECT COUNT(*) FROM oshi_accounts WHERE oshi_id = ?),
+		(SELECT COUNT(*) FROM oshi_categories WHERE oshi_id = ?),
+		(SELECT COUNT(*) FROM event_candidates WHERE oshi_id = ?),
+		(SELECT COUNT(*) FROM calendar_feeds WHERE oshi_id = ? AND revoked_at IS NULL)
+`
+
+type oshiDeletionCountsQueryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func queryOshiDeletionCounts(q oshiDeletionCountsQueryer, oshiID int64) (*models.OshiDeletionCounts, error) {
+	var counts models.OshiDeletionCounts
+	err := q.QueryRow(oshiDeletionCountsQuery, oshiID, oshiID, oshiID, oshiID, oshiID, oshiID).Scan(
+		&counts.Events,
+		&counts.DeletedEvents,
+		&counts.Accounts,
+		&counts.Categories,
+		&counts.EventCandidates,
+		&counts.CalendarFeeds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count oshi deletion: %w", err)
+	}
+	return &counts, nil
+}
+
+// 推しを削除した場合に一緒に削除される件数を取得
+func (r *oshiRepository) GetDeletionCounts(oshiID int64, userID int64) (*models.OshiDeletionCounts, error) {
+	var oshiExists int
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM oshis WHERE id = ? AND user_id = ? AND deleted_at IS NULL)`, oshiID, userID).Scan(&oshiExists)
+	if err != nil {
+		return nil, err
+	}
+	if oshiExists == 0 {
+		return nil, fmt.Errorf("oshi not found")
+	}
+
+	return queryOshiDeletionCounts(r.db, oshiID)
+}
+
+// 推しを論理削除（アカウント・カテゴリ・イベントは取り消し期間を過ぎてから削除される）
+func (r *oshiRepository) DeleteOshi(oshiID int64, userID int64) (*models.OshiDeletionCounts, time.Time, error) {
+	// トランザクション開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("DeleteOshi ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// 所有者の確認（件数を数えている間にイベントが追加されないようロック）
+	var lockedOshiID int64
+	err = tx.QueryRow(`SELECT id FROM oshis WHERE id = ? AND user_id = ? AND deleted_at IS NULL FOR UPDATE`, oshiID, userID).Scan(&lockedOshiID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("oshi not found")
+		}
+		return nil, time.Time{}, err
+	}
+
+	counts, err := queryOshiDeletionCounts(tx, oshiID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	if _, err = tx.Exec(`UPDATE oshis SET deleted_at = NOW(3) WHERE id = ?`, oshiID); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to delete oshi: %w", err)
+	}
+
+	var deletedAt time.Time
+	if err = tx.QueryRow(`SELECT deleted_at FROM oshis WHERE id = ?`, oshiID).Scan(&deletedAt); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return counts, deletedAt, nil
+}
+
+// deletedAfter以降に論理削除した推しを復元
+func (r *oshiRepository) RestoreOshi(oshiID int64, userID int64, deletedAfter time.Time) error {
+	result, err := r.db.Exec(
+		`UPDATE oshis SET deleted_at = NULL WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL AND deleted_at > ?`,
+		oshiID, userID, deletedAfter,
+	)
+	if err != nil {
+		// 削除後に同じ名前の推しを作成した場合
+		if isDuplicateOshiNameError(err) {
+			return fmt.Errorf("oshi already exists")
+		}
+		return fmt.Errorf("failed to restore oshi: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("oshi not found")
+	}
+
+	return nil
+}
+
+// before以前に論理削除した推しを削除（アカウント・カテゴリ・イベントなどはON DELETE CASCADEで削除される）
+func (r *oshiRepository) PurgeDeleted(before time.Time, limit int) (int64, error) {
+	result, err := r.db.Exec(
+		`DELETE FROM oshis WHERE deleted_at IS NOT NULL AND deleted_at <= ? ORDER BY deleted_at LIMIT ?`,
+		before, limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge oshis: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+func isDuplicateOshiNameError(err error) bool {
+	return strings.Contains(err.Error(), "uq_oshis_user_name")
+}
+
 // 共通の推し詳細情報取得関数
 func (r *oshiRepository) queryOshisWithDetails(whereClause string, args ...interface{}) ([]*models.OshiWithDetails, error) {
 	query := fmt.Sprintf(`
@@ -304,10 +737,13 @@ func (r *oshiRepository) queryOshisWithDetails(whereClause string, args ...inter
 			o.name as oshi_name,
 			o.description as oshi_description,
 			o.theme_color,
+			o.auto_event_mode,
 			o.created_at as oshi_created_at,
 			o.updated_at as oshi_updated_at,
 			oa.id as account_id,
 			oa.url as account_url,
+			oa.platform as account_platform,
+			oa.handle as account_handle,
 			oa.created_at as account_created_at,
 			c.id as category_id,
 			c.slug as category_slug,
@@ -335,19 +771,19 @@ func (r *oshiRepository) queryOshisWithDetails(whereClause string, args ...inter
 		var (
 			oshiID, userIDResult                            int64
 			accountID, categoryID                           *int64
-			oshiName, themeColor                            string
+			oshiName, themeColor, autoEventMode             string
 			oshiDescription                                 *string
 			oshiCreatedAt, oshiUpdatedAt                    time.Time
-			accountURL                                      *string
+			accountURL, accountPlatform, accountHandle      *string
 			accountCreatedAt                                *time.Time
 			categorySlug, categoryName, categoryDescription *string
 			categoryCreatedAt, categoryUpdatedAt            *time.Time
 		)
 
 		err := rows.Scan(
-			&oshiID, &userIDResult, &oshiName, &oshiDescription, &themeColor,
+			&oshiID, &userIDResult, &oshiName, &oshiDescription, &themeColor, &autoEventMode,
 			&oshiCreatedAt, &oshiUpdatedAt,
-			&accountID, &accountURL, &accountCreatedAt,
+			&accountID, &accountURL, &accountPlatform, &accountHandle, &accountCreatedAt,
 			&categoryID, &categorySlug, &categoryName, &categoryDescription,
 			&categoryCreatedAt, &categoryUpdatedAt,
 		)
@@ -359,13 +795,14 @@ func (r *oshiRepository) queryOshisWithDetails(whereClause string, args ...inter
 		if _, exists := oshiMap[oshiID]; !exists {
 			oshiMap[oshiID] = &models.OshiWithDetails{
 				Oshi: &models.Oshi{
-					ID:          oshiID,
-					UserID:      userIDResult,
-					Name:        oshiName,
-					Description: oshiDescription,
-					ThemeColor:  themeColor,
-					CreatedAt:   oshiCreatedAt,
-					UpdatedAt:   oshiUpdatedAt,
+					ID:            oshiID,
+					UserID:        userIDResult,
+					Name:          oshiName,
+					Description:   oshiDescription,
+					ThemeColor:    themeColor,
+					AutoEventMode: autoEventMode,
+					CreatedAt:     oshiCreatedAt,
+					UpdatedAt:     oshiUpdatedAt,
 				},
 				Accounts:   []*models.OshiAccount{},
 				Categories: []*models.Category{},
@@ -373,11 +810,13 @@ func (r *oshiRepository) queryOshisWithDetails(whereClause string, args ...inter
 		}
 
 		// アカウント情報を追加
-		if accountID != nil && accountURL != nil && accountCreatedAt != nil {
+		if accountID != nil && accountURL != nil && accountPlatform != nil && accountCreatedAt != nil {
 			account := &models.OshiAccount{
 				ID:        *accountID,
 				OshiID:    oshiID,
 				URL:       *accountURL,
+				Platform:  *accountPlatform,
+				Handle:    accountHandle,
 				CreatedAt: *accountCreatedAt,
 			}
 			// 重複チェック
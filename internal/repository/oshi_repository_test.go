@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffStrings(t *testing.T) {
+	added, removed := diffStrings(
+		[]string{"https://x.com/a", "https://x.com/b", "https://x.com/c"},
+		[]string{"https://x.com/d", "https://x.com/a", "https://x.com/d", "https://x.com/e"},
+	)
+	if !reflect.DeepEqual(added, []string{"https://x.com/d", "https://x.com/e"}) {
+		t.Errorf("added: 期待値 [https://x.com/d https://x.com/e], 実際 %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"https://x.com/b", "https://x.com/c"}) {
+		t.Errorf("removed: 期待値 [https://x.com/b https://x.com/c], 実際 %v", removed)
+	}
+
+	added, removed = diffStrings([]string{"a"}, []string{"a"})
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("変更がない場合は差分なしになるべきです: added=%v removed=%v", added, removed)
+	}
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"lovender_backend/internal/models"
+	"strings"
+	"time"
+)
+
+type PersonalAccessTokenRepository interface {
+	Create(token *models.PersonalAccessToken) error
+	GetByUserID(userID int64) ([]*models.PersonalAccessToken, error)
+	GetActiveByTokenHash(tokenHash string) (*models.PersonalAccessToken, error)
+	TouchLastUsed(tokenID int64) error
+	Revoke(tokenID int64, userID int64) error
+}
+
+type personalAccessTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPersonalAccessTokenRepository(db *sql.DB) PersonalAccessTokenRepository {
+	return &personalAccessTokenRepository{db: db}
+}
+
+// PATを作成
+func (r *personalAccessTokenRepository) Create(token *models.PersonalAccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(
+		query,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		token.TokenPrefix,
+		strings.Join(token.Scopes, " "),
+		token.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create personal access token: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	token.ID = id
+	token.CreatedAt = time.Now()
+	return nil
+}
+
+// ユーザーの有効なPAT一覧を取得（期限切れは除く）
+func (r *personalAccessTokenRepository) GetByUserID(userID int64) ([]*models.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > NOW(3)
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]*models.PersonalAccessToken, 0)
+	for rows.Next() {
+		token, err := scanPersonalAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// トークンのハッシュから有効なPATを取得（退会申請中のユーザーのトークンは使えない）
+func (r *personalAccessTokenRepository) GetActiveByTokenHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	query := `
+		SELECT t.id, t.user_id, t.name, t.token_hash, t.token_prefix, t.scopes, t.expires_at, t.last_used_at, t.created_at
+		FROM personal_access_tokens t
+		INNER JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = ? AND t.revoked_at IS NULL AND t.expires_at > NOW(3)
+			AND u.deletion_requested_at IS NULL
+	`
+
+	token, err := scanPersonalAccessToken(r.db.QueryRow(query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("personal access token not found")
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// 最終使用日時を更新（リクエストごとに書き込まないよう1分単位）
+func (r *personalAccessTokenRepository) TouchLastUsed(tokenID int64) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used_at = NOW(3)
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < NOW(3) - INTERVAL 1 MINUTE)
+	`
+
+	if _, err := r.db.Exec(query, tokenID); err != nil {
+		return fmt.Errorf("failed to update personal access token: %w", err)
+	}
+	return nil
+}
+
+// PATを失効させる
+func (r *personalAccessTokenRepository) Revoke(tokenID int64, userID int64) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked_at = NOW(3)
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("personal access token not found")
+	}
+
+	return nil
+}
+
+type personalAccessTokenScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPersonalAccessToken(row personalAccessTokenScanner) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	var scopes string
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.TokenPrefix,
+		&scopes,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	token.Scopes = strings.Fields(scopes)
+	return &token, nil
+}
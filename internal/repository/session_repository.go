@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"lovender_backend/internal/models"
+	"time"
+)
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByRefreshTokenHash(refreshTokenHash string) (*models.Session, error)
+	RevokeByPreviousRefreshTokenHash(refreshTokenHash string) (bool, error)
+	Rotate(sessionID int64, oldHash string, newHash string, expiresAt time.Time) error
+	IsActiveByJTI(jti string) (bool, error)
+	GetActiveByUserID(userID int64) ([]*models.Session, error)
+	Revoke(sessionID int64, userID int64) error
+	RevokeByJTI(jti string) error
+	RevokeOthers(userID int64, currentJTI string) (int64, error)
+}
+
+type sessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// セッションを作成
+func (r *sessionRepository) Create(session *models.Session) error {
+	query := `
+		INSERT INTO sessions (user_id, jti, refresh_token_hash, user_agent, ip_address, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := r.db.Exec(
+		query,
+		session.UserID,
+		session.JTI,
+		session.RefreshTokenHash,
+		session.UserAgent,
+		session.IPAddress,
+		session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	session.ID = id
+	return nil
+}
+
+// リフレッシュトークンのハッシュから有効なセッションを取得
+func (r *sessionRepository) GetByRefreshTokenHash(refreshTokenHash string) (*models.Session, error) {
+	query := `
+		SELECT id, user_id, jti, refresh_token_hash, user_agent, ip_address,
+			created_at, last_used_at, expires_at, revoked_at
+		FROM sessions
+		WHERE refresh_token_hash = ? AND revoked_at IS NULL AND expires_at > NOW(3)
+	`
+
+	session, err := scanSession(r.db.QueryRow(query, refreshTokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// ローテーション済みのリフレッシュトークンが使われた場合はセッションを失効させる
+// 盗まれたトークンが使われた可能性があるため、正規の利用者にも再ログインを求める
+func (r *sessionRepository) RevokeByPreviousRefreshTokenHash(refreshTokenHash string) (bool, error) {
+	result, err := r.db.Exec(
+		`UPDATE sessions SET revoked_at = NOW(3) WHERE previous_refresh_token_hash = ? AND revoked_at IS NULL`,
+		refreshTokenHash,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// リフレッシュトークンを新しいものに置き換える
+// 同じトークンで同時に再発行された場合は後の方を失敗させる
+func (r *sessionRepository) Rotate(sessionID int64, oldHash string, newHash string, expiresAt time.Time) error {
+	query := `
+		UPDATE sessions
+		SET refresh_token_hash = ?, previous_refresh_token_hash = ?, expires_at = ?, last_used_at = NOW(3)
+		WHERE id = ? AND refresh_token_hash = ? AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Exec(query, newHash, oldHash, expiresAt, sessionID, oldHash)
+	if err != nil {
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	return nil
+}
+
+// jtiのセッションが有効か確認
+func (r *sessionRepository) IsActiveByJTI(jti string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM sessions WHERE jti = ? AND revoked_at IS NULL AND expires_at > NOW(3)) AS session_active`
+
+	var active bool
+	if err := r.db.QueryRow(query, jti).Scan(&active); err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+
+	return active, nil
+}
+
+// ユーザーの有効なセッション一覧を取得（最近使われた順）
+func (r *sessionRepository) GetActiveByUserID(userID int64) ([]*models.Session, error) {
+	query := `
+		SELECT id, user_id, jti, refresh_token_hash, user_agent, ip_address,
+			created_at, last_used_at, expires_at, revoked_at
+		FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > NOW(3)
+		ORDER BY last_used_at DESC, id DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*models.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// セッションを失効させる
+func (r *sessionRepository) Revoke(sessionID int64, userID int64) error {
+	result, err := r.db.Exec(
+		`UPDATE sessions SET revoked_at = NOW(3) WHERE id = ? AND user_id = ? AND revoked_at IS NULL`,
+		sessionID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	return nil
+}
+
+// jtiのセッションを失効させる（ログアウト）
+func (r *sessionRepository) RevokeByJTI(jti string) error {
+	_, err := r.db.Exec(`UPDATE sessions SET revoked_at = NOW(3) WHERE jti = ? AND revoked_at IS NULL`, jti)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// 現在のセッション以外をすべて失効させ、失効させた件数を返す
+func (r *sessionRepository) RevokeOthers(userID int64, currentJTI string) (int64, error) {
+	result, err := r.db.Exec(
+		`UPDATE sessions SET revoked_at = NOW(3) WHERE user_id = ? AND jti <> ? AND revoked_at IS NULL`,
+		userID, currentJTI,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+type sessionScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row sessionScanner) (*models.Session, error) {
+	var session models.Session
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.JTI,
+		&session.RefreshTokenHash,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
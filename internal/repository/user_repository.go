@@ -2,13 +2,24 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"log"
 	"lovender_backend/internal/models"
+	"strings"
+	"time"
 )
 
 type UserRepository interface {
 	GetByID(id int64) (*models.User, error)
 	Create(user *models.User) error
 	GetByEmail(email string) (*models.User, error)
+	UpdateProfile(userID int64, name string, email string) error
+	UpdatePassword(userID int64, passwordHash string, currentJTI string) error
+	RequestDeletion(userID int64) (time.Time, error)
+	CancelDeletion(userID int64) error
+	PurgeDeletionRequested(before time.Time, limit int) (int64, error)
+	SearchWithStats(filter *models.AdminUserFilter) ([]*models.AdminUserItem, int64, error)
+	GetWithStatsByID(id int64) (*models.AdminUserItem, error)
 }
 
 type userRepository struct {
@@ -21,7 +32,7 @@ func NewUserRepository(db *sql.DB) UserRepository {
 
 func (r *userRepository) GetByID(id int64) (*models.User, error) {
 	query := `
-		SELECT id, name, email, password_hash, created_at, updated_at 
+		SELECT id, name, email, password_hash, role, email_verified_at, deletion_requested_at, created_at, updated_at 
 		FROM users 
 		WHERE id = ?
 	`
@@ -32,6 +43,9 @@ func (r *userRepository) GetByID(id int64) (*models.User, error) {
 		&user.Name,
 		&user.Email,
 		&user.PasswordHash,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.DeletionRequestedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -63,12 +77,13 @@ func (r *userRepository) Create(user *models.User) error {
 	}
 
 	user.ID = id
+	user.Role = models.UserRoleUser
 	return nil
 }
 
 func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	query := `
-		SELECT id, name, email, password_hash, created_at, updated_at 
+		SELECT id, name, email, password_hash, role, email_verified_at, deletion_requested_at, created_at, updated_at 
 		FROM users 
 		WHERE email = ?
 	`
@@ -79,6 +94,9 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 		&user.Name,
 		&user.Email,
 		&user.PasswordHash,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.DeletionRequestedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -92,3 +110,242 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 
 	return &user, nil
 }
+
+// 名前とメールアドレスを更新
+// メールアドレスが変わった場合は確認済みを解除する
+func (r *userRepository) UpdateProfile(userID int64, name string, email string) error {
+	query := `
+		UPDATE users
+		SET name = ?,
+			email_verified_at = IF(email = ?, email_verified_at, NULL),
+			email = ?
+		WHERE id = ?
+	`
+
+	result, err := r.db.Exec(query, name, email, email, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		// 値が変わらない場合も0件になるため存在確認する
+		var exists bool
+		if err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)`, userID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("user not found")
+		}
+	}
+
+	return nil
+}
+
+// パスワードを更新し、現在のセッション以外を失効させる
+func (r *userRepository) UpdatePassword(userID int64, passwordHash string, currentJTI string) error {
+	// トランザクション開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("UpdatePassword ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	if _, err = tx.Exec(`UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, userID); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`UPDATE sessions SET revoked_at = NOW(3) WHERE user_id = ? AND jti <> ? AND revoked_at IS NULL`,
+		userID, currentJTI,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// 退会を申請し、すべてのセッションと個人用アクセストークンを失効させる
+// 申請済みの場合は最初の申請日時を返す
+func (r *userRepository) RequestDeletion(userID int64) (time.Time, error) {
+	// トランザクション開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("RequestDeletion ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	_, err = tx.Exec(
+		`UPDATE users SET deletion_requested_at = COALESCE(deletion_requested_at, NOW(3)) WHERE id = ?`,
+		userID,
+	)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to request deletion: %w", err)
+	}
+
+	var requestedAt sql.NullTime
+	err = tx.QueryRow(`SELECT deletion_requested_at FROM users WHERE id = ?`, userID).Scan(&requestedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, fmt.Errorf("user not found")
+		}
+		return time.Time{}, err
+	}
+
+	if _, err = tx.Exec(`UPDATE sessions SET revoked_at = NOW(3) WHERE user_id = ? AND revoked_at IS NULL`, userID); err != nil {
+		return time.Time{}, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if _, err = tx.Exec(`UPDATE personal_access_tokens SET revoked_at = NOW(3) WHERE user_id = ? AND revoked_at IS NULL`, userID); err != nil {
+		return time.Time{}, fmt.Errorf("failed to revoke personal access tokens: %w", err)
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return requestedAt.Time, nil
+}
+
+// 退会申請を取り消す
+func (r *userRepository) CancelDeletion(userID int64) error {
+	_, err := r.db.Exec(`UPDATE users SET deletion_requested_at = NULL WHERE id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+
+	return nil
+}
+
+// 猶予期間を過ぎたユーザーを削除（推し・イベントなどは外部キーで連鎖削除される）
+func (r *userRepository) PurgeDeletionRequested(before time.Time, limit int) (int64, error) {
+	result, err := r.db.Exec(
+		`DELETE FROM users WHERE deletion_requested_at IS NOT NULL AND deletion_requested_at <= ? ORDER BY deletion_requested_at LIMIT ?`,
+		before, limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge users: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// 管理者向けのユーザー情報（件数は論理削除済みの推し・イベントを除く）
+const userWithStatsQuery = `
+	SELECT u.id, u.name, u.email, u.role, u.email_verified_at, u.deletion_requested_at,
+		(SELECT COUNT(*) FROM oshis o WHERE o.user_id = u.id AND o.deleted_at IS NULL) AS oshi_count,
+		(SELECT COUNT(*) FROM events e JOIN oshis o ON o.id = e.oshi_id
+			WHERE o.user_id = u.id AND o.deleted_at IS NULL AND e.deleted_at IS NULL) AS event_count,
+		(SELECT COUNT(*) FROM events e JOIN oshis o ON o.id = e.oshi_id
+			WHERE o.user_id = u.id AND o.deleted_at IS NULL AND e.deleted_at IS NULL AND e.source = 'import') AS imported_event_count,
+		u.created_at, u.updated_at
+	FROM users u
+`
+
+// メールアドレスまたは名前で検索（新しい順）し、該当件数も返す
+func (r *userRepository) SearchWithStats(filter *models.AdminUserFilter) ([]*models.AdminUserItem, int64, error) {
+	whereClause := ""
+	args := []interface{}{}
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		whereClause = " WHERE u.email LIKE ? OR u.name LIKE ?"
+		args = append(args, pattern, pattern)
+	}
+
+	var total int64
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM users u`+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query := userWithStatsQuery + whereClause + ` ORDER BY u.id DESC LIMIT ? OFFSET ?`
+	rows, err := r.db.Query(query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]*models.AdminUserItem, 0)
+	for rows.Next() {
+		user, err := scanUserWithStats(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// 管理者向けのユーザー情報を取得
+func (r *userRepository) GetWithStatsByID(id int64) (*models.AdminUserItem, error) {
+	user, err := scanUserWithStats(r.db.QueryRow(userWithStatsQuery+` WHERE u.id = ?`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+type userScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUserWithStats(row userScanner) (*models.AdminUserItem, error) {
+	var user models.AdminUserItem
+	var emailVerifiedAt sql.NullTime
+	err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&emailVerifiedAt,
+		&user.DeletionRequestedAt,
+		&user.OshiCount,
+		&user.EventCount,
+		&user.ImportedEventCount,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	user.EmailVerified = emailVerifiedAt.Valid
+	return &user, nil
+}
+
+// LIKE検索のワイルドカードをエスケープ
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "testing"
+
+func TestEscapeLike(t *testing.T) {
+	tests := map[string]string{
+		"tanaka":     "tanaka",
+		"100%":       `100\%`,
+		"user_name":  `user\_name`,
+		`back\slash`: `back\\slash`,
+		"田中%_太郎":     `田中\%\_太郎`,
+	}
+
+	for input, want := range tests {
+		if got := escapeLike(input); got != want {
+			t.Errorf("escapeLike(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// トークンの用途
+const (
+	UserTokenPurposePasswordReset     = "password_reset"
+	UserTokenPurposeEmailVerification = "email_verification"
+)
+
+type UserTokenRepository interface {
+	Create(userID int64, purpose string, tokenHash string, expiresAt time.Time) error
+	ResetPassword(tokenHash string, passwordHash string) (int64, error)
+	VerifyEmail(tokenHash string) (int64, error)
+}
+
+type userTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUserTokenRepository(db *sql.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+// トークンを保存（同じ用途の未使用トークンは無効にする）
+func (r *userTokenRepository) Create(userID int64, purpose string, tokenHash string, expiresAt time.Time) error {
+	// トランザクション開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("CreateUserToken ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	_, err = tx.Exec(
+		`UPDATE user_tokens SET used_at = NOW(3) WHERE user_id = ? AND purpose = ? AND used_at IS NULL`,
+		userID, purpose,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate user tokens: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at) VALUES (?, ?, ?, ?)`,
+		userID, purpose, tokenHash, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create user token: %w", err)
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// パスワード再設定トークンを使ってパスワードを更新し、全セッションと個人用アクセストークンを失効させる
+func (r *userTokenRepository) ResetPassword(tokenHash string, passwordHash string) (int64, error) {
+	return r.consume(tokenHash, UserTokenPurposePasswordReset, func(tx *sql.Tx, userID int64) error {
+		if _, err := tx.Exec(`UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, userID); err != nil {
+			return fmt.Errorf("failed to update password: %w", err)
+		}
+		// 盗まれたトークンでログインされている可能性があるため全端末をログアウトさせる
+		if _, err := tx.Exec(`UPDATE sessions SET revoked_at = NOW(3) WHERE user_id = ? AND revoked_at IS NULL`, userID); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		// 乗っ取られている間に作られた個人用アクセストークンも使えなくする
+		if _, err := tx.Exec(`UPDATE personal_access_tokens SET revoked_at = NOW(3) WHERE user_id = ? AND revoked_at IS NULL`, userID); err != nil {
+			return fmt.Errorf("failed to revoke personal access tokens: %w", err)
+		}
+		return nil
+	})
+}
+
+// メールアドレス確認トークンを使ってメールアドレスを確認済みにする
+func (r *userTokenRepository) VerifyEmail(tokenHash string) (int64, error) {
+	return r.consume(tokenHash, UserTokenPurposeEmailVerification, func(tx *sql.Tx, userID int64) error {
+		_, err := tx.Exec(
+			`UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW(3)) WHERE id = ?`,
+			userID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+		return nil
+	})
+}
+
+// 有効なトークンを使用済みにしてapplyを同じトランザクションで実行する
+func (r *userTokenRepository) consume(tokenHash string, purpose string, apply func(tx *sql.Tx, userID int64) error) (int64, error) {
+	// トランザクション開始
+	tx, err := r.db.Begin()
+	if err != nil {
+		log.Printf("ConsumeUserToken ERROR: failed to begin transaction: %v", err)
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// エラー時ロールバック
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				log.Printf("ConsumeUserToken ERROR: failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	// 同時に使われないようトークンをロック
+	var (
+		tokenID int64
+		userID  int64
+	)
+	err = tx.QueryRow(`
+		SELECT id, user_id
+		FROM user_tokens
+		WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > NOW(3)
+		FOR UPDATE
+	`, tokenHash, purpose).Scan(&tokenID, &userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("invalid token")
+		}
+		return 0, err
+	}
+
+	if _, err = tx.Exec(`UPDATE user_tokens SET used_at = NOW(3) WHERE id = ?`, tokenID); err != nil {
+		return 0, fmt.Errorf("failed to consume user token: %w", err)
+	}
+
+	if err = apply(tx, userID); err != nil {
+		log.Printf("ConsumeUserToken ERROR: failed to apply %s for user_id=%d: %v", purpose, userID, err)
+		return 0, err
+	}
+
+	// トランザクションをコミット
+	if err = tx.Commit(); err != nil {
+		log.Printf("ConsumeUserToken ERROR: failed to commit transaction: %v", err)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return userID, nil
+}
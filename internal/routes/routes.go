@@ -15,41 +15,117 @@ func SetupRoutes(
 	commonHandler *handler.CommonHandler,
 	eventsHandler *handler.EventsHandler,
   eventAutoHandler *handler.EventAutoHandler,
-  schedulerHandler *handler.SchedulerHandler) {
+  schedulerHandler *handler.SchedulerHandler,
+	calendarFeedHandler *handler.CalendarFeedHandler,
+	eventImportHandler *handler.EventImportHandler,
+	eventCandidateHandler *handler.EventCandidateHandler,
+	sessionHandler *handler.SessionHandler,
+	accountHandler *handler.AccountHandler,
+	adminUserHandler *handler.AdminUserHandler,
+	oidcHandler *handler.OIDCHandler,
+	personalAccessTokenHandler *handler.PersonalAccessTokenHandler,
+	dateTimePatternHandler *handler.DateTimePatternHandler) {
+
+	// トークン検証用の公開鍵（他のサービス向け）
+	e.GET("/.well-known/jwks.json", jwtutil.JWKSHandler)
 
 	api := e.Group("/api")
 
 	// 認証
 	api.POST("/auth/register", userHandler.Register)
 	api.POST("/auth/login", userHandler.Login)
+	api.POST("/auth/refresh", sessionHandler.Refresh)
+	api.POST("/auth/logout", sessionHandler.Logout, jwtutil.JWTMiddleware())
+
+	// パスワード再設定とメールアドレス確認
+	api.POST("/auth/password/forgot", accountHandler.ForgotPassword)
+	api.POST("/auth/password/reset", accountHandler.ResetPassword)
+	api.POST("/auth/verify", accountHandler.VerifyEmail)
+	api.POST("/auth/verify/resend", accountHandler.ResendEmailVerification, jwtutil.JWTMiddleware())
+
+	// ソーシャルログイン（OpenID Connect）
+	api.GET("/auth/oidc/providers", oidcHandler.GetProviders)
+	api.GET("/auth/oidc/:provider/authorize", oidcHandler.Authorize)
+	api.POST("/auth/oidc/:provider/callback", oidcHandler.Callback)
 
 	// 共通情報
 	api.GET("/common", commonHandler.GetCommon)
 
+	// カレンダーフィード配信（トークンで識別するため認証なし）
+	api.GET("/calendar/:token", calendarFeedHandler.GetCalendarFeed)
+
 	// ユーザー情報取得
-	api.GET("/me", userHandler.GetMe, jwtutil.JWTMiddleware())
+	// 個人用アクセストークン（PAT）はRequireScopeを付けたエンドポイントでのみ使える
+	api.GET("/me", userHandler.GetMe, jwtutil.JWTMiddleware(), jwtutil.RequireScope(jwtutil.ScopeProfileRead))
+	api.PATCH("/me", userHandler.UpdateMe, jwtutil.JWTMiddleware())
+	api.DELETE("/me", userHandler.DeleteMe, jwtutil.JWTMiddleware())
 
-	// JWT認証が必要なエンドポイント
+	// JWT認証が必要なエンドポイント（PATはスコープを指定したエンドポイントのみ）
 	protected := api.Group("/me")
 	protected.Use(jwtutil.JWTMiddleware())
 
+	// パスワード変更
+	protected.PUT("/password", userHandler.ChangePassword)
+
+	// 個人用アクセストークン（PATでは管理できない）
+	protected.GET("/tokens", personalAccessTokenHandler.GetMyTokens)
+	protected.POST("/tokens/new", personalAccessTokenHandler.CreateToken)
+	protected.DELETE("/tokens/:tokenId", personalAccessTokenHandler.RevokeToken)
+
+	// ログイン中のセッション（端末）
+	protected.GET("/sessions", sessionHandler.GetMySessions)
+	protected.DELETE("/sessions", sessionHandler.RevokeOtherSessions)
+	protected.DELETE("/sessions/:sessionId", sessionHandler.RevokeSession)
+
 	// 推し関連のエンドポイント
-	protected.GET("/oshis", oshiHandler.GetMyOshis)
-	protected.POST("/oshis/new", oshiHandler.CreateOshi)
-	protected.PUT("/oshis/:oshiId", oshiHandler.UpdateOshi)
-	protected.GET("/oshis/:oshiId", oshiGetHandler.GetMyOshiByID)
+	protected.GET("/oshis", oshiHandler.GetMyOshis, jwtutil.RequireScope(jwtutil.ScopeOshisRead))
+	protected.POST("/oshis/new", oshiHandler.CreateOshi, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.PUT("/oshis/:oshiId", oshiHandler.UpdateOshi, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.PATCH("/oshis/:oshiId", oshiHandler.PatchOshi, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.POST("/oshis/:oshiId/accounts", oshiHandler.AddAccount, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.DELETE("/oshis/:oshiId/accounts", oshiHandler.RemoveAccount, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.POST("/oshis/:oshiId/categories/:slug", oshiHandler.AddCategory, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.DELETE("/oshis/:oshiId/categories/:slug", oshiHandler.RemoveCategory, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.GET("/oshis/:oshiId", oshiGetHandler.GetMyOshiByID, jwtutil.RequireScope(jwtutil.ScopeOshisRead))
+	protected.DELETE("/oshis/:oshiId", oshiHandler.DeleteOshi, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.POST("/oshis/:oshiId/restore", oshiHandler.RestoreOshi, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.POST("/oshis/:oshiId/events/import", eventImportHandler.ImportEvents, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
+	protected.PUT("/oshis/:oshiId/auto-event-mode", oshiHandler.UpdateAutoEventMode, jwtutil.RequireScope(jwtutil.ScopeOshisWrite))
+	protected.POST("/oshis/:oshiId/sync", eventAutoHandler.SyncOshi, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
 
 	// イベント関連のエンドポイント
-	protected.GET("/events", eventsHandler.GetMyOshiEvents)
-	protected.GET("/events/:eventId", eventsHandler.GetEventByID)
-	protected.PUT("/events/:eventId", eventsHandler.UpdateEvent)
-	protected.POST("/events/new", eventsHandler.CreateEvent)
+	protected.GET("/events", eventsHandler.GetMyOshiEvents, jwtutil.RequireScope(jwtutil.ScopeEventsRead))
+	protected.GET("/events/:eventId", eventsHandler.GetEventByID, jwtutil.RequireScope(jwtutil.ScopeEventsRead))
+	protected.PUT("/events/:eventId", eventsHandler.UpdateEvent, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
+	protected.POST("/events/new", eventsHandler.CreateEvent, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
+	protected.DELETE("/events/:eventId", eventsHandler.DeleteEvent, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
+	protected.POST("/events/:eventId/restore", eventsHandler.RestoreEvent, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
+	protected.PUT("/events/:eventId/occurrences/:recurrenceId", eventsHandler.UpdateOccurrence, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
+	protected.DELETE("/events/:eventId/occurrences/:recurrenceId", eventsHandler.CancelOccurrence, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
+
+	// 自動検出したイベント候補（確認待ち）のエンドポイント
+	protected.GET("/event-candidates", eventCandidateHandler.GetMyEventCandidates, jwtutil.RequireScope(jwtutil.ScopeEventsRead))
+	protected.POST("/event-candidates/:candidateId/accept", eventCandidateHandler.AcceptEventCandidate, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
+	protected.POST("/event-candidates/:candidateId/edit-and-accept", eventCandidateHandler.EditAndAcceptEventCandidate, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
+	protected.POST("/event-candidates/:candidateId/reject", eventCandidateHandler.RejectEventCandidate, jwtutil.RequireScope(jwtutil.ScopeEventsWrite))
 
-	// API接続テスト用のユーザー情報取得
-	api.GET("/users/:id", userHandler.GetUser)
+	// カレンダーフィード関連のエンドポイント
+	protected.GET("/calendar-feeds", calendarFeedHandler.GetMyCalendarFeeds)
+	protected.POST("/calendar-feeds/new", calendarFeedHandler.CreateCalendarFeed)
+	protected.POST("/calendar-feeds/:feedId/rotate", calendarFeedHandler.RotateCalendarFeed)
+	protected.DELETE("/calendar-feeds/:feedId", calendarFeedHandler.RevokeCalendarFeed)
 
-	// イベント自動登録エンドポイント（内部処理用）
+	// イベント自動登録エンドポイント（内部処理用、管理者またはサービスのみ）
 	z := api.Group("/z")
+	z.Use(jwtutil.AdminMiddleware())
 	z.POST("/events", eventAutoHandler.ProcessAutoEvents)
 	z.GET("/scheduler/status", schedulerHandler.GetSchedulerStatus)
+
+	// ユーザー管理（検索と推し・イベント件数の確認）
+	z.GET("/users", adminUserHandler.SearchUsers)
+	z.GET("/users/:userId", adminUserHandler.GetUser)
+
+	// 日時抽出パターン（一覧・追加と投稿内容の例での試行）
+	z.GET("/datetime-patterns", dateTimePatternHandler.GetPatterns)
+	z.POST("/datetime-patterns/test", dateTimePatternHandler.TestPatterns)
 }
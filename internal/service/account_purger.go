@@ -0,0 +1,17 @@
+package service
+
+import (
+	"lovender_backend/internal/repository"
+)
+
+// 退会の猶予期間を過ぎたユーザーを定期的に削除する
+type AccountPurger struct {
+	*batchPurger
+}
+
+// コンストラクタ
+func NewAccountPurger(userRepo repository.UserRepository) *AccountPurger {
+	return &AccountPurger{
+		batchPurger: newBatchPurger("Account purger", "deleted accounts", userRepo.PurgeDeletionRequested, accountDeletionGracePeriod),
+	}
+}
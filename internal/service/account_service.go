@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/repository"
+	"lovender_backend/pkg/crypto"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	// トークンの有効期間
+	passwordResetTokenTTL     = time.Hour
+	emailVerificationTokenTTL = 24 * time.Hour
+
+	// トークンのバイト長
+	userTokenBytes = 32
+
+	// メール送信のタイムアウト
+	mailSendTimeout = 30 * time.Second
+)
+
+// AccountService パスワード再設定とメールアドレス確認
+type AccountService interface {
+	SendEmailVerification(user *models.User) error
+	ResendEmailVerification(userID int64) error
+	VerifyEmail(token string) error
+	ForgotPassword(email string, client models.ClientInfo) error
+	ResetPassword(token string, password string) error
+}
+
+type accountService struct {
+	userRepo      repository.UserRepository
+	userTokenRepo repository.UserTokenRepository
+	mailer        Mailer
+	resetThrottle *LoginThrottle
+	appBaseURL    string
+}
+
+func NewAccountService(userRepo repository.UserRepository, userTokenRepo repository.UserTokenRepository, mailer Mailer, resetThrottle *LoginThrottle) AccountService {
+	return &accountService{
+		userRepo:      userRepo,
+		userTokenRepo: userTokenRepo,
+		mailer:        mailer,
+		resetThrottle: resetThrottle,
+		appBaseURL:    os.Getenv("APP_BASE_URL"),
+	}
+}
+
+// メールアドレス確認用のメールを送信
+func (s *accountService) SendEmailVerification(user *models.User) error {
+	token, err := s.createToken(user.ID, repository.UserTokenPurposeEmailVerification, emailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.send(&models.MailMessage{
+		To:      user.Email,
+		Subject: "【Lovender】メールアドレスの確認",
+		Body: fmt.Sprintf(
+			"%s さん\n\nLovenderにご登録いただきありがとうございます。\n以下のリンクからメールアドレスを確認してください（有効期限: 24時間）。\n\n%s\n\n心当たりがない場合はこのメールを破棄してください。\n",
+			user.Name, s.link("/verify-email", token),
+		),
+	})
+}
+
+// メールアドレス確認用のメールを再送信
+func (s *accountService) ResendEmailVerification(userID int64) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email already verified")
+	}
+
+	return s.SendEmailVerification(user)
+}
+
+func (s *accountService) VerifyEmail(token string) error {
+	if token == "" {
+		return errors.New("invalid token")
+	}
+
+	_, err := s.userTokenRepo.VerifyEmail(crypto.HashToken(token))
+	return err
+}
+
+// パスワード再設定用のメールを送信
+// 登録の有無を推測されないよう、ユーザーの確認と送信は非同期で行い、制限中以外はエラーにしない
+// 同じメールアドレス・IPからの送信はresetThrottleで制限する（登録の有無にかかわらず数える）
+func (s *accountService) ForgotPassword(email string, client models.ClientInfo) error {
+	if err := s.resetThrottle.Check(email, client.IPAddress); err != nil {
+		log.Printf("AUDIT password reset throttled email=%q ip=%s", email, client.IPAddress)
+		return err
+	}
+	s.resetThrottle.RecordFailure(email, client.IPAddress)
+
+	go func() {
+		if err := s.sendPasswordReset(email); err != nil {
+			log.Printf("ForgotPassword ERROR: %v", err)
+		}
+	}()
+	return nil
+}
+
+// 登録済みのメールアドレスならパスワード再設定用のメールを送信
+func (s *accountService) sendPasswordReset(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	token, err := s.createToken(user.ID, repository.UserTokenPurposePasswordReset, passwordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.send(&models.MailMessage{
+		To:      user.Email,
+		Subject: "【Lovender】パスワードの再設定",
+		Body: fmt.Sprintf(
+			"%s さん\n\n以下のリンクからパスワードを再設定してください（有効期限: 1時間）。\n\n%s\n\n心当たりがない場合はこのメールを破棄してください。パスワードは変更されません。\n",
+			user.Name, s.link("/reset-password", token),
+		),
+	})
+}
+
+// パスワードを再設定（すべての端末からログアウトされる）
+func (s *accountService) ResetPassword(token string, password string) error {
+	if token == "" {
+		return errors.New("invalid token")
+	}
+
+	hashedPassword, err := crypto.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	userID, err := s.userTokenRepo.ResetPassword(crypto.HashToken(token), hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Password reset for user %d", userID)
+	return nil
+}
+
+func (s *accountService) createToken(userID int64, purpose string, ttl time.Duration) (string, error) {
+	token, err := crypto.GenerateRandomToken(userTokenBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	if err := s.userTokenRepo.Create(userID, purpose, crypto.HashToken(token), time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func (s *accountService) send(msg *models.MailMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+	defer cancel()
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// メールに記載するリンク（APP_BASE_URL未設定時はトークンのみ）
+func (s *accountService) link(path string, token string) string {
+	if s.appBaseURL == "" {
+		return "token: " + token
+	}
+	return s.appBaseURL + path + "?token=" + url.QueryEscape(token)
+}
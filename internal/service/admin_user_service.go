@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/repository"
+	"strings"
+)
+
+const (
+	// 管理者向けユーザー一覧の1ページあたりの件数
+	defaultAdminUsersPerPage = 50
+	maxAdminUsersPerPage     = 200
+)
+
+// AdminUserService 管理者向けのユーザー検索
+type AdminUserService interface {
+	SearchUsers(query string, page int, perPage int) (*models.AdminUsersResponse, error)
+	GetUser(id int64) (*models.AdminUserItem, error)
+}
+
+type adminUserService struct {
+	userRepo repository.UserRepository
+}
+
+func NewAdminUserService(userRepo repository.UserRepository) AdminUserService {
+	return &adminUserService{userRepo: userRepo}
+}
+
+// メールアドレスまたは名前でユーザーを検索（pageは1始まり）
+func (s *adminUserService) SearchUsers(query string, page int, perPage int) (*models.AdminUsersResponse, error) {
+	page, perPage = normalizeAdminUsersPage(page, perPage)
+
+	users, total, err := s.userRepo.SearchWithStats(&models.AdminUserFilter{
+		Query:  strings.TrimSpace(query),
+		Limit:  perPage,
+		Offset: (page - 1) * perPage,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AdminUsersResponse{
+		Users:   users,
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}, nil
+}
+
+func (s *adminUserService) GetUser(id int64) (*models.AdminUserItem, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid user ID")
+	}
+
+	user, err := s.userRepo.GetWithStatsByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	return user, nil
+}
+
+// ページ番号と件数を有効な範囲に丸める
+func normalizeAdminUsersPage(page int, perPage int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = defaultAdminUsersPerPage
+	}
+	if perPage > maxAdminUsersPerPage {
+		perPage = maxAdminUsersPerPage
+	}
+	return page, perPage
+}
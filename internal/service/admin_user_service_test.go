@@ -0,0 +1,28 @@
+package service
+
+import "testing"
+
+func TestNormalizeAdminUsersPage(t *testing.T) {
+	tests := []struct {
+		name        string
+		page        int
+		perPage     int
+		wantPage    int
+		wantPerPage int
+	}{
+		{"未指定は1ページ目と既定の件数", 0, 0, 1, defaultAdminUsersPerPage},
+		{"指定した値をそのまま使う", 3, 20, 3, 20},
+		{"負の値は既定値に丸める", -1, -5, 1, defaultAdminUsersPerPage},
+		{"上限を超える件数は上限に丸める", 2, 1000, 2, maxAdminUsersPerPage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, perPage := normalizeAdminUsersPage(tt.page, tt.perPage)
+			if page != tt.wantPage || perPage != tt.wantPerPage {
+				t.Errorf("normalizeAdminUsersPage(%d, %d) = (%d, %d), want (%d, %d)",
+					tt.page, tt.perPage, page, perPage, tt.wantPage, tt.wantPerPage)
+			}
+		})
+	}
+}
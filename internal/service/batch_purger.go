@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// 保持期間を過ぎたデータをバッチ単位で定期的に削除する
+type batchPurger struct {
+	name      string
+	target    string
+	purgeFunc func(before time.Time, limit int) (int64, error)
+	retention time.Duration
+	interval  time.Duration
+	batchSize int
+	ctx       context.Context
+	cancel    context.CancelFunc
+}
+
+// コンストラクタ（purgeFuncはbefore以前のデータを最大limit件削除し、削除件数を返す）
+func newBatchPurger(name string, target string, purgeFunc func(before time.Time, limit int) (int64, error), retention time.Duration) *batchPurger {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &batchPurger{
+		name:      name,
+		target:    target,
+		purgeFunc: purgeFunc,
+		retention: retention,
+		interval:  1 * time.Hour,
+		batchSize: 100,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// 定期実行を開始
+func (p *batchPurger) Start() {
+	go p.run()
+}
+
+// 定期実行のメインループ
+func (p *batchPurger) run() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.purge(time.Now().UTC())
+
+	for {
+		select {
+		case <-ticker.C:
+			p.purge(time.Now().UTC())
+		case <-p.ctx.Done():
+			log.Printf("%s stopped", p.name)
+			return
+		}
+	}
+}
+
+// 保持期間を過ぎたデータをバッチ単位で削除
+func (p *batchPurger) purge(now time.Time) int64 {
+	before := now.Add(-p.retention)
+
+	var total int64
+	for p.ctx.Err() == nil {
+		purged, err := p.purgeFunc(before, p.batchSize)
+		if err != nil {
+			log.Printf("Failed to purge %s: %v", p.target, err)
+			break
+		}
+		total += purged
+		if purged < int64(p.batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Purged %d %s", total, p.target)
+	}
+	return total
+}
+
+// 定期実行を停止
+func (p *batchPurger) Stop() {
+	log.Printf("Stopping %s...", p.name)
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+}
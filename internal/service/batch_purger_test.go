@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBatchPurger_Purge(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		results   []int64
+		err       error
+		wantTotal int64
+		wantCalls int
+	}{
+		{"削除対象なし", []int64{0}, nil, 0, 1},
+		{"1バッチに収まる", []int64{30}, nil, 30, 1},
+		{"バッチが埋まる間は繰り返す", []int64{100, 100, 5}, nil, 205, 3},
+		{"ちょうどバッチサイズの倍数", []int64{100, 0}, nil, 100, 2},
+		{"エラーで中断", []int64{100}, errors.New("db error"), 100, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			p := newBatchPurger("Test purger", "test rows", func(before time.Time, limit int) (int64, error) {
+				calls++
+				if want := now.Add(-24 * time.Hour); !before.Equal(want) {
+					t.Errorf("before = %v, 期待値 %v", before, want)
+				}
+				if limit != 100 {
+					t.Errorf("limit = %d, 期待値 100", limit)
+				}
+				if calls > len(tt.results) {
+					return 0, tt.err
+				}
+				return tt.results[calls-1], nil
+			}, 24*time.Hour)
+			defer p.Stop()
+
+			if total := p.purge(now); total != tt.wantTotal {
+				t.Errorf("total = %d, 期待値 %d", total, tt.wantTotal)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, 期待値 %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBatchPurger_StopsAfterCancel(t *testing.T) {
+	calls := 0
+	p := newBatchPurger("Test purger", "test rows", func(before time.Time, limit int) (int64, error) {
+		calls++
+		return int64(limit), nil
+	}, time.Hour)
+	p.Stop()
+
+	if total := p.purge(time.Now().UTC()); total != 0 || calls != 0 {
+		t.Errorf("停止後に削除が実行された: total = %d, calls = %d", total, calls)
+	}
+}
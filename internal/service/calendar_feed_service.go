@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/repository"
+	"lovender_backend/pkg/crypto"
+	"lovender_backend/pkg/ical"
+	"time"
+)
+
+const (
+	// フィードトークンのバイト長（Base64で43文字）
+	calendarFeedTokenBytes = 32
+	// フィードに含めるイベントの期間と上限
+	calendarFeedLookback  = 90 * 24 * time.Hour
+	calendarFeedLookahead = 365 * 24 * time.Hour
+	calendarFeedMaxEvents = 1000
+
+	calendarFeedProdID = "-//lovender//oshi calendar//JA"
+	calendarFeedName   = "Lovender"
+)
+
+type CalendarFeedService interface {
+	CreateFeed(userID int64, req *models.CreateCalendarFeedRequest, baseURL string) (*models.CalendarFeedResponse, error)
+	GetFeeds(userID int64) (*models.CalendarFeedsResponse, error)
+	RotateFeed(feedID int64, userID int64, baseURL string) (*models.CalendarFeedResponse, error)
+	RevokeFeed(feedID int64, userID int64) error
+	RenderFeed(token string) ([]byte, error)
+}
+
+type calendarFeedService struct {
+	feedRepo   repository.CalendarFeedRepository
+	eventsRepo repository.EventsRepository
+}
+
+func NewCalendarFeedService(feedRepo repository.CalendarFeedRepository, eventsRepo repository.EventsRepository) CalendarFeedService {
+	return &calendarFeedService{
+		feedRepo:   feedRepo,
+		eventsRepo: eventsRepo,
+	}
+}
+
+func (s *calendarFeedService) CreateFeed(userID int64, req *models.CreateCalendarFeedRequest, baseURL string) (*models.CalendarFeedResponse, error) {
+	token, err := crypto.GenerateRandomToken(calendarFeedTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	feed, err := s.feedRepo.Create(userID, req.OshiID, crypto.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CalendarFeedResponse{
+		Feed: toCalendarFeedItem(feed, calendarFeedURL(baseURL, token)),
+	}, nil
+}
+
+func (s *calendarFeedService) GetFeeds(userID int64) (*models.CalendarFeedsResponse, error) {
+	feeds, err := s.feedRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// トークンは保存していないため一覧ではURLを返さない
+	items := make([]models.CalendarFeedItem, 0, len(feeds))
+	for _, feed := range feeds {
+		items = append(items, toCalendarFeedItem(feed, ""))
+	}
+
+	return &models.CalendarFeedsResponse{Feeds: items}, nil
+}
+
+func (s *calendarFeedService) RotateFeed(feedID int64, userID int64, baseURL string) (*models.CalendarFeedResponse, error) {
+	token, err := crypto.GenerateRandomToken(calendarFeedTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	feed, err := s.feedRepo.Rotate(feedID, userID, crypto.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CalendarFeedResponse{
+		Feed: toCalendarFeedItem(feed, calendarFeedURL(baseURL, token)),
+	}, nil
+}
+
+func (s *calendarFeedService) RevokeFeed(feedID int64, userID int64) error {
+	return s.feedRepo.Revoke(feedID, userID)
+}
+
+// トークンに対応するフィードのイベントをiCalendar形式で出力
+func (s *calendarFeedService) RenderFeed(token string) ([]byte, error) {
+	if token == "" {
+		return nil, errors.New("calendar feed not found")
+	}
+
+	feed, err := s.feedRepo.GetByTokenHash(crypto.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	// 直近の過去分と今後1年のイベントを対象にする（繰り返しイベントはこの期間で展開される）
+	// 上限を超える場合は今後のイベントを優先し、残りの件数で過去分を含める
+	now := time.Now().UTC()
+	future, futureTruncated, err := s.eventsRepo.GetOshiEventsByUserID(feed.UserID, calendarFeedFilter(feed, now, now.Add(calendarFeedLookahead), calendarFeedMaxEvents))
+	if err != nil {
+		return nil, err
+	}
+
+	events := future
+	pastTruncated := false
+	if remaining := calendarFeedMaxEvents - countOshiEvents(future); remaining > 0 {
+		var past *models.OshiEventsResponse
+		// 過去分は現在に近いものを残し、上限を超える分は古いものから除く
+		pastFilter := calendarFeedFilter(feed, now.Add(-calendarFeedLookback), now, remaining)
+		pastFilter.Newest = true
+		past, pastTruncated, err = s.eventsRepo.GetOshiEventsByUserID(feed.UserID, pastFilter)
+		if err != nil {
+			return nil, err
+		}
+		events = mergeOshiEvents(past, future)
+	} else {
+		pastTruncated = true
+	}
+
+	if futureTruncated || pastTruncated {
+		log.Printf("RenderFeed WARNING: calendar feed %d truncated to %d events (future truncated: %v)", feed.ID, countOshiEvents(events), futureTruncated)
+	}
+
+	calendar := buildCalendar(events, feed.OshiID != nil)
+
+	var buf bytes.Buffer
+	if err := calendar.Encode(&buf, now); err != nil {
+		return nil, fmt.Errorf("failed to encode calendar: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// フィードに含めるイベントの絞り込み条件（[from, to) に開始するイベントを最大limit件）
+func calendarFeedFilter(feed *models.CalendarFeed, from, to time.Time, limit int) *models.EventFilter {
+	filter := &models.EventFilter{
+		From:  &from,
+		To:    &to,
+		Limit: limit,
+	}
+	if feed.OshiID != nil {
+		filter.OshiIDs = []int64{*feed.OshiID}
+	}
+	return filter
+}
+
+func countOshiEvents(events *models.OshiEventsResponse) int {
+	count := 0
+	for _, oshi := range events.Oshis {
+		count += len(oshi.Events)
+	}
+	return count
+}
+
+// 過去分と今後のイベントを推しごとに開始日時順で結合
+func mergeOshiEvents(past, future *models.OshiEventsResponse) *models.OshiEventsResponse {
+	merged := &models.OshiEventsResponse{
+		Oshis: make([]models.OshiEventsResponseItem, 0, len(future.Oshis)),
+	}
+	pastEvents := make(map[int64][]models.Event)
+	for _, oshi := range past.Oshis {
+		pastEvents[oshi.ID] = oshi.Events
+	}
+	for _, oshi := range future.Oshis {
+		events := make([]models.Event, 0, len(pastEvents[oshi.ID])+len(oshi.Events))
+		events = append(events, pastEvents[oshi.ID]...)
+		events = append(events, oshi.Events...)
+		oshi.Events = events
+		merged.Oshis = append(merged.Oshis, oshi)
+	}
+	return merged
+}
+
+// 推しごとのイベントをVEVENTに変換
+func buildCalendar(events *models.OshiEventsResponse, singleOshi bool) *ical.Calendar {
+	calendar := &ical.Calendar{
+		ProdID: calendarFeedProdID,
+		Name:   calendarFeedName,
+		Events: make([]ical.Event, 0),
+	}
+	if singleOshi && len(events.Oshis) == 1 {
+		calendar.Name = calendarFeedName + " - " + events.Oshis[0].Name
+	}
+
+	for _, oshi := range events.Oshis {
+		for _, event := range oshi.Events {
+			calendar.Events = append(calendar.Events, toICalEvent(oshi.Name, event))
+		}
+	}
+
+	return calendar
+}
+
+func toICalEvent(oshiName string, event models.Event) ical.Event {
+	icalEvent := ical.Event{
+		UID:     fmt.Sprintf("event-%d@lovender", event.ID),
+		Summary: fmt.Sprintf("[%s] %s", oshiName, event.Title),
+		Start:   event.Starts_at,
+		End:     event.Ends_at,
+	}
+	// 繰り返しイベントは回ごとに別のVEVENTとして出力する
+	if event.Recurrence_id != nil {
+		icalEvent.UID = fmt.Sprintf("event-%d-%s@lovender", event.ID, event.Recurrence_id.UTC().Format("20060102T150405Z"))
+	}
+	if event.Description != nil {
+		icalEvent.Description = *event.Description
+	}
+	if event.URL != nil {
+		icalEvent.URL = *event.URL
+	}
+	if event.Category != nil {
+		icalEvent.Categories = []string{event.Category.Name}
+	}
+	if event.Has_alarm {
+		if before, ok := notificationTimingDuration(event.Notification_timing); ok {
+			icalEvent.Alarm = &ical.Alarm{Before: before}
+		}
+	}
+
+	return icalEvent
+}
+
+// notification_timingを開始前の時間に変換
+func notificationTimingDuration(timing string) (time.Duration, bool) {
+	for _, t := range models.NotificationTimings {
+		if t.Timing == timing {
+			return t.Before, true
+		}
+	}
+	return 0, false
+}
+
+func calendarFeedURL(baseURL string, token string) string {
+	return baseURL + "/api/calendar/" + token + ".ics"
+}
+
+func toCalendarFeedItem(feed *models.CalendarFeed, url string) models.CalendarFeedItem {
+	return models.CalendarFeedItem{
+		ID:        feed.ID,
+		OshiID:    feed.OshiID,
+		URL:       url,
+		CreatedAt: feed.CreatedAt,
+		RotatedAt: feed.RotatedAt,
+	}
+}
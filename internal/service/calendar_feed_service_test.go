@@ -0,0 +1,106 @@
+package service
+
+import (
+	"lovender_backend/internal/models"
+	"testing"
+	"time"
+)
+
+func TestToICalEvent(t *testing.T) {
+	description := "当日券あり"
+	url := "https://example.com/live"
+	endsAt := time.Date(2026, 1, 10, 7, 0, 0, 0, time.UTC)
+	event := models.Event{
+		ID:                  42,
+		Title:               "ワンマンライブ",
+		Description:         &description,
+		URL:                 &url,
+		Starts_at:           time.Date(2026, 1, 10, 5, 0, 0, 0, time.UTC),
+		Ends_at:             &endsAt,
+		Has_alarm:           true,
+		Notification_timing: "1h",
+		Category:            &models.CategoryItem{ID: 1, Slug: "live", Name: "ライブ・コンサート"},
+	}
+
+	actual := toICalEvent("山田美咲", event)
+
+	if actual.UID != "event-42@lovender" {
+		t.Errorf("UIDが一致しません: %q", actual.UID)
+	}
+	if actual.Summary != "[山田美咲] ワンマンライブ" {
+		t.Errorf("SUMMARYが一致しません: %q", actual.Summary)
+	}
+	if actual.Description != description || actual.URL != url {
+		t.Errorf("DESCRIPTION/URLが一致しません: %q %q", actual.Description, actual.URL)
+	}
+	if len(actual.Categories) != 1 || actual.Categories[0] != "ライブ・コンサート" {
+		t.Errorf("CATEGORIESが一致しません: %v", actual.Categories)
+	}
+	if actual.Alarm == nil || actual.Alarm.Before != time.Hour {
+		t.Errorf("VALARMが一致しません: %+v", actual.Alarm)
+	}
+
+	// 繰り返しイベントの回はUIDを分ける
+	recurrenceID := time.Date(2026, 1, 17, 5, 0, 0, 0, time.UTC)
+	event.Recurrence_id = &recurrenceID
+	if actual := toICalEvent("山田美咲", event); actual.UID != "event-42-20260117T050000Z@lovender" {
+		t.Errorf("繰り返しの回のUIDが一致しません: %q", actual.UID)
+	}
+
+	// アラームなしのイベントにはVALARMを付けない
+	event.Has_alarm = false
+	if actual := toICalEvent("山田美咲", event); actual.Alarm != nil {
+		t.Errorf("アラームなしのイベントにVALARMが設定されています: %+v", actual.Alarm)
+	}
+}
+
+func TestNotificationTimingDuration(t *testing.T) {
+	tests := []struct {
+		timing   string
+		expected time.Duration
+		ok       bool
+	}{
+		{"0", 0, true},
+		{"15m", 15 * time.Minute, true},
+		{"2h", 2 * time.Hour, true},
+		{"1w", 7 * 24 * time.Hour, true},
+		{"", 0, false},
+		{"3h", 0, false},
+	}
+
+	for _, tt := range tests {
+		actual, ok := notificationTimingDuration(tt.timing)
+		if actual != tt.expected || ok != tt.ok {
+			t.Errorf("notificationTimingDuration(%q) = (%v, %v), 期待値 (%v, %v)", tt.timing, actual, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestMergeOshiEvents(t *testing.T) {
+	past := &models.OshiEventsResponse{Oshis: []models.OshiEventsResponseItem{
+		{ID: 1, Name: "推しA", Events: []models.Event{{ID: 1}}},
+		{ID: 2, Name: "推しB", Events: []models.Event{{ID: 2}, {ID: 3}}},
+	}}
+	future := &models.OshiEventsResponse{Oshis: []models.OshiEventsResponseItem{
+		{ID: 1, Name: "推しA", Events: []models.Event{{ID: 4}}},
+		{ID: 2, Name: "推しB", Events: []models.Event{}},
+	}}
+
+	actual := mergeOshiEvents(past, future)
+
+	if len(actual.Oshis) != 2 {
+		t.Fatalf("推しの件数が一致しません: %d", len(actual.Oshis))
+	}
+	if len(actual.Oshis[0].Events) != 2 || actual.Oshis[0].Events[0].ID != 1 || actual.Oshis[0].Events[1].ID != 4 {
+		t.Errorf("推しAのイベントが過去分・今後の順になっていません: %+v", actual.Oshis[0].Events)
+	}
+	if len(actual.Oshis[1].Events) != 2 {
+		t.Errorf("推しBの過去分のイベントが含まれていません: %+v", actual.Oshis[1].Events)
+	}
+	if countOshiEvents(actual) != 4 {
+		t.Errorf("イベント数が一致しません: %d", countOshiEvents(actual))
+	}
+	if len(future.Oshis[0].Events) != 1 {
+		t.Errorf("結合元のイベントが変更されました: %+v", future.Oshis[0].Events)
+	}
+}
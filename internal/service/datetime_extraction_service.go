@@ -2,990 +2,445 @@ package service
 
 import (
 	"log"
+	"lovender_backend/internal/models"
+	"math"
 	"regexp"
-	"strconv"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 // DateTimeExtractionService 日時抽出サービス
-type DateTimeExtractionService struct{}
+// パターンは datetime_patterns.json の定義から生成し、LoadPatterns・RegisterPattern で追加できる
+type DateTimeExtractionService struct {
+	mu       sync.RWMutex
+	patterns []dateTimePattern
+}
 
-// NewDateTimeExtractionService コンストラクタ
+// NewDateTimeExtractionService コンストラクタ（組み込みのパターンを読み込む）
 func NewDateTimeExtractionService() *DateTimeExtractionService {
-	return &DateTimeExtractionService{}
+	s := &DateTimeExtractionService{}
+	s.loadBuiltinPatterns()
+	return s
+}
+
+// 年の記載がない日付を投稿日より前とみなす日数
+// これより前の日付は翌年、投稿日の1年後からこの日数を引いた日以降の日付は前年として扱う
+// 例: 12/20の投稿の"1/10"は翌年、1/5の投稿の"12/31"は前年、12/20の投稿の"12/10"は同じ年（終わったイベント）
+const pastDateToleranceDays = 30
+
+// 前後の文脈から過去の日付であると判断する表現
+var pastDateContextRegex = regexp.MustCompile(`昨日|おととい|先日|先週|先月|昨年|去年|前回|(?i:\b(?:was|were|yesterday|last (?:week|month|year))\b)`)
+
+// DateTimeMatch 日時抽出で一致したパターン
+type DateTimeMatch struct {
+	Name    string // パターン名（複数のパターンを組み合わせた場合は "+" で連結）
+	Pattern string // 一致した正規表現
+	Text    string // 正規表現に一致した箇所の元の投稿内容（全角数字・漢数字などは正規化前のまま）
+	Start   int    // 投稿内容での開始位置（文字数）
+	End     int    // 投稿内容での終了位置（文字数、この位置の文字は含まない）
+}
+
+// DateTimeSpan 投稿内容から抽出した日時1件
+type DateTimeSpan struct {
+	StartsAt    time.Time
+	EndsAt      *time.Time
+	Match       DateTimeMatch
+	Confidence  float64  // 信頼度（0〜1、年・日付・時刻・終了がどこまで明示されているか）
+	Assumptions []string // 投稿に書かれておらず推測した内容（DateTimeAssumption*）
+}
+
+// 日時抽出で推測した内容
+const (
+	DateTimeAssumptionYearInferred     = "year_inferred"     // 年の記載がなく、投稿日から推測した
+	DateTimeAssumptionDateInferred     = "date_inferred"     // 日付の記載がなく、投稿日とした
+	DateTimeAssumptionRelativeDate     = "relative_date"     // "明日"・"今度の土曜日"などを投稿日から計算した
+	DateTimeAssumptionTimeInferred     = "time_inferred"     // 時刻の記載がなく、0:00とした
+	DateTimeAssumptionTimeApproximate  = "time_approximate"  // "夕方"などのおおよその時間帯から時刻を決めた
+	DateTimeAssumptionStartInferred    = "start_inferred"    // 終了（"まで"・期間）のみの記載で、開始を投稿日時とした
+	DateTimeAssumptionDurationInferred = "duration_inferred" // 終了の記載がなく、既定の長さとした
+	DateTimeAssumptionPastContext      = "past_context"      // "先日"などの文脈から、年の記載がない日付を投稿日以前とした
+)
+
+// パターンに明示されている情報
+type dateTimeParts int
+
+const (
+	partYear            dateTimeParts = 1 << iota // 年
+	partDate                                      // 月日
+	partRelativeDate                              // 相対日付（明日、今度の土曜日など）
+	partTime                                      // 時刻
+	partApproximateTime                           // おおよその時間帯（夕方など）
+	partEnd                                       // 終了時刻・期間
+	partUntil                                     // 終了のみで開始の記載がない（"まで"・期間）
+)
+
+// パターンに含まれる情報の種類
+type dateTimeKind int
+
+const (
+	dateTimeKindDateTime dateTimeKind = iota // 日付と時刻（相対日付を含む）
+	dateTimeKindDate                         // 日付のみ（後に続く時刻と組み合わせる）
+	dateTimeKindTime                         // 時刻のみ（投稿日、または直前の日付の時刻）
+	dateTimeKindOther                        // 期間など
+)
+
+// 日時抽出のパターン（優先度の高い順に並べる）
+type dateTimePattern struct {
+	name    string
+	kind    dateTimeKind
+	parts   dateTimeParts
+	regex   *regexp.Regexp
+	valid   func([]string) bool // 一致した内容が日時として有効か（存在しない月日は使わない）
+	handler func([]string, time.Time) (time.Time, *time.Time)
+	def     models.DateTimePatternDefinition
 }
 
 // ExtractDateTime 投稿内容から日時情報を抽出
 func (s *DateTimeExtractionService) ExtractDateTime(content string, postCreatedAt time.Time) (time.Time, *time.Time, bool) {
-	// 日時抽出用の正規表現パターン
-	patterns := []struct {
-		regex   *regexp.Regexp
-		handler func([]string, time.Time) (time.Time, *time.Time)
-	}{
-		// パターン1: "2026年1月10日 14:00-16:00" (年月日+時刻範囲)
-		{
-			regexp.MustCompile(`(\d{4})年(\d{1,2})月(\d{1,2})日[\s　]*(\d{1,2}):(\d{2})\s*[-〜～]\s*(\d{1,2}):(\d{2})`),
-			s.handleYearDateTimeRange,
-		},
-		// パターン2: "2026年1月10日 14:00" (年月日+時刻)
-		{
-			regexp.MustCompile(`(\d{4})年(\d{1,2})月(\d{1,2})日[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleYearDateTime,
-		},
-		// パターン3: "2026年1月10日" (年月日のみ)
-		{
-			regexp.MustCompile(`(\d{4})年(\d{1,2})月(\d{1,2})日`),
-			s.handleYearDateOnly,
-		},
-		// パターン4: "10/6（月）18:00まで" (月日曜日+時刻まで)
-		{
-			regexp.MustCompile(`(\d{1,2})/(\d{1,2})（[月火水木金土日]）[\s　]*(\d{1,2}):(\d{2})\s*まで`),
-			s.handleSlashDateWeekdayTimeUntil,
-		},
-		// パターン5: "10/6（月）18:00-20:00" (月日曜日+時刻範囲)
-		{
-			regexp.MustCompile(`(\d{1,2})/(\d{1,2})（[月火水木金土日]）[\s　]*(\d{1,2}):(\d{2})\s*[-〜～]\s*(\d{1,2}):(\d{2})`),
-			s.handleSlashDateWeekdayTimeRange,
-		},
-		// パターン6: "10/6（月）18:00" (月日曜日+時刻)
-		{
-			regexp.MustCompile(`(\d{1,2})/(\d{1,2})（[月火水木金土日]）[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleSlashDateWeekdayTime,
-		},
-		// パターン7: "10/6（月）" (月日曜日のみ)
-		{
-			regexp.MustCompile(`(\d{1,2})/(\d{1,2})（[月火水木金土日]）`),
-			s.handleSlashDateWeekdayOnly,
-		},
-		// パターン8: "10/6 18:00まで" (スラッシュ日付+時刻まで)
-		{
-			regexp.MustCompile(`(\d{1,2})/(\d{1,2})[\s　]+(\d{1,2}):(\d{2})\s*まで`),
-			s.handleSlashDateTimeUntil,
-		},
-		// パターン9: "10/6 18:00-20:00" (スラッシュ日付+時刻範囲)
-		{
-			regexp.MustCompile(`(\d{1,2})/(\d{1,2})[\s　]+(\d{1,2}):(\d{2})\s*[-〜～]\s*(\d{1,2}):(\d{2})`),
-			s.handleSlashDateTimeRange,
-		},
-		// パターン10: "10/6 18:00" (スラッシュ日付+時刻)
-		{
-			regexp.MustCompile(`(\d{1,2})/(\d{1,2})[\s　]+(\d{1,2}):(\d{2})`),
-			s.handleSlashDateTime,
-		},
-		// パターン11: "10/6" (スラッシュ日付のみ)
-		{
-			regexp.MustCompile(`(\d{1,2})/(\d{1,2})`),
-			s.handleSlashDateOnly,
-		},
-		// パターン12: "10月3日（月）18:00まで" (月日曜日+時刻まで)
-		{
-			regexp.MustCompile(`(\d{1,2})月(\d{1,2})日（[月火水木金土日]）[\s　]*(\d{1,2}):(\d{2})\s*まで`),
-			s.handleDateWeekdayTimeUntil,
-		},
-		// パターン13: "10月3日（月）18:00-20:00" (月日曜日+時刻範囲)
-		{
-			regexp.MustCompile(`(\d{1,2})月(\d{1,2})日（[月火水木金土日]）[\s　]*(\d{1,2}):(\d{2})\s*[-〜～]\s*(\d{1,2}):(\d{2})`),
-			s.handleDateWeekdayTimeRange,
-		},
-		// パターン14: "10月3日（月）18:00" (月日曜日+時刻)
-		{
-			regexp.MustCompile(`(\d{1,2})月(\d{1,2})日（[月火水木金土日]）[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleDateWeekdayTime,
-		},
-		// パターン15: "10月3日（月）" (月日曜日のみ)
-		{
-			regexp.MustCompile(`(\d{1,2})月(\d{1,2})日（[月火水木金土日]）`),
-			s.handleDateWeekdayOnly,
-		},
-		// パターン16: "10月3日 14:00-16:00" (月日+時刻範囲)
-		{
-			regexp.MustCompile(`(\d{1,2})月(\d{1,2})日[\s　]*(\d{1,2}):(\d{2})\s*[-〜～]\s*(\d{1,2}):(\d{2})`),
-			s.handleDateTimeRange,
-		},
-		// パターン17: "10月3日 14:00" (月日+時刻)
-		{
-			regexp.MustCompile(`(\d{1,2})月(\d{1,2})日[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleDateTime,
-		},
-		// パターン18: "14:00-16:00" (時刻範囲のみ)
-		{
-			regexp.MustCompile(`(\d{1,2}):(\d{2})\s*[-〜～]\s*(\d{1,2}):(\d{2})`),
-			s.handleTimeRange,
-		},
-		// パターン19: "14時30分〜16時45分" (時分範囲・日本語)
-		{
-			regexp.MustCompile(`(\d{1,2})時(\d{1,2})分\s*[〜～]\s*(\d{1,2})時(\d{1,2})分`),
-			s.handleJapaneseTimeMinuteRange,
-		},
-		// パターン20: "14時30分から16時45分" (時分範囲・から)
-		{
-			regexp.MustCompile(`(\d{1,2})時(\d{1,2})分から\s*(\d{1,2})時(\d{1,2})分`),
-			s.handleJapaneseTimeMinuteFromTo,
-		},
-		// パターン21: "14時30分から" (時分開始・から)
-		{
-			regexp.MustCompile(`(\d{1,2})時(\d{1,2})分から[！!]?`),
-			s.handleJapaneseTimeMinuteFrom,
-		},
-		// パターン22: "14時30分〜" (時分開始・〜)
-		{
-			regexp.MustCompile(`(\d{1,2})時(\d{1,2})分[〜～][！!]?`),
-			s.handleJapaneseTimeMinuteStart,
-		},
-		// パターン23: "14時30分" (時分のみ)
-		{
-			regexp.MustCompile(`(\d{1,2})時(\d{1,2})分`),
-			s.handleJapaneseTimeMinute,
-		},
-		// パターン24: "14時〜16時" (時刻範囲・日本語)
-		{
-			regexp.MustCompile(`(\d{1,2})時\s*[〜～]\s*(\d{1,2})時`),
-			s.handleJapaneseTimeRange,
-		},
-		// パターン25: "14時から16時" (時刻範囲・から)
-		{
-			regexp.MustCompile(`(\d{1,2})時から\s*(\d{1,2})時`),
-			s.handleJapaneseTimeFromTo,
-		},
-		// パターン26: "14時から" (開始時刻のみ・から)
-		{
-			regexp.MustCompile(`(\d{1,2})時から[！!]?`),
-			s.handleJapaneseTimeFrom,
-		},
-		// パターン27: "14時〜" (開始時刻のみ・〜)
-		{
-			regexp.MustCompile(`(\d{1,2})時[〜～][！!]?`),
-			s.handleJapaneseTimeStart,
-		},
-		// === 相対日付表現 ===（具体的なパターンを先に配置）
-		// パターン28: "明日 14:00"
-		{
-			regexp.MustCompile(`明日[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleTomorrowTime,
-		},
-		// パターン29: "今日 14:00"
-		{
-			regexp.MustCompile(`今日[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleTodayTime,
-		},
-		// パターン30: "明後日 14:00"
-		{
-			regexp.MustCompile(`明後日[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleDayAfterTomorrowTime,
-		},
-
-		// === 英語混在表現 ===（時刻のみより先に配置）
-		// パターン31: "AM 9:00", "PM 6:00"
-		{
-			regexp.MustCompile(`AM[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleAMTimeEng,
-		},
-		{
-			regexp.MustCompile(`PM[\s　]*(\d{1,2}):(\d{2})`),
-			s.handlePMTimeEng,
-		},
-
-		// === 区切り文字バリエーション ===（時刻のみより先に配置）
-		// パターン32: "10-6 18:00", "10.6 18:00"
-		{
-			regexp.MustCompile(`(\d{1,2})[-.](\d{1,2})[\s　]+(\d{1,2}):(\d{2})`),
-			s.handleAlternativeDateFormat,
-		},
-
-		// === 自然な日本語表現 ===（時刻のみパターンより先に配置）
-		// パターン33-42: "今週の土曜日", "今度の土曜日", "来週月曜日"
-		{
-			regexp.MustCompile(`今週の?([月火水木金土日])曜日[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleThisWeekdayTime,
-		},
-		{
-			regexp.MustCompile(`今度の([月火水木金土日])曜日[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleNextWeekdayTime,
-		},
-		{
-			regexp.MustCompile(`来週の?([月火水木金土日])曜日[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleNextWeekWeekdayTime,
-		},
-		{
-			regexp.MustCompile(`今週の?([月火水木金土日])曜日`),
-			s.handleThisWeekday,
-		},
-		{
-			regexp.MustCompile(`今度の([月火水木金土日])曜日`),
-			s.handleNextWeekday,
-		},
-		{
-			regexp.MustCompile(`来週の?([月火水木金土日])曜日`),
-			s.handleNextWeekWeekday,
-		},
-
-		// パターン37: "14:00" (時刻のみ) - より具体的なパターンの後に配置
-		{
-			regexp.MustCompile(`(\d{1,2}):(\d{2})`),
-			s.handleTimeOnly,
-		},
-		// パターン38: "10月3日" (月日のみ)
-		{
-			regexp.MustCompile(`(\d{1,2})月(\d{1,2})日`),
-			s.handleDateOnly,
-		},
-
-		// === 時間帯表現 ===
-		// パターン39: "午前10時", "午後3時"
-		{
-			regexp.MustCompile(`午前(\d{1,2})時`),
-			s.handleAMTime,
-		},
-		{
-			regexp.MustCompile(`午後(\d{1,2})時`),
-			s.handlePMTime,
-		},
-		// パターン40: "夜8時", "朝9時", "昼12時"
-		{
-			regexp.MustCompile(`夜(\d{1,2})時`),
-			s.handleNightTime,
-		},
-		{
-			regexp.MustCompile(`朝(\d{1,2})時`),
-			s.handleMorningTime,
-		},
-		{
-			regexp.MustCompile(`昼(\d{1,2})時`),
-			s.handleNoonTime,
-		},
-
-		// === 完全日付形式 ===
-		// パターン41: "2025/10/6 18:00"
-		{
-			regexp.MustCompile(`(\d{4})/(\d{1,2})/(\d{1,2})[\s　]*(\d{1,2}):(\d{2})`),
-			s.handleFullSlashDate,
-		},
-
-		// === 曖昧な時間表現 ===
-		// パターン42-45: "夕方", "お昼頃", "夜中", "早朝"
-		{
-			regexp.MustCompile(`夕方`),
-			s.handleEvening,
-		},
-		{
-			regexp.MustCompile(`お昼頃|昼頃`),
-			s.handleAroundNoon,
-		},
-		{
-			regexp.MustCompile(`夜中|深夜`),
-			s.handleMidnight,
-		},
-		{
-			regexp.MustCompile(`早朝`),
-			s.handleEarlyMorning,
-		},
-
-		// === 期間表現 ===
-		// パターン46-47: "3時間", "30分間"
-		{
-			regexp.MustCompile(`(\d{1,2})時間`),
-			s.handleHourDuration,
-		},
-		{
-			regexp.MustCompile(`(\d{1,2})分間`),
-			s.handleMinuteDuration,
-		},
+	startsAt, endsAt, match := s.ExtractDateTimeWithMatch(content, postCreatedAt)
+	return startsAt, endsAt, match != nil
+}
+
+// ExtractDateTimeWithMatch 投稿内容から日時情報を抽出し、一致したパターンも返す
+// 最も優先度の高いパターンの最初の一致のみ使う。パターンが見つからない場合、matchはnil
+func (s *DateTimeExtractionService) ExtractDateTimeWithMatch(content string, postCreatedAt time.Time) (time.Time, *time.Time, *DateTimeMatch) {
+	span := s.Extract(content, postCreatedAt)
+	if span == nil {
+		// パターンが見つからない場合はデフォルト（投稿日の0:00-1:00）を返すが、パターンマッチしなかったことを示す
+		log.Printf("DateTime extraction - No pattern found, using default time")
+		startsAt, endsAt := s.getDefaultDateTime(postCreatedAt)
+		return startsAt, endsAt, nil
 	}
-
-	// 各パターンを試行
-	for _, pattern := range patterns {
-		matches := pattern.regex.FindStringSubmatch(content)
-		if len(matches) > 0 {
+	return span.StartsAt, span.EndsAt, &span.Match
+}
+
+// Extract 投稿内容から最も優先度の高いパターンの最初の一致を抽出し、信頼度と推測した内容を付けて返す
+// 全角数字・漢数字・和暦などは正規化してから一致させる。パターンが見つからない場合はnil
+func (s *DateTimeExtractionService) Extract(content string, postCreatedAt time.Time) *DateTimeSpan {
+	normalized := normalizeDateTimeText(content)
+
+	// 各パターンを試行（存在しない月日の一致と、その範囲に重なる一致は使わない）
+	var rejected [][2]int
+	for _, pattern := range s.currentPatterns() {
+		for _, loc := range pattern.regex.FindAllStringSubmatchIndex(normalized.text, -1) {
+			if overlapsRanges(rejected, loc[0], loc[1]) {
+				continue
+			}
+			matches := submatches(normalized.text, loc)
+			if !pattern.valid(matches) {
+				rejected = append(rejected, [2]int{loc[0], loc[1]})
+				continue
+			}
 			log.Printf("DateTime extraction - Pattern matched: %v", matches)
 			startsAt, endsAt := pattern.handler(matches, postCreatedAt)
-			return startsAt, endsAt, true
+			span := newDateTimeSpan(startsAt, endsAt, newDateTimeMatch(normalized, pattern, loc[0], loc[1]), pattern.parts)
+			s.applyPastContext(&span, normalized.text, loc[0], loc[1], pattern.parts, postCreatedAt)
+			return &span
 		}
 	}
-
-	// パターンが見つからない場合はデフォルト（投稿日の0:00-1:00）を返すが、パターンマッチしなかったことを示す
-	log.Printf("DateTime extraction - No pattern found, using default time")
-	startsAt, endsAt := s.getDefaultDateTime(postCreatedAt)
-	return startsAt, endsAt, false
-}
-
-// 年月日+時刻範囲の処理 (例: "2026年1月10日 14:00-16:00")
-func (s *DateTimeExtractionService) handleYearDateTimeRange(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	year, _ := strconv.Atoi(matches[1])
-	month, _ := strconv.Atoi(matches[2])
-	day, _ := strconv.Atoi(matches[3])
-	startHour, _ := strconv.Atoi(matches[4])
-	startMin, _ := strconv.Atoi(matches[5])
-	endHour, _ := strconv.Atoi(matches[6])
-	endMin, _ := strconv.Atoi(matches[7])
-
-	startsAt := time.Date(year, time.Month(month), day, startHour, startMin, 0, 0, postCreatedAt.Location())
-	endsAt := time.Date(year, time.Month(month), day, endHour, endMin, 0, 0, postCreatedAt.Location())
-
-	return startsAt, &endsAt
-}
-
-// 年月日+時刻の処理 (例: "2026年1月10日 14:00")
-func (s *DateTimeExtractionService) handleYearDateTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	year, _ := strconv.Atoi(matches[1])
-	month, _ := strconv.Atoi(matches[2])
-	day, _ := strconv.Atoi(matches[3])
-	hour, _ := strconv.Atoi(matches[4])
-	min, _ := strconv.Atoi(matches[5])
-
-	startsAt := time.Date(year, time.Month(month), day, hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour) // 1時間後を終了時刻とする
-
-	return startsAt, &endsAt
-}
-
-// 年月日のみの処理 (例: "2026年1月10日")
-func (s *DateTimeExtractionService) handleYearDateOnly(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	year, _ := strconv.Atoi(matches[1])
-	month, _ := strconv.Atoi(matches[2])
-	day, _ := strconv.Atoi(matches[3])
-
-	startsAt := time.Date(year, time.Month(month), day, 0, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 時刻範囲の処理 (例: "14:00-16:00")
-func (s *DateTimeExtractionService) handleTimeRange(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	startHour, _ := strconv.Atoi(matches[1])
-	startMin, _ := strconv.Atoi(matches[2])
-	endHour, _ := strconv.Atoi(matches[3])
-	endMin, _ := strconv.Atoi(matches[4])
-
-	// 投稿日の指定時刻
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		startHour, startMin, 0, 0, postCreatedAt.Location())
-	endsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		endHour, endMin, 0, 0, postCreatedAt.Location())
-
-	return startsAt, &endsAt
-}
-
-// 時刻のみの処理 (例: "14:00")
-func (s *DateTimeExtractionService) handleTimeOnly(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-	min, _ := strconv.Atoi(matches[2])
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 日付のみの処理 (例: "10月3日")
-func (s *DateTimeExtractionService) handleDateOnly(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
-
-	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
-		year++
+	return nil
+}
+
+// ExtractAllDateTimes 投稿内容に含まれる日時をすべて抽出し、出現順に返す
+// 例: "10/5（土）大阪 18:00 / 10/12（土）東京 17:00" は10/5 18:00と10/12 17:00の2件になる
+//   - Extract と同じく正規化してから一致させ、位置は元の投稿内容で返す
+//   - 優先度の高いパターンから順に、既に一致した範囲と重ならない箇所のみ使う
+//   - 存在しない月日の一致（"13/40"）は使わず、その範囲に重なる優先度の低い一致も使わない
+//   - 日付のみの一致は、同じ行で次の日付より前にある時刻のみの一致と組み合わせる
+//   - 日付を含む一致がある場合、日付と組み合わせられなかった時刻・期間は無視する
+//   - 日付を含む一致がない場合は ExtractDateTimeWithMatch と同じく最も優先度の高い1件のみ返す
+//   - 同じ日時になる一致は最初の1件のみ返す
+func (s *DateTimeExtractionService) ExtractAllDateTimes(content string, postCreatedAt time.Time) []DateTimeSpan {
+	type found struct {
+		pattern    dateTimePattern
+		start, end int // 正規化後の内容でのバイト位置
+		matches    []string
 	}
 
-	startsAt := time.Date(year, time.Month(month), day, 0, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 日付+時刻の処理 (例: "10月3日 14:00")
-func (s *DateTimeExtractionService) handleDateTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
-	hour, _ := strconv.Atoi(matches[3])
-	min, _ := strconv.Atoi(matches[4])
-
-	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
-		year++
+	normalized := normalizeDateTimeText(content)
+
+	var all []found
+	var rejected [][2]int
+	for _, pattern := range s.currentPatterns() {
+		for _, loc := range pattern.regex.FindAllStringSubmatchIndex(normalized.text, -1) {
+			overlapped := overlapsRanges(rejected, loc[0], loc[1])
+			for _, f := range all {
+				if loc[0] < f.end && f.start < loc[1] {
+					overlapped = true
+					break
+				}
+			}
+			if overlapped {
+				continue
+			}
+			matches := submatches(normalized.text, loc)
+			if !pattern.valid(matches) {
+				rejected = append(rejected, [2]int{loc[0], loc[1]})
+				continue
+			}
+			all = append(all, found{pattern: pattern, start: loc[0], end: loc[1], matches: matches})
+		}
 	}
-
-	startsAt := time.Date(year, time.Month(month), day, hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 日付+時刻範囲の処理 (例: "10月3日 14:00-16:00")
-func (s *DateTimeExtractionService) handleDateTimeRange(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
-	startHour, _ := strconv.Atoi(matches[3])
-	startMin, _ := strconv.Atoi(matches[4])
-	endHour, _ := strconv.Atoi(matches[5])
-	endMin, _ := strconv.Atoi(matches[6])
-
-	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
-		year++
+	if len(all) == 0 {
+		return nil
 	}
 
-	startsAt := time.Date(year, time.Month(month), day, startHour, startMin, 0, 0, postCreatedAt.Location())
-	endsAt := time.Date(year, time.Month(month), day, endHour, endMin, 0, 0, postCreatedAt.Location())
-
-	return startsAt, &endsAt
-}
-
-// 簡略化のため、他のハンドラー関数は基本的なものを実装
-// 実際の本格運用時には全パターンを実装する必要があります
-
-// スラッシュ形式の基本ハンドラー（簡略版）
-func (s *DateTimeExtractionService) handleSlashDateWeekdayTimeUntil(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
-	hour, _ := strconv.Atoi(matches[3])
-	min, _ := strconv.Atoi(matches[4])
-
-	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
-		year++
+	// 優先度が最も高い一致（日付を含む一致がない場合に使う）
+	first := all[0]
+	sort.SliceStable(all, func(i, j int) bool { return all[i].start < all[j].start })
+
+	var spans []DateTimeSpan
+	for i := 0; i < len(all); i++ {
+		f := all[i]
+		switch f.pattern.kind {
+		case dateTimeKindDateTime:
+			startsAt, endsAt := f.pattern.handler(f.matches, postCreatedAt)
+			span := newDateTimeSpan(startsAt, endsAt, newDateTimeMatch(normalized, f.pattern, f.start, f.end), f.pattern.parts)
+			s.applyPastContext(&span, normalized.text, f.start, f.end, f.pattern.parts, postCreatedAt)
+			spans = append(spans, span)
+		case dateTimeKindDate:
+			startsAt, endsAt := f.pattern.handler(f.matches, postCreatedAt)
+			match := newDateTimeMatch(normalized, f.pattern, f.start, f.end)
+			parts := f.pattern.parts
+			end := f.end
+
+			// 同じ行の直後にある時刻を組み合わせる（時刻のハンドラーをこの日付を基準に呼ぶ）
+			if i+1 < len(all) {
+				next := all[i+1]
+				if next.pattern.kind == dateTimeKindTime && !strings.Contains(normalized.text[f.end:next.start], "\n") {
+					startsAt, endsAt = next.pattern.handler(next.matches, startsAt)
+					match = newDateTimeMatch(normalized, f.pattern, f.start, next.end)
+					match.Name = f.pattern.name + "+" + next.pattern.name
+					match.Pattern = f.pattern.regex.String() + " + " + next.pattern.regex.String()
+					parts |= next.pattern.parts
+					end = next.end
+					i++
+				}
+			}
+			span := newDateTimeSpan(startsAt, endsAt, match, parts)
+			s.applyPastContext(&span, normalized.text, f.start, end, parts, postCreatedAt)
+			spans = append(spans, span)
+		}
 	}
 
-	// "まで"の場合は終了時刻として扱い、開始時刻は投稿時刻とする
-	startsAt := postCreatedAt
-	endsAt := time.Date(year, time.Month(month), day, hour, min, 0, 0, postCreatedAt.Location())
-
-	return startsAt, &endsAt
-}
-
-func (s *DateTimeExtractionService) handleSlashDateWeekdayTimeRange(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleDateTimeRange(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleSlashDateWeekdayTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleDateTime(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleSlashDateWeekdayOnly(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleDateOnly(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleDateWeekdayTimeUntil(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
-	hour, _ := strconv.Atoi(matches[3])
-	min, _ := strconv.Atoi(matches[4])
-
-	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
-		year++
+	if len(spans) == 0 {
+		startsAt, endsAt := first.pattern.handler(first.matches, postCreatedAt)
+		span := newDateTimeSpan(startsAt, endsAt, newDateTimeMatch(normalized, first.pattern, first.start, first.end), first.pattern.parts)
+		s.applyPastContext(&span, normalized.text, first.start, first.end, first.pattern.parts, postCreatedAt)
+		return []DateTimeSpan{span}
 	}
 
-	// "まで"の場合は終了時刻として扱い、開始時刻は投稿時刻とする
-	startsAt := postCreatedAt
-	endsAt := time.Date(year, time.Month(month), day, hour, min, 0, 0, postCreatedAt.Location())
-
-	return startsAt, &endsAt
-}
-
-func (s *DateTimeExtractionService) handleDateWeekdayTimeRange(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleDateTimeRange(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleDateWeekdayTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleDateTime(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleDateWeekdayOnly(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleDateOnly(matches, postCreatedAt)
-}
-
-// 日本語時刻パターンの基本ハンドラー（簡略版）
-func (s *DateTimeExtractionService) handleJapaneseTimeMinuteRange(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleTimeRange(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleJapaneseTimeMinuteFromTo(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleTimeRange(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleJapaneseTimeMinuteFrom(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleTimeOnly(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleJapaneseTimeMinuteStart(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleTimeOnly(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleJapaneseTimeMinute(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	return s.handleTimeOnly(matches, postCreatedAt)
-}
-
-func (s *DateTimeExtractionService) handleJapaneseTimeRange(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	startHour, _ := strconv.Atoi(matches[1])
-	endHour, _ := strconv.Atoi(matches[2])
-
-	// 投稿日の指定時刻（分は0とする）
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		startHour, 0, 0, 0, postCreatedAt.Location())
-	endsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		endHour, 0, 0, 0, postCreatedAt.Location())
-
-	return startsAt, &endsAt
-}
-
-func (s *DateTimeExtractionService) handleJapaneseTimeFromTo(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	startHour, _ := strconv.Atoi(matches[1])
-	endHour, _ := strconv.Atoi(matches[2])
-
-	// 投稿日の指定時刻（分は0とする）
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		startHour, 0, 0, 0, postCreatedAt.Location())
-	endsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		endHour, 0, 0, 0, postCreatedAt.Location())
-
-	return startsAt, &endsAt
-}
-
-func (s *DateTimeExtractionService) handleJapaneseTimeFrom(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour) // 1時間後を終了時刻とする
-
-	return startsAt, &endsAt
-}
-
-func (s *DateTimeExtractionService) handleJapaneseTimeStart(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour) // 1時間後を終了時刻とする
-
-	return startsAt, &endsAt
-}
-
-// スラッシュ日付+時刻まで の処理 (例: "10/6 18:00まで")
-func (s *DateTimeExtractionService) handleSlashDateTimeUntil(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
-	hour, _ := strconv.Atoi(matches[3])
-	min, _ := strconv.Atoi(matches[4])
-
-	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
-		year++
+	// 同じ日時の重複を除く
+	unique := spans[:0]
+	for _, span := range spans {
+		duplicated := false
+		for _, existing := range unique {
+			if existing.StartsAt.Equal(span.StartsAt) && equalTimePtr(existing.EndsAt, span.EndsAt) {
+				duplicated = true
+				break
+			}
+		}
+		if !duplicated {
+			unique = append(unique, span)
+		}
 	}
 
-	// "まで"の場合は終了時刻として扱い、開始時刻は投稿時刻とする
-	startsAt := postCreatedAt
-	endsAt := time.Date(year, time.Month(month), day, hour, min, 0, 0, postCreatedAt.Location())
-
-	return startsAt, &endsAt
+	log.Printf("DateTime extraction - %d datetime spans found", len(unique))
+	return unique
 }
 
-// スラッシュ日付+時刻範囲 の処理 (例: "10/6 18:00-20:00")
-func (s *DateTimeExtractionService) handleSlashDateTimeRange(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
-	startHour, _ := strconv.Atoi(matches[3])
-	startMin, _ := strconv.Atoi(matches[4])
-	endHour, _ := strconv.Atoi(matches[5])
-	endMin, _ := strconv.Atoi(matches[6])
-
-	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
-		year++
+// 一致した箇所の情報（正規化後の位置を元の投稿内容の位置に戻し、文字数に変換する）
+func newDateTimeMatch(normalized *normalizedText, pattern dateTimePattern, start, end int) DateTimeMatch {
+	start, end = normalized.originalRange(start, end)
+	content := normalized.original
+	return DateTimeMatch{
+		Name:    pattern.name,
+		Pattern: pattern.regex.String(),
+		Text:    content[start:end],
+		Start:   utf8.RuneCountInString(content[:start]),
+		End:     utf8.RuneCountInString(content[:end]),
 	}
-
-	startsAt := time.Date(year, time.Month(month), day, startHour, startMin, 0, 0, postCreatedAt.Location())
-	endsAt := time.Date(year, time.Month(month), day, endHour, endMin, 0, 0, postCreatedAt.Location())
-
-	return startsAt, &endsAt
 }
 
-// スラッシュ日付+時刻 の処理 (例: "10/6 18:00")
-func (s *DateTimeExtractionService) handleSlashDateTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
-	hour, _ := strconv.Atoi(matches[3])
-	min, _ := strconv.Atoi(matches[4])
-
-	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
-		year++
+// [start, end) がいずれかの範囲と重なるか
+func overlapsRanges(ranges [][2]int, start, end int) bool {
+	for _, r := range ranges {
+		if start < r[1] && r[0] < end {
+			return true
+		}
 	}
-
-	startsAt := time.Date(year, time.Month(month), day, hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
+	return false
 }
 
-// スラッシュ日付のみ の処理 (例: "10/6")
-func (s *DateTimeExtractionService) handleSlashDateOnly(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
+// 年の記載がない月日の年を決める
+// 投稿日の pastDateToleranceDays 日前から1年以内になる年を選ぶ（それより前なら翌年、1年以上先なら前年）
+// 2/29がその年に存在しない場合は、投稿日に最も近い閏年を選ぶ
+func (s *DateTimeExtractionService) inferYear(month, day int, postCreatedAt time.Time) int {
+	postDate := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(), 0, 0, 0, 0, postCreatedAt.Location())
+	candidate := time.Date(postCreatedAt.Year(), time.Month(month), day, 0, 0, 0, 0, postCreatedAt.Location())
 
 	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
+	if candidate.Before(postDate.AddDate(0, 0, -pastDateToleranceDays)) {
 		year++
+	} else if !candidate.Before(postDate.AddDate(1, 0, -pastDateToleranceDays)) {
+		year--
 	}
 
-	startsAt := time.Date(year, time.Month(month), day, 0, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// === 相対日付表現ハンドラー ===
-
-// 明日の時刻処理 (例: "明日 14:00")
-func (s *DateTimeExtractionService) handleTomorrowTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-	min, _ := strconv.Atoi(matches[2])
-
-	tomorrow := postCreatedAt.AddDate(0, 0, 1)
-	startsAt := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(),
-		hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 今日の時刻処理 (例: "今日 14:00")
-func (s *DateTimeExtractionService) handleTodayTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-	min, _ := strconv.Atoi(matches[2])
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
+	if month == 2 && day == 29 && !isLeapYear(year) {
+		prev, next := year-1, year+1
+		for !isLeapYear(prev) {
+			prev--
+		}
+		for !isLeapYear(next) {
+			next++
+		}
+		leapDay := func(y int) time.Time { return time.Date(y, time.February, 29, 0, 0, 0, 0, postCreatedAt.Location()) }
+		if postDate.Sub(leapDay(prev)) < leapDay(next).Sub(postDate) {
+			return prev
+		}
+		return next
+	}
+	return year
 }
 
-// 明後日の時刻処理 (例: "明後日 14:00")
-func (s *DateTimeExtractionService) handleDayAfterTomorrowTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-	min, _ := strconv.Atoi(matches[2])
-
-	dayAfterTomorrow := postCreatedAt.AddDate(0, 0, 2)
-	startsAt := time.Date(dayAfterTomorrow.Year(), dayAfterTomorrow.Month(), dayAfterTomorrow.Day(),
-		hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
 }
 
-// === 時間帯表現ハンドラー ===
-
-// 午前時刻処理 (例: "午前10時")
-func (s *DateTimeExtractionService) handleAMTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-	if hour == 12 {
-		hour = 0 // 午前12時は0時
+// 一致した箇所と同じ文（。！？や改行で区切る）に"先日"などの過去を示す表現がある場合、
+// 年の記載がない日付を投稿日以前の直近の年に置き換える
+func (s *DateTimeExtractionService) applyPastContext(span *DateTimeSpan, text string, start, end int, parts dateTimeParts, postCreatedAt time.Time) {
+	if parts&partDate == 0 || parts&(partYear|partUntil) != 0 {
+		return
 	}
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 午後時刻処理 (例: "午後3時")
-func (s *DateTimeExtractionService) handlePMTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-	if hour != 12 {
-		hour += 12 // 午後は12時間追加（12時は除く）
+	if !pastDateContextRegex.MatchString(sentenceAround(text, start, end)) {
+		return
 	}
 
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 夜時刻処理 (例: "夜8時")
-func (s *DateTimeExtractionService) handleNightTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-	if hour < 12 {
-		hour += 12 // 夜は午後として扱う
+	postDate := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(), 0, 0, 0, 0, postCreatedAt.Location())
+	// 2/29は閏年にのみ移す（AddDateでは3/1に繰り越されるため）
+	years := 0
+	for {
+		shifted := span.StartsAt.AddDate(years, 0, 0)
+		if shifted.Day() == span.StartsAt.Day() && shifted.Before(postDate.AddDate(0, 0, 1)) {
+			break
+		}
+		years--
 	}
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 朝時刻処理 (例: "朝9時")
-func (s *DateTimeExtractionService) handleMorningTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 昼時刻処理 (例: "昼12時")
-func (s *DateTimeExtractionService) handleNoonTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// === 英語混在表現ハンドラー ===
-
-// AM時刻処理 (例: "AM 9:00")
-func (s *DateTimeExtractionService) handleAMTimeEng(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-	min, _ := strconv.Atoi(matches[2])
-	if hour == 12 {
-		hour = 0 // AM 12:00は0:00
+	if years != 0 {
+		span.StartsAt = span.StartsAt.AddDate(years, 0, 0)
+		if span.EndsAt != nil {
+			endsAt := span.EndsAt.AddDate(years, 0, 0)
+			span.EndsAt = &endsAt
+		}
 	}
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
+	span.Assumptions = append(span.Assumptions, DateTimeAssumptionPastContext)
 }
 
-// PM時刻処理 (例: "PM 6:00")
-func (s *DateTimeExtractionService) handlePMTimeEng(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hour, _ := strconv.Atoi(matches[1])
-	min, _ := strconv.Atoi(matches[2])
-	if hour != 12 {
-		hour += 12 // PM時は12時間追加（12時は除く）
+// 一致した箇所を含む文を返す
+func sentenceAround(text string, start, end int) string {
+	const delimiters = "。！？!?\n"
+	from := 0
+	if i := strings.LastIndexAny(text[:start], delimiters); i >= 0 {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		from = i + size
 	}
-
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// === 区切り文字バリエーションハンドラー ===
-
-// 代替日付形式処理 (例: "10-6 18:00", "10.6 18:00")
-func (s *DateTimeExtractionService) handleAlternativeDateFormat(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	month, _ := strconv.Atoi(matches[1])
-	day, _ := strconv.Atoi(matches[2])
-	hour, _ := strconv.Atoi(matches[3])
-	min, _ := strconv.Atoi(matches[4])
-
-	year := postCreatedAt.Year()
-	if month < int(postCreatedAt.Month()) {
-		year++
+	to := strings.IndexAny(text[end:], delimiters)
+	if to < 0 {
+		return text[from:]
 	}
-
-	startsAt := time.Date(year, time.Month(month), day, hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 完全スラッシュ日付処理 (例: "2025/10/6 18:00")
-func (s *DateTimeExtractionService) handleFullSlashDate(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	year, _ := strconv.Atoi(matches[1])
-	month, _ := strconv.Atoi(matches[2])
-	day, _ := strconv.Atoi(matches[3])
-	hour, _ := strconv.Atoi(matches[4])
-	min, _ := strconv.Atoi(matches[5])
-
-	startsAt := time.Date(year, time.Month(month), day, hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// === 曖昧な時間表現ハンドラー ===
-
-// 夕方処理 (例: "夕方")
-func (s *DateTimeExtractionService) handleEvening(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		18, 0, 0, 0, postCreatedAt.Location()) // 18:00を夕方とする
-	endsAt := startsAt.Add(2 * time.Hour) // 2時間の幅
-
-	return startsAt, &endsAt
-}
-
-// お昼頃処理 (例: "お昼頃")
-func (s *DateTimeExtractionService) handleAroundNoon(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		12, 0, 0, 0, postCreatedAt.Location()) // 12:00をお昼とする
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 夜中・深夜処理 (例: "夜中", "深夜")
-func (s *DateTimeExtractionService) handleMidnight(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		0, 0, 0, 0, postCreatedAt.Location()) // 0:00を夜中とする
-	endsAt := startsAt.Add(2 * time.Hour) // 2時間の幅
-
-	return startsAt, &endsAt
-}
-
-// 早朝処理 (例: "早朝")
-func (s *DateTimeExtractionService) handleEarlyMorning(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	startsAt := time.Date(postCreatedAt.Year(), postCreatedAt.Month(), postCreatedAt.Day(),
-		6, 0, 0, 0, postCreatedAt.Location()) // 6:00を早朝とする
-	endsAt := startsAt.Add(2 * time.Hour) // 2時間の幅
-
-	return startsAt, &endsAt
-}
-
-// === 自然な日本語表現ハンドラー ===
-
-// 今週の曜日+時刻処理 (例: "今週の土曜日 14:00")
-func (s *DateTimeExtractionService) handleThisWeekdayTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	weekdayStr := matches[1]
-	hour, _ := strconv.Atoi(matches[2])
-	min, _ := strconv.Atoi(matches[3])
-
-	targetWeekday := s.parseWeekday(weekdayStr)
-	daysUntil := s.daysUntilWeekday(postCreatedAt.Weekday(), targetWeekday)
-
-	// 今週の場合、過去の曜日も含める（例：金曜日に「今週の月曜日」と言った場合、今週の月曜日を指す）
-	if daysUntil > 0 && targetWeekday < postCreatedAt.Weekday() {
-		// 対象曜日が今日より前の曜日の場合、今週のその曜日（過去）を指す
-		daysUntil -= 7
+	return text[from : end+to]
+}
+
+// 抽出した日時に信頼度と推測した内容を付ける
+func newDateTimeSpan(startsAt time.Time, endsAt *time.Time, match DateTimeMatch, parts dateTimeParts) DateTimeSpan {
+	confidence, assumptions := evaluateDateTimeParts(parts)
+	return DateTimeSpan{
+		StartsAt:    startsAt,
+		EndsAt:      endsAt,
+		Match:       match,
+		Confidence:  confidence,
+		Assumptions: assumptions,
 	}
-
-	targetDate := postCreatedAt.AddDate(0, 0, daysUntil)
-	startsAt := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
-		hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
 }
 
-// 今週の曜日処理 (例: "今週の土曜日")
-func (s *DateTimeExtractionService) handleThisWeekday(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	weekdayStr := matches[1]
-
-	targetWeekday := s.parseWeekday(weekdayStr)
-	daysUntil := s.daysUntilWeekday(postCreatedAt.Weekday(), targetWeekday)
-
-	// 今週の場合、過去の曜日も含める
-	if daysUntil > 0 && targetWeekday < postCreatedAt.Weekday() {
-		// 対象曜日が今日より前の曜日の場合、今週のその曜日（過去）を指す
-		daysUntil -= 7
+// 明示されている情報から信頼度（0〜1）と推測した内容を求める
+// 日付0.4（相対日付は0.3）、年0.1（相対日付は投稿日から確定するため0.05）、
+// 時刻0.35（おおよその時間帯は0.15）、終了0.15の合計。開始の記載がない場合は開始日時の分を加えない
+func evaluateDateTimeParts(parts dateTimeParts) (float64, []string) {
+	score := 0.0
+	assumptions := make([]string, 0)
+
+	if parts&partUntil != 0 {
+		// 終了のみ記載されている場合、開始は投稿日時
+		if parts&partDate != 0 {
+			score += 0.4
+			if parts&partYear != 0 {
+				score += 0.1
+			} else {
+				assumptions = append(assumptions, DateTimeAssumptionYearInferred)
+			}
+		}
+		score += 0.15
+		assumptions = append(assumptions, DateTimeAssumptionStartInferred)
+		return math.Round(score*100) / 100, assumptions
 	}
 
-	targetDate := postCreatedAt.AddDate(0, 0, daysUntil)
-	startsAt := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
-		0, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 今度の曜日+時刻処理 (例: "今度の土曜日 14:00")
-func (s *DateTimeExtractionService) handleNextWeekdayTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	weekdayStr := matches[1]
-	hour, _ := strconv.Atoi(matches[2])
-	min, _ := strconv.Atoi(matches[3])
-
-	targetWeekday := s.parseWeekday(weekdayStr)
-	daysUntil := s.daysUntilWeekday(postCreatedAt.Weekday(), targetWeekday)
-	if daysUntil == 0 {
-		daysUntil = 7 // 今日が同じ曜日なら来週
+	switch {
+	case parts&partDate != 0:
+		score += 0.4
+		if parts&partYear != 0 {
+			score += 0.1
+		} else {
+			assumptions = append(assumptions, DateTimeAssumptionYearInferred)
+		}
+	case parts&partRelativeDate != 0:
+		score += 0.35
+		assumptions = append(assumptions, DateTimeAssumptionRelativeDate)
+	default:
+		assumptions = append(assumptions, DateTimeAssumptionDateInferred)
 	}
 
-	targetDate := postCreatedAt.AddDate(0, 0, daysUntil)
-	startsAt := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
-		hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 来週曜日+時刻処理 (例: "来週月曜日 10:00")
-func (s *DateTimeExtractionService) handleNextWeekWeekdayTime(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	weekdayStr := matches[1]
-	hour, _ := strconv.Atoi(matches[2])
-	min, _ := strconv.Atoi(matches[3])
-
-	targetWeekday := s.parseWeekday(weekdayStr)
-	daysUntil := s.daysUntilWeekday(postCreatedAt.Weekday(), targetWeekday) + 7 // 来週なので+7日
-
-	targetDate := postCreatedAt.AddDate(0, 0, daysUntil)
-	startsAt := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
-		hour, min, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 今度の曜日処理 (例: "今度の土曜日")
-func (s *DateTimeExtractionService) handleNextWeekday(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	weekdayStr := matches[1]
-
-	targetWeekday := s.parseWeekday(weekdayStr)
-	daysUntil := s.daysUntilWeekday(postCreatedAt.Weekday(), targetWeekday)
-	if daysUntil == 0 {
-		daysUntil = 7 // 今日が同じ曜日なら来週
+	switch {
+	case parts&partTime != 0:
+		score += 0.35
+	case parts&partApproximateTime != 0:
+		score += 0.15
+		assumptions = append(assumptions, DateTimeAssumptionTimeApproximate)
+	default:
+		assumptions = append(assumptions, DateTimeAssumptionTimeInferred)
 	}
 
-	targetDate := postCreatedAt.AddDate(0, 0, daysUntil)
-	startsAt := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
-		0, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
-
-	return startsAt, &endsAt
-}
-
-// 来週曜日処理 (例: "来週月曜日")
-func (s *DateTimeExtractionService) handleNextWeekWeekday(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	weekdayStr := matches[1]
-
-	targetWeekday := s.parseWeekday(weekdayStr)
-	daysUntil := s.daysUntilWeekday(postCreatedAt.Weekday(), targetWeekday) + 7 // 来週なので+7日
-
-	targetDate := postCreatedAt.AddDate(0, 0, daysUntil)
-	startsAt := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(),
-		0, 0, 0, 0, postCreatedAt.Location())
-	endsAt := startsAt.Add(1 * time.Hour)
+	if parts&partEnd != 0 {
+		score += 0.15
+	} else {
+		assumptions = append(assumptions, DateTimeAssumptionDurationInferred)
+	}
 
-	return startsAt, &endsAt
+	return math.Round(score*100) / 100, assumptions
 }
 
-// === 期間表現ハンドラー ===
-
-// 時間期間処理 (例: "3時間")
-func (s *DateTimeExtractionService) handleHourDuration(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	hours, _ := strconv.Atoi(matches[1])
-
-	startsAt := postCreatedAt
-	endsAt := startsAt.Add(time.Duration(hours) * time.Hour)
-
-	return startsAt, &endsAt
+// FindStringSubmatchIndexの結果からサブマッチの文字列を取り出す
+func submatches(content string, loc []int) []string {
+	matches := make([]string, len(loc)/2)
+	for i := range matches {
+		if loc[2*i] >= 0 {
+			matches[i] = content[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return matches
 }
 
-// 分期間処理 (例: "30分間")
-func (s *DateTimeExtractionService) handleMinuteDuration(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
-	minutes, _ := strconv.Atoi(matches[1])
-
-	startsAt := postCreatedAt
-	endsAt := startsAt.Add(time.Duration(minutes) * time.Minute)
-
-	return startsAt, &endsAt
+func equalTimePtr(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
 }
 
-// === ヘルパー関数 ===
-
 // 曜日文字列を time.Weekday に変換
 func (s *DateTimeExtractionService) parseWeekday(weekdayStr string) time.Weekday {
 	weekdayMap := map[string]time.Weekday{
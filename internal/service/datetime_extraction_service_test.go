@@ -1,6 +1,7 @@
 package service
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -1033,3 +1034,541 @@ func TestDateTimeExtractionService_PatternPriority(t *testing.T) {
 		})
 	}
 }
+
+func TestDateTimeExtractionService_ExtractAllDateTimes(t *testing.T) {
+	service := NewDateTimeExtractionService()
+	baseTime := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	type expectedSpan struct {
+		start time.Time
+		name  string
+		text  string
+		from  int
+		to    int
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []expectedSpan
+	}{
+		{
+			name:    "ツアー告知（日付と時刻の間に会場名）",
+			content: "ツアー決定！10/5（土）大阪 18:00 / 10/12（土）東京 17:00",
+			expected: []expectedSpan{
+				{time.Date(2025, 10, 5, 18, 0, 0, 0, time.UTC), "slash_date_weekday+time", "10/5（土）大阪 18:00", 6, 21},
+				{time.Date(2025, 10, 12, 17, 0, 0, 0, time.UTC), "slash_date_weekday+time", "10/12（土）東京 17:00", 24, 40},
+			},
+		},
+		{
+			name:    "日付と時刻がそろった複数行",
+			content: "2026年1月10日 14:00-16:00 昼公演\n2026年1月10日 18:00 夜公演",
+			expected: []expectedSpan{
+				{time.Date(2026, 1, 10, 14, 0, 0, 0, time.UTC), "year_date_time_range", "2026年1月10日 14:00-16:00", 0, 22},
+				{time.Date(2026, 1, 10, 18, 0, 0, 0, time.UTC), "year_date_time", "2026年1月10日 18:00", 27, 43},
+			},
+		},
+		{
+			name:    "別の行の時刻は組み合わせない",
+			content: "10月20日 発売\n開店は10:00",
+			expected: []expectedSpan{
+				{time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC), "date", "10月20日", 0, 6},
+			},
+		},
+		{
+			name:    "同じ日時は1件にまとめる",
+			content: "10/6（月）18:00開演！ 10/6（月）18:00をお見逃しなく",
+			expected: []expectedSpan{
+				{time.Date(2025, 10, 6, 18, 0, 0, 0, time.UTC), "slash_date_weekday_time", "10/6（月）18:00", 0, 12},
+			},
+		},
+		{
+			name:    "日付がない場合は最も優先度の高い1件",
+			content: "今日は夕方から配信、19:00-21:00です",
+			expected: []expectedSpan{
+				{time.Date(2025, 10, 3, 19, 0, 0, 0, time.UTC), "time_range", "19:00-21:00", 10, 21},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := service.ExtractAllDateTimes(tt.content, baseTime)
+			if len(spans) != len(tt.expected) {
+				t.Fatalf("件数が一致しません: 期待値 %d, 実際 %d (%+v)", len(tt.expected), len(spans), spans)
+			}
+			for i, want := range tt.expected {
+				got := spans[i]
+				if !got.StartsAt.Equal(want.start) {
+					t.Errorf("%d件目の開始時刻: 期待値 %s, 実際 %s", i+1, want.start, got.StartsAt)
+				}
+				if got.Match.Name != want.name || got.Match.Text != want.text || got.Match.Start != want.from || got.Match.End != want.to {
+					t.Errorf("%d件目の一致箇所: 期待値 {%s %q %d %d}, 実際 {%s %q %d %d}",
+						i+1, want.name, want.text, want.from, want.to,
+						got.Match.Name, got.Match.Text, got.Match.Start, got.Match.End)
+				}
+			}
+		})
+	}
+
+	if spans := service.ExtractAllDateTimes("日時の記載なし", baseTime); spans != nil {
+		t.Errorf("日時がない場合はnilになるべきです: %+v", spans)
+	}
+}
+
+func TestDateTimeExtractionService_Extract_Confidence(t *testing.T) {
+	service := NewDateTimeExtractionService()
+	baseTime := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		content     string
+		pattern     string
+		confidence  float64
+		assumptions []string
+	}{
+		{"年月日と時刻範囲がすべて明示", "2026年1月10日 14:00-16:00", "year_date_time_range", 1.0, []string{}},
+		{"年の記載なし", "10月3日 14:00", "date_time", 0.75,
+			[]string{DateTimeAssumptionYearInferred, DateTimeAssumptionDurationInferred}},
+		{"相対日付", "明日 14:00", "tomorrow_time", 0.7,
+			[]string{DateTimeAssumptionRelativeDate, DateTimeAssumptionDurationInferred}},
+		{"日付のみ", "10月20日", "date", 0.4,
+			[]string{DateTimeAssumptionYearInferred, DateTimeAssumptionTimeInferred, DateTimeAssumptionDurationInferred}},
+		{"時刻のみ", "19:00-21:00", "time_range", 0.5,
+			[]string{DateTimeAssumptionDateInferred}},
+		{"おおよその時間帯", "夕方", "evening", 0.15,
+			[]string{DateTimeAssumptionDateInferred, DateTimeAssumptionTimeApproximate, DateTimeAssumptionDurationInferred}},
+		{"終了のみ", "10/6 18:00まで", "slash_date_time_until", 0.55,
+			[]string{DateTimeAssumptionYearInferred, DateTimeAssumptionStartInferred}},
+		{"期間のみ", "3時間", "hour_duration", 0.15,
+			[]string{DateTimeAssumptionStartInferred}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := service.Extract(tt.content, baseTime)
+			if span == nil {
+				t.Fatalf("日時が抽出されるべきです: %q", tt.content)
+			}
+			if span.Match.Name != tt.pattern {
+				t.Errorf("パターン: 期待値 %s, 実際 %s", tt.pattern, span.Match.Name)
+			}
+			if span.Confidence != tt.confidence {
+				t.Errorf("信頼度: 期待値 %v, 実際 %v", tt.confidence, span.Confidence)
+			}
+			if !reflect.DeepEqual(span.Assumptions, tt.assumptions) {
+				t.Errorf("推測した内容: 期待値 %v, 実際 %v", tt.assumptions, span.Assumptions)
+			}
+		})
+	}
+
+	// 日付と時刻を組み合わせた場合は両方のパターンの情報を使う
+	spans := service.ExtractAllDateTimes("10/5（土）大阪 18:00", baseTime)
+	if len(spans) != 1 || spans[0].Confidence != 0.75 {
+		t.Errorf("日付と時刻を組み合わせた信頼度は0.75になるべきです: %+v", spans)
+	}
+
+	if span := service.Extract("日時の記載なし", baseTime); span != nil {
+		t.Errorf("日時がない場合はnilになるべきです: %+v", span)
+	}
+}
+
+// 全角数字・漢数字・和暦・"半"の表記のテスト
+func TestDateTimeExtractionService_ExtractAllDateTimes_NormalizedForms(t *testing.T) {
+	service := NewDateTimeExtractionService()
+	baseTime := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		content       string
+		expectedStart time.Time
+		expectedEnd   *time.Time
+		expectedText  string // 元の投稿内容のまま返ること
+		expectedFrom  int
+		expectedTo    int
+	}{
+		{
+			name:          "全角数字",
+			content:       "１０月５日 １８：００開演",
+			expectedStart: time.Date(2025, 10, 5, 18, 0, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 5, 19, 0, 0, 0, time.UTC)),
+			expectedText:  "１０月５日 １８：００",
+			expectedFrom:  0,
+			expectedTo:    11,
+		},
+		{
+			name:          "全角数字・記号と曜日",
+			content:       "【告知】１０／６（月）１８：００－２０：００",
+			expectedStart: time.Date(2025, 10, 6, 18, 0, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 6, 20, 0, 0, 0, time.UTC)),
+			expectedText:  "１０／６（月）１８：００－２０：００",
+			expectedFrom:  4,
+			expectedTo:    22,
+		},
+		{
+			name:          "漢数字の日付と時刻",
+			content:       "十月五日 十八時三十分から",
+			expectedStart: time.Date(2025, 10, 5, 18, 30, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 5, 19, 30, 0, 0, time.UTC)),
+			expectedText:  "十月五日 十八時三十分から",
+			expectedFrom:  0,
+			expectedTo:    13,
+		},
+		{
+			name:          "和暦",
+			content:       "令和8年1月10日 14:00-16:00",
+			expectedStart: time.Date(2026, 1, 10, 14, 0, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2026, 1, 10, 16, 0, 0, 0, time.UTC)),
+			expectedText:  "令和8年1月10日 14:00-16:00",
+			expectedFrom:  0,
+			expectedTo:    21,
+		},
+		{
+			name:          "和暦と漢数字",
+			content:       "発売日は令和八年一月十日",
+			expectedStart: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2026, 1, 10, 1, 0, 0, 0, time.UTC)),
+			expectedText:  "令和八年一月十日",
+			expectedFrom:  4,
+			expectedTo:    12,
+		},
+		{
+			name:          "平成の年",
+			content:       "平成三十一年四月三十日",
+			expectedStart: time.Date(2019, 4, 30, 0, 0, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2019, 4, 30, 1, 0, 0, 0, time.UTC)),
+			expectedText:  "平成三十一年四月三十日",
+			expectedFrom:  0,
+			expectedTo:    11,
+		},
+		{
+			name:          "時半",
+			content:       "10月5日 18時半開演",
+			expectedStart: time.Date(2025, 10, 5, 18, 30, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 5, 19, 30, 0, 0, time.UTC)),
+			expectedText:  "10月5日 18時半",
+			expectedFrom:  0,
+			expectedTo:    10,
+		},
+		{
+			name:          "時半の範囲",
+			content:       "配信は１８時半〜２０時半",
+			expectedStart: time.Date(2025, 10, 3, 18, 30, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 3, 20, 30, 0, 0, time.UTC)),
+			expectedText:  "１８時半〜２０時半",
+			expectedFrom:  3,
+			expectedTo:    12,
+		},
+		{
+			name:          "午後の時半",
+			content:       "午後6時半から",
+			expectedStart: time.Date(2025, 10, 3, 18, 30, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 3, 19, 30, 0, 0, time.UTC)),
+			expectedText:  "午後6時半",
+			expectedFrom:  0,
+			expectedTo:    5,
+		},
+		{
+			name:          "夜の時半",
+			content:       "今夜は夜7時半から配信",
+			expectedStart: time.Date(2025, 10, 3, 19, 30, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 3, 20, 30, 0, 0, time.UTC)),
+			expectedText:  "夜7時半",
+			expectedFrom:  3,
+			expectedTo:    7,
+		},
+		{
+			name:          "午後の時分",
+			content:       "午後6時30分開演",
+			expectedStart: time.Date(2025, 10, 3, 18, 30, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 3, 19, 30, 0, 0, time.UTC)),
+			expectedText:  "午後6時30分",
+			expectedFrom:  0,
+			expectedTo:    7,
+		},
+		{
+			name:          "午前の時分（漢数字）",
+			content:       "午前十時十五分集合",
+			expectedStart: time.Date(2025, 10, 3, 10, 15, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 3, 11, 15, 0, 0, time.UTC)),
+			expectedText:  "午前十時十五分",
+			expectedFrom:  0,
+			expectedTo:    7,
+		},
+		{
+			name:          "朝の時半",
+			content:       "朝9時半集合",
+			expectedStart: time.Date(2025, 10, 3, 9, 30, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 3, 10, 30, 0, 0, time.UTC)),
+			expectedText:  "朝9時半",
+			expectedFrom:  0,
+			expectedTo:    4,
+		},
+		{
+			name:          "日付と午後の時半",
+			content:       "10/5 午後6時半開演",
+			expectedStart: time.Date(2025, 10, 5, 18, 30, 0, 0, time.UTC),
+			expectedEnd:   timePtr(time.Date(2025, 10, 5, 19, 30, 0, 0, time.UTC)),
+			expectedText:  "10/5 午後6時半",
+			expectedFrom:  0,
+			expectedTo:    10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := service.ExtractAllDateTimes(tt.content, baseTime)
+			if len(spans) == 0 {
+				t.Fatalf("日時が抽出されるべきです: %q", tt.content)
+			}
+			got := spans[0]
+			if !got.StartsAt.Equal(tt.expectedStart) {
+				t.Errorf("開始時刻が一致しません\n期待値: %s\n実際値: %s",
+					tt.expectedStart.Format("2006-01-02 15:04:05"), got.StartsAt.Format("2006-01-02 15:04:05"))
+			}
+			if !equalTimePtr(got.EndsAt, tt.expectedEnd) {
+				t.Errorf("終了時刻が一致しません\n期待値: %v\n実際値: %v", tt.expectedEnd, got.EndsAt)
+			}
+			if got.Match.Text != tt.expectedText || got.Match.Start != tt.expectedFrom || got.Match.End != tt.expectedTo {
+				t.Errorf("一致箇所: 期待値 {%q %d %d}, 実際 {%q %d %d}",
+					tt.expectedText, tt.expectedFrom, tt.expectedTo,
+					got.Match.Text, got.Match.Start, got.Match.End)
+			}
+		})
+	}
+
+	// "十分（じゅうぶん）"は10分として扱わない
+	if spans := service.ExtractAllDateTimes("十分間に合います", baseTime); spans != nil {
+		t.Errorf("日時として扱わないべきです: %+v", spans)
+	}
+}
+
+func TestParseKanjiNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		ok       bool
+	}{
+		{"五", 5, true},
+		{"十", 10, true},
+		{"十八", 18, true},
+		{"三十", 30, true},
+		{"三十一", 31, true},
+		{"二〇二六", 2026, true},
+		{"十十", 0, false},
+		{"百十", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseKanjiNumber(tt.input)
+		if ok != tt.ok || got != tt.expected {
+			t.Errorf("parseKanjiNumber(%q) = %d, %v; 期待値 %d, %v", tt.input, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+// 年の記載がない日付の年の推測（年末年始の境界）
+// 年の付いたスラッシュ区切りの日付は、月日のみのパターンより優先して年を使うことのテスト
+func TestDateTimeExtractionService_FullSlashDate(t *testing.T) {
+	service := NewDateTimeExtractionService()
+	postCreatedAt := time.Date(2026, 12, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		content       string
+		expectedStart time.Time
+		expectedText  string
+		expectedName  string
+	}{
+		{"2026/1/10 14:00 開演", time.Date(2026, 1, 10, 14, 0, 0, 0, time.UTC), "2026/1/10 14:00", "full_slash_date_time"},
+		{"2026/1/10 14:00-16:00", time.Date(2026, 1, 10, 14, 0, 0, 0, time.UTC), "2026/1/10 14:00", "full_slash_date_time"},
+		{"2026/1/10発売", time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), "2026/1/10", "full_slash_date"},
+		{"2027/1/10（日）開催", time.Date(2027, 1, 10, 0, 0, 0, 0, time.UTC), "2027/1/10", "full_slash_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			for _, span := range []*DateTimeSpan{service.Extract(tt.content, postCreatedAt), firstSpan(service.ExtractAllDateTimes(tt.content, postCreatedAt))} {
+				if span == nil {
+					t.Fatalf("日時が抽出されるべきです: %q", tt.content)
+				}
+				if !span.StartsAt.Equal(tt.expectedStart) || span.Match.Text != tt.expectedText || span.Match.Name != tt.expectedName {
+					t.Errorf("期待値 {%s %q %s}, 実際 {%s %q %s}",
+						tt.expectedStart.Format("2006-01-02 15:04:05"), tt.expectedText, tt.expectedName,
+						span.StartsAt.Format("2006-01-02 15:04:05"), span.Match.Text, span.Match.Name)
+				}
+				for _, assumption := range span.Assumptions {
+					if assumption == DateTimeAssumptionYearInferred {
+						t.Errorf("年の記載がある場合はyear_inferredを含めないべきです: %v", span.Assumptions)
+					}
+				}
+			}
+		})
+	}
+}
+
+func firstSpan(spans []DateTimeSpan) *DateTimeSpan {
+	if len(spans) == 0 {
+		return nil
+	}
+	return &spans[0]
+}
+
+// 存在しない月日は日時として扱わないことのテスト
+func TestDateTimeExtractionService_InvalidDates(t *testing.T) {
+	service := NewDateTimeExtractionService()
+	baseTime := time.Date(2025, 10, 3, 12, 0, 0, 0, time.UTC)
+
+	// 存在しない月日とそれに続く時刻は使わない
+	for _, content := range []string{
+		"13/40 18:00",
+		"2月30日 19:00",
+		"2025年2月29日 19:00",
+		"0/10（土）",
+	} {
+		if span := service.Extract(content, baseTime); span != nil {
+			t.Errorf("Extract(%q): 日時を抽出するべきではありません: %+v", content, span)
+		}
+		if spans := service.ExtractAllDateTimes(content, baseTime); len(spans) != 0 {
+			t.Errorf("ExtractAllDateTimes(%q): 日時を抽出するべきではありません: %+v", content, spans)
+		}
+	}
+
+	// 存在しない日付の後にある日付は使う
+	content := "4/31は休演、5/1 18:00開演"
+	expectedStart := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	if span := service.Extract(content, baseTime); span == nil || !span.StartsAt.Equal(expectedStart) || span.Match.Text != "5/1 18:00" {
+		t.Errorf("Extract(%q): 期待値 %s, 実際 %+v", content, expectedStart.Format("2006-01-02 15:04:05"), span)
+	}
+	if spans := service.ExtractAllDateTimes(content, baseTime); len(spans) != 1 || !spans[0].StartsAt.Equal(expectedStart) {
+		t.Errorf("ExtractAllDateTimes(%q): 期待値 %s, 実際 %+v", content, expectedStart.Format("2006-01-02 15:04:05"), spans)
+	}
+}
+
+func TestDateTimeExtractionService_YearInference(t *testing.T) {
+	service := NewDateTimeExtractionService()
+
+	tests := []struct {
+		name          string
+		postCreatedAt time.Time
+		content       string
+		expectedStart time.Time
+		pastContext   bool
+	}{
+		{
+			name:          "12月の投稿の1月の日付は翌年",
+			postCreatedAt: time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC),
+			content:       "1/10 18:00 開演",
+			expectedStart: time.Date(2026, 1, 10, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "大晦日の投稿の元日は翌年",
+			postCreatedAt: time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC),
+			content:       "1月1日 0:00 カウントダウン配信",
+			expectedStart: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "ちょうど30日前の日付は同じ年",
+			postCreatedAt: time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC),
+			content:       "12/1 18:00",
+			expectedStart: time.Date(2025, 12, 1, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "31日前の日付は翌年",
+			postCreatedAt: time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC),
+			content:       "11/30 18:00",
+			expectedStart: time.Date(2026, 11, 30, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "1月の投稿の年末の日付は前年",
+			postCreatedAt: time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC),
+			content:       "12月28日 19:00",
+			expectedStart: time.Date(2025, 12, 28, 19, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "1年後から30日を引いた日より前は同じ年",
+			postCreatedAt: time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC),
+			content:       "12/5 19:00",
+			expectedStart: time.Date(2026, 12, 5, 19, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "先日は投稿日以前",
+			postCreatedAt: time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC),
+			content:       "先日の12/5 19:00の公演ありがとうございました",
+			expectedStart: time.Date(2025, 12, 5, 19, 0, 0, 0, time.UTC),
+			pastContext:   true,
+		},
+		{
+			name:          "昨日は翌年に繰り越さない",
+			postCreatedAt: time.Date(2025, 10, 20, 12, 0, 0, 0, time.UTC),
+			content:       "昨日は9/1（月）から続いたツアーの最終日でした",
+			expectedStart: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+			pastContext:   true,
+		},
+		{
+			name:          "英語のwas",
+			postCreatedAt: time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC),
+			content:       "The show on 12/30 was amazing",
+			expectedStart: time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC),
+			pastContext:   true,
+		},
+		{
+			name:          "別の文の過去の表現は使わない",
+			postCreatedAt: time.Date(2025, 12, 20, 12, 0, 0, 0, time.UTC),
+			content:       "先日はありがとう！次は1/10 18:00です",
+			expectedStart: time.Date(2026, 1, 10, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "閏年でない年を跨ぐ2/29は次の閏年",
+			postCreatedAt: time.Date(2026, 12, 20, 12, 0, 0, 0, time.UTC),
+			content:       "2/29 18:00 開演",
+			expectedStart: time.Date(2028, 2, 29, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "閏年の年末の投稿の2/29は最も近い閏年",
+			postCreatedAt: time.Date(2028, 12, 31, 12, 0, 0, 0, time.UTC),
+			content:       "2/29 18:00 開演",
+			expectedStart: time.Date(2028, 2, 29, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "閏年の2/29はその年",
+			postCreatedAt: time.Date(2028, 2, 1, 12, 0, 0, 0, time.UTC),
+			content:       "2月29日 19:00",
+			expectedStart: time.Date(2028, 2, 29, 19, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "先日の2/29は投稿日以前の閏年",
+			postCreatedAt: time.Date(2027, 3, 5, 12, 0, 0, 0, time.UTC),
+			content:       "先日の2/29 19:00の公演ありがとうございました",
+			expectedStart: time.Date(2024, 2, 29, 19, 0, 0, 0, time.UTC),
+			pastContext:   true,
+		},
+		{
+			name:          "年の記載がある場合は過去の表現があっても変えない",
+			postCreatedAt: time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC),
+			content:       "先日発表した2026年3月1日の公演",
+			expectedStart: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := service.Extract(tt.content, tt.postCreatedAt)
+			if span == nil {
+				t.Fatalf("日時が抽出されるべきです: %q", tt.content)
+			}
+			if !span.StartsAt.Equal(tt.expectedStart) {
+				t.Errorf("開始時刻が一致しません\n期待値: %s\n実際値: %s",
+					tt.expectedStart.Format("2006-01-02 15:04:05"), span.StartsAt.Format("2006-01-02 15:04:05"))
+			}
+			hasPastContext := false
+			for _, assumption := range span.Assumptions {
+				if assumption == DateTimeAssumptionPastContext {
+					hasPastContext = true
+				}
+			}
+			if hasPastContext != tt.pastContext {
+				t.Errorf("past_contextの有無: 期待値 %v, 実際 %v (%v)", tt.pastContext, hasPastContext, span.Assumptions)
+			}
+		})
+	}
+}
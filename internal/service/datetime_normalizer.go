@@ -0,0 +1,188 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 日時抽出の前に投稿内容を正規化する
+//   - 全角数字・記号（"１０／５ １８：００" → "10/5 18:00"）
+//   - 漢数字（"十月五日" → "10月5日"、年月日時分の前のみ。"十分（じゅうぶん）"と区別できないため時の後以外の"十分"は変換しない）
+//   - 和暦（"令和8年" → "2026年"、"令和元年" → "2019年"）
+//   - 半（"18時半" → "18:30"、"午後6時半" など時間帯の付いた時刻は "午後6時30分"）
+//
+// 曜日の括弧は全角（"（月）"）のままパターンに一致させるため変換しない
+
+var (
+	dateTimeFullWidthRegex = regexp.MustCompile(`[０-９：／－．]`)
+	dateTimeKanjiRegex     = regexp.MustCompile(`([〇零一二三四五六七八九十]+)([年月日時分])`)
+	dateTimeEraRegex       = regexp.MustCompile(`(令和|平成|昭和)(元|\d{1,2})年`)
+	dateTimeHalfHourRegex  = regexp.MustCompile(`(午前|午後|夜|朝|昼)?\d{1,2}(時半)`)
+)
+
+// 元号の開始年の前年（元号の年 + この値 = 西暦）
+var japaneseEraOffsets = map[string]int{
+	"令和": 2018,
+	"平成": 1988,
+	"昭和": 1925,
+}
+
+var kanjiDigits = map[rune]int{
+	'〇': 0, '零': 0, '一': 1, '二': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+// 日時抽出用に正規化した投稿内容
+// 正規化後の位置を元の投稿内容の位置に対応付けられるようにする
+type normalizedText struct {
+	original string
+	text     string
+	starts   []int // 正規化後の各バイト位置に対応する元のバイト位置（一致の開始位置として使う）
+	ends     []int // 正規化後の各バイト位置に対応する元のバイト位置（一致の終了位置として使う）
+}
+
+// 投稿内容を日時抽出用に正規化
+func normalizeDateTimeText(content string) *normalizedText {
+	n := &normalizedText{
+		original: content,
+		text:     content,
+		starts:   make([]int, len(content)+1),
+		ends:     make([]int, len(content)+1),
+	}
+	for i := range n.starts {
+		n.starts[i] = i
+		n.ends[i] = i
+	}
+
+	n.replaceAll(dateTimeFullWidthRegex, 0, func(m []string, before string) (string, bool) {
+		r := []rune(m[0])[0]
+		if r >= '０' && r <= '９' {
+			return string(r - '０' + '0'), true
+		}
+		return string(r - '！' + '!'), true
+	})
+	n.replaceAll(dateTimeKanjiRegex, 1, func(m []string, before string) (string, bool) {
+		if m[1] == "十" && m[2] == "分" && !strings.HasSuffix(before, "時") {
+			return "", false
+		}
+		value, ok := parseKanjiNumber(m[1])
+		if !ok {
+			return "", false
+		}
+		return strconv.Itoa(value), true
+	})
+	n.replaceAll(dateTimeEraRegex, 0, func(m []string, before string) (string, bool) {
+		year := 1
+		if m[2] != "元" {
+			year, _ = strconv.Atoi(m[2])
+		}
+		return strconv.Itoa(japaneseEraOffsets[m[1]]+year) + "年", true
+	})
+	n.replaceAll(dateTimeHalfHourRegex, 2, func(m []string, before string) (string, bool) {
+		// 時間帯の付いた時刻は時間帯のパターンで午前・午後を解釈するため、分の表記にする
+		if m[1] != "" {
+			return "時30分", true
+		}
+		return ":30", true
+	})
+
+	return n
+}
+
+// 正規化後の範囲を元の投稿内容の範囲（バイト位置）に変換
+func (n *normalizedText) originalRange(start, end int) (int, int) {
+	return n.starts[start], n.ends[end]
+}
+
+// 正規表現に一致した箇所のサブマッチ（groupが0なら一致全体）をreplaceの結果に置き換える
+// replaceには一致した箇所のサブマッチと、一致より前の内容を渡す。falseを返した場合は置き換えない
+func (n *normalizedText) replaceAll(re *regexp.Regexp, group int, replace func(m []string, before string) (string, bool)) {
+	locs := re.FindAllStringSubmatchIndex(n.text, -1)
+	if len(locs) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	starts := make([]int, 0, len(n.starts))
+	ends := make([]int, 1, len(n.ends))
+	ends[0] = n.ends[0]
+
+	// 元の位置の対応を引き継いでコピー
+	copyRange := func(from, to int) {
+		for i := from; i < to; i++ {
+			starts = append(starts, n.starts[i])
+			ends = append(ends, n.ends[i+1])
+		}
+		b.WriteString(n.text[from:to])
+	}
+
+	last := 0
+	for _, loc := range locs {
+		from, to := loc[2*group], loc[2*group+1]
+		if from < 0 {
+			continue
+		}
+		replacement, ok := replace(submatches(n.text, loc), n.text[:loc[0]])
+		if !ok {
+			continue
+		}
+
+		copyRange(last, from)
+		// 置き換えた文字列はすべて置き換え前の範囲全体に対応させる
+		for i := 0; i < len(replacement); i++ {
+			starts = append(starts, n.starts[from])
+			ends = append(ends, n.ends[to])
+		}
+		b.WriteString(replacement)
+		last = to
+	}
+	copyRange(last, len(n.text))
+	starts = append(starts, n.starts[len(n.text)])
+
+	n.text = b.String()
+	n.starts = starts
+	n.ends = ends
+}
+
+// 漢数字を数値に変換（"十五" → 15、"二〇二六" → 2026）
+// 十を2つ以上含むもの、百以上の位を含むものは変換しない
+func parseKanjiNumber(s string) (int, bool) {
+	digits := func(runes []rune) (int, bool) {
+		value := 0
+		for _, r := range runes {
+			digit, ok := kanjiDigits[r]
+			if !ok {
+				return 0, false
+			}
+			value = value*10 + digit
+		}
+		return value, true
+	}
+
+	parts := strings.Split(s, "十")
+	switch len(parts) {
+	case 1:
+		return digits([]rune(s))
+	case 2:
+		left, right := []rune(parts[0]), []rune(parts[1])
+		if len(left) > 1 || len(right) > 1 {
+			return 0, false
+		}
+		tens, ones := 1, 0
+		var ok bool
+		if len(left) == 1 {
+			if tens, ok = digits(left); !ok {
+				return 0, false
+			}
+		}
+		if len(right) == 1 {
+			if ones, ok = digits(right); !ok {
+				return 0, false
+			}
+		}
+		return tens*10 + ones, true
+	default:
+		return 0, false
+	}
+}
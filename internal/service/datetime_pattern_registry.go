@@ -0,0 +1,479 @@
+package service
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"lovender_backend/internal/models"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// 組み込みの日時抽出パターン（優先度順）
+//
+//go:embed datetime_patterns.json
+var builtinDateTimePatterns []byte
+
+// キャプチャグループの役割
+const (
+	groupYear            = "year"
+	groupMonth           = "month"
+	groupDay             = "day"
+	groupHour            = "hour"
+	groupMinute          = "minute"
+	groupEndHour         = "end_hour"
+	groupEndMinute       = "end_minute"
+	groupWeekday         = "weekday"          // 曜日（"月"〜"日"）
+	groupDurationHours   = "duration_hours"   // 期間（時間）。開始は投稿日時
+	groupDurationMinutes = "duration_minutes" // 期間（分）。開始は投稿日時
+)
+
+var dateTimeGroupRoles = map[string]bool{
+	groupYear: true, groupMonth: true, groupDay: true, groupHour: true, groupMinute: true,
+	groupEndHour: true, groupEndMinute: true, groupWeekday: true,
+	groupDurationHours: true, groupDurationMinutes: true,
+}
+
+var dateTimePatternNameRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// 終了の記載がない場合の長さ（分）
+const defaultDateTimeDurationMinutes = 60
+
+// NewDateTimeExtractionServiceFromEnv 組み込みのパターンに DATETIME_PATTERNS_FILE（JSON）のパターンを追加する
+func NewDateTimeExtractionServiceFromEnv() (*DateTimeExtractionService, error) {
+	s := NewDateTimeExtractionService()
+
+	path := os.Getenv("DATETIME_PATTERNS_FILE")
+	if path == "" {
+		return s, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open datetime patterns file: %w", err)
+	}
+	defer file.Close()
+
+	if err := s.LoadPatterns(file); err != nil {
+		return nil, fmt.Errorf("failed to load datetime patterns file %s: %w", path, err)
+	}
+	log.Printf("DateTime extraction - loaded patterns from %s", path)
+	return s, nil
+}
+
+// LoadPatterns JSONのパターン定義の配列を読み込んで追加する（同じ名前のパターンは置き換える）
+func (s *DateTimeExtractionService) LoadPatterns(r io.Reader) error {
+	var defs []models.DateTimePatternDefinition
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&defs); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	patterns := make([]dateTimePattern, 0, len(defs))
+	for _, def := range defs {
+		pattern, err := s.compilePattern(def)
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patterns = mergeDateTimePatterns(s.patterns, patterns)
+	return nil
+}
+
+// RegisterPattern パターンを追加する（同じ名前のパターンは置き換える）
+// プロセス内のみに反映されるため、全インスタンスで使うパターンは DATETIME_PATTERNS_FILE に定義する
+func (s *DateTimeExtractionService) RegisterPattern(def models.DateTimePatternDefinition) error {
+	pattern, err := s.compilePattern(def)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patterns = mergeDateTimePatterns(s.patterns, []dateTimePattern{pattern})
+	return nil
+}
+
+// Patterns 登録されているパターン定義を優先度順に返す
+func (s *DateTimeExtractionService) Patterns() []models.DateTimePatternDefinition {
+	patterns := s.currentPatterns()
+	defs := make([]models.DateTimePatternDefinition, 0, len(patterns))
+	for _, pattern := range patterns {
+		defs = append(defs, pattern.def)
+	}
+	return defs
+}
+
+// TestText 投稿内容の例から日時を抽出した結果を返す
+// defを指定した場合は登録せずに追加したパターンで試す。postedAtは日本時間として扱う
+func (s *DateTimeExtractionService) TestText(text string, postedAt time.Time, def *models.DateTimePatternDefinition) (*models.DateTimePatternTestResponse, error) {
+	extractor := s
+	if def != nil {
+		pattern, err := s.compilePattern(*def)
+		if err != nil {
+			return nil, err
+		}
+		extractor = &DateTimeExtractionService{patterns: mergeDateTimePatterns(s.currentPatterns(), []dateTimePattern{pattern})}
+	}
+
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		log.Printf("Warning: Failed to load JST location, using UTC: %v", err)
+		jst = time.UTC
+	}
+
+	spans := extractor.ExtractAllDateTimes(text, postedAt.In(jst))
+	matches := make([]models.EventExtraction, 0, len(spans))
+	for _, span := range spans {
+		matches = append(matches, *newEventExtraction(span))
+	}
+
+	return &models.DateTimePatternTestResponse{
+		NormalizedText: normalizeDateTimeText(text).text,
+		Matches:        matches,
+	}, nil
+}
+
+// 現在のパターン一覧（更新時はスライスごと置き換えるため、返したスライスは変更されない）
+func (s *DateTimeExtractionService) currentPatterns() []dateTimePattern {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.patterns
+}
+
+// 既存のパターンに追加・置き換えた新しい一覧を優先度順（同じ優先度は登録順）で返す
+func mergeDateTimePatterns(current, added []dateTimePattern) []dateTimePattern {
+	merged := make([]dateTimePattern, 0, len(current)+len(added))
+	merged = append(merged, current...)
+	for _, pattern := range added {
+		replaced := false
+		for i := range merged {
+			if merged[i].name == pattern.name {
+				merged[i] = pattern
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, pattern)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].def.Priority < merged[j].def.Priority })
+	return merged
+}
+
+// パターン定義を検証してコンパイル
+func (s *DateTimeExtractionService) compilePattern(def models.DateTimePatternDefinition) (dateTimePattern, error) {
+	invalid := func(format string, args ...interface{}) (dateTimePattern, error) {
+		return dateTimePattern{}, fmt.Errorf("invalid pattern: %s: %s", def.Name, fmt.Sprintf(format, args...))
+	}
+
+	if !dateTimePatternNameRegex.MatchString(def.Name) {
+		return dateTimePattern{}, fmt.Errorf("invalid pattern: name must consist of lowercase letters, digits and underscores")
+	}
+	regex, err := regexp.Compile(def.Regex)
+	if err != nil {
+		return invalid("invalid regex: %v", err)
+	}
+
+	groups := def.Groups
+	for role, index := range groups {
+		if !dateTimeGroupRoles[role] {
+			return invalid("unknown group role %q", role)
+		}
+		if index < 1 || index > regex.NumSubexp() {
+			return invalid("group %q refers to missing capture group %d", role, index)
+		}
+	}
+	has := func(role string) bool {
+		_, ok := groups[role]
+		return ok
+	}
+
+	hasDuration := has(groupDurationHours) || has(groupDurationMinutes)
+	switch {
+	case has(groupMonth) != has(groupDay):
+		return invalid("month and day must be specified together")
+	case has(groupYear) && !has(groupMonth):
+		return invalid("year requires month and day")
+	case has(groupMinute) && !has(groupHour):
+		return invalid("minute requires hour")
+	case has(groupEndHour) && !has(groupHour):
+		return invalid("end_hour requires hour")
+	case has(groupEndMinute) && !has(groupEndHour):
+		return invalid("end_minute requires end_hour")
+	case has(groupWeekday) != (def.WeekdayMode != ""):
+		return invalid("weekday and weekday_mode must be specified together")
+	case hasDuration && (def.Until || has(groupHour) || def.Time != ""):
+		return invalid("duration cannot be combined with until, hour or time")
+	case def.Until && !has(groupHour):
+		return invalid("until requires hour")
+	case def.Time != "" && has(groupHour):
+		return invalid("time cannot be combined with hour")
+	case def.DurationMinutes < 0:
+		return invalid("duration_minutes must not be negative")
+	}
+
+	dateCount := 0
+	for _, specified := range []bool{has(groupMonth), def.RelativeDays != nil, has(groupWeekday)} {
+		if specified {
+			dateCount++
+		}
+	}
+	if dateCount > 1 {
+		return invalid("only one of month/day, relative_days and weekday can be specified")
+	}
+
+	switch def.WeekdayMode {
+	case "", "this_week", "next", "next_week":
+	default:
+		return invalid("unknown weekday_mode %q", def.WeekdayMode)
+	}
+	switch def.Meridiem {
+	case "", "am", "pm", "night":
+	default:
+		return invalid("unknown meridiem %q", def.Meridiem)
+	}
+	if def.Time != "" {
+		if _, err := time.Parse("15:04", def.Time); err != nil {
+			return invalid("time must be HH:MM")
+		}
+	}
+
+	parts := dateTimePatternParts(def)
+	if parts == 0 {
+		return invalid("pattern must capture a date, time or duration")
+	}
+	if def.Example != "" && !regex.MatchString(normalizeDateTimeText(def.Example).text) {
+		return invalid("example %q does not match regex", def.Example)
+	}
+
+	return dateTimePattern{
+		name:  def.Name,
+		kind:  dateTimePatternKind(parts, hasDuration),
+		parts: parts,
+		regex: regex,
+		valid: func(matches []string) bool {
+			return validDateTimeMatch(&def, matches)
+		},
+		handler: func(matches []string, postCreatedAt time.Time) (time.Time, *time.Time) {
+			return s.resolve(&def, matches, postCreatedAt)
+		},
+		def: def,
+	}, nil
+}
+
+// 一致した月日が存在する日付か（"13/40" や "2/30" は日付として扱わない）
+// 年の記載がない2/29は閏年の日付として有効とする
+func validDateTimeMatch(def *models.DateTimePatternDefinition, matches []string) bool {
+	monthIndex, ok := def.Groups[groupMonth]
+	if !ok {
+		return true
+	}
+	month, _ := strconv.Atoi(matches[monthIndex])
+	day, _ := strconv.Atoi(matches[def.Groups[groupDay]])
+	year := 2000
+	if index, ok := def.Groups[groupYear]; ok {
+		year, _ = strconv.Atoi(matches[index])
+	}
+	if month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	return day <= time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// 定義から明示される情報を求める
+func dateTimePatternParts(def models.DateTimePatternDefinition) dateTimeParts {
+	has := func(role string) bool {
+		_, ok := def.Groups[role]
+		return ok
+	}
+
+	var parts dateTimeParts
+	if has(groupYear) {
+		parts |= partYear
+	}
+	if has(groupMonth) {
+		parts |= partDate
+	}
+	if def.RelativeDays != nil || has(groupWeekday) {
+		parts |= partRelativeDate
+	}
+	switch {
+	case def.Until:
+		// 一致した時刻は終了時刻
+		parts |= partEnd | partUntil
+	case has(groupDurationHours) || has(groupDurationMinutes):
+		parts |= partEnd | partUntil
+	case has(groupHour):
+		parts |= partTime
+		if has(groupEndHour) {
+			parts |= partEnd
+		}
+	case def.Time != "":
+		parts |= partApproximateTime
+	}
+	return parts
+}
+
+// 明示される情報からパターンの種類を求める
+func dateTimePatternKind(parts dateTimeParts, hasDuration bool) dateTimeKind {
+	hasDate := parts&(partDate|partRelativeDate) != 0
+	hasTime := parts&(partTime|partApproximateTime|partUntil) != 0
+	switch {
+	case hasDuration:
+		return dateTimeKindOther
+	case hasDate && hasTime:
+		return dateTimeKindDateTime
+	case hasDate:
+		return dateTimeKindDate
+	default:
+		return dateTimeKindTime
+	}
+}
+
+// 定義に従って一致した内容から開始・終了日時を求める
+// 日付の記載がない場合はbase（投稿日時、または組み合わせる日付）の日付を使う
+func (s *DateTimeExtractionService) resolve(def *models.DateTimePatternDefinition, matches []string, base time.Time) (time.Time, *time.Time) {
+	value := func(role string) (int, bool) {
+		index, ok := def.Groups[role]
+		if !ok {
+			return 0, false
+		}
+		n, _ := strconv.Atoi(matches[index])
+		return n, true
+	}
+	loc := base.Location()
+
+	// 期間（開始は投稿日時）
+	if hours, ok := value(groupDurationHours); ok {
+		endsAt := base.Add(time.Duration(hours) * time.Hour)
+		return base, &endsAt
+	}
+	if minutes, ok := value(groupDurationMinutes); ok {
+		endsAt := base.Add(time.Duration(minutes) * time.Minute)
+		return base, &endsAt
+	}
+
+	// 日付
+	year, month, day := base.Date()
+	if m, ok := value(groupMonth); ok {
+		d, _ := value(groupDay)
+		month, day = time.Month(m), d
+		if y, ok := value(groupYear); ok {
+			year = y
+		} else {
+			year = s.inferYear(m, d, base)
+		}
+	} else if def.RelativeDays != nil {
+		year, month, day = base.AddDate(0, 0, *def.RelativeDays).Date()
+	} else if index, ok := def.Groups[groupWeekday]; ok {
+		year, month, day = base.AddDate(0, 0, s.weekdayOffset(def.WeekdayMode, matches[index], base)).Date()
+	}
+
+	// 時刻
+	hour, minute := 0, 0
+	if h, ok := value(groupHour); ok {
+		hour = applyMeridiem(def.Meridiem, h)
+		minute, _ = value(groupMinute)
+	} else if def.Time != "" {
+		fixed, _ := time.Parse("15:04", def.Time)
+		hour, minute = fixed.Hour(), fixed.Minute()
+	}
+	startsAt := time.Date(year, month, day, hour, minute, 0, 0, loc)
+
+	// "まで"の場合は終了時刻として扱い、開始時刻は投稿時刻とする
+	if def.Until {
+		return base, &startsAt
+	}
+
+	if endHour, ok := value(groupEndHour); ok {
+		endMinute, _ := value(groupEndMinute)
+		endsAt := time.Date(year, month, day, applyMeridiem(def.Meridiem, endHour), endMinute, 0, 0, loc)
+		return startsAt, &endsAt
+	}
+
+	duration := def.DurationMinutes
+	if duration == 0 {
+		duration = defaultDateTimeDurationMinutes
+	}
+	endsAt := startsAt.Add(time.Duration(duration) * time.Minute)
+	return startsAt, &endsAt
+}
+
+// 午前・午後の指定に従って時を24時間制にする
+func applyMeridiem(meridiem string, hour int) int {
+	switch meridiem {
+	case "am":
+		if hour == 12 {
+			return 0 // 午前12時は0時
+		}
+	case "pm":
+		if hour != 12 {
+			return hour + 12 // 午後は12時間追加（12時は除く）
+		}
+	case "night":
+		if hour < 12 {
+			return hour + 12 // 夜は午後として扱う
+		}
+	}
+	return hour
+}
+
+// 曜日表現の投稿日からの日数
+func (s *DateTimeExtractionService) weekdayOffset(mode string, weekdayStr string, postCreatedAt time.Time) int {
+	targetWeekday := s.parseWeekday(weekdayStr)
+	daysUntil := s.daysUntilWeekday(postCreatedAt.Weekday(), targetWeekday)
+
+	switch mode {
+	case "this_week":
+		// 今週の場合、過去の曜日も含める（例：金曜日に「今週の月曜日」と言った場合、今週の月曜日を指す）
+		if daysUntil > 0 && targetWeekday < postCreatedAt.Weekday() {
+			daysUntil -= 7
+		}
+	case "next":
+		if daysUntil == 0 {
+			daysUntil = 7 // 今日が同じ曜日なら来週
+		}
+	case "next_week":
+		daysUntil += 7 // 来週なので+7日
+	}
+	return daysUntil
+}
+
+// 抽出した日時を保存・表示用の根拠に変換（日時はUTC）
+func newEventExtraction(span DateTimeSpan) *models.EventExtraction {
+	var endsAt *time.Time
+	if span.EndsAt != nil {
+		utcTime := span.EndsAt.UTC()
+		endsAt = &utcTime
+	}
+	return &models.EventExtraction{
+		Pattern:     span.Match.Name,
+		MatchedText: span.Match.Text,
+		SpanStart:   span.Match.Start,
+		SpanEnd:     span.Match.End,
+		StartsAt:    span.StartsAt.UTC(),
+		EndsAt:      endsAt,
+		Confidence:  span.Confidence,
+		Assumptions: span.Assumptions,
+	}
+}
+
+// 組み込みのパターンを読み込む（組み込みのパターンが不正な場合はプログラムの誤りなのでpanicする）
+func (s *DateTimeExtractionService) loadBuiltinPatterns() {
+	if err := s.LoadPatterns(bytes.NewReader(builtinDateTimePatterns)); err != nil {
+		panic("failed to load builtin datetime patterns: " + err.Error())
+	}
+}
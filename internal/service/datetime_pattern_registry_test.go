@@ -0,0 +1,185 @@
+package service
+
+import (
+	"lovender_backend/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDateTimeExtractionService_BuiltinPatterns(t *testing.T) {
+	service := NewDateTimeExtractionService()
+
+	defs := service.Patterns()
+	if len(defs) != 54 {
+		t.Fatalf("組み込みのパターン数が一致しません: got %d, want 54", len(defs))
+	}
+
+	names := make(map[string]bool)
+	for i, def := range defs {
+		if names[def.Name] {
+			t.Errorf("パターン名が重複しています: %s", def.Name)
+		}
+		names[def.Name] = true
+		if i > 0 && defs[i-1].Priority >= def.Priority {
+			t.Errorf("優先度順に並んでいません: %s(%d) の後に %s(%d)", defs[i-1].Name, defs[i-1].Priority, def.Name, def.Priority)
+		}
+		if def.Example == "" {
+			t.Errorf("パターン %s に例がありません", def.Name)
+		}
+	}
+}
+
+func TestDateTimeExtractionService_RegisterPattern(t *testing.T) {
+	service := NewDateTimeExtractionService()
+	jst, _ := time.LoadLocation("Asia/Tokyo")
+	postCreatedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, jst)
+
+	// 組み込みのパターンでは日付のみ一致する
+	spans := service.ExtractAllDateTimes("10/5 19h30 開演", postCreatedAt)
+	if len(spans) != 1 || spans[0].Match.Name != "slash_date" {
+		t.Fatalf("追加前は slash_date のみに一致する想定です: %+v", spans)
+	}
+
+	err := service.RegisterPattern(models.DateTimePatternDefinition{
+		Name:     "h_time",
+		Priority: 395,
+		Example:  "19h30",
+		Regex:    `(\d{1,2})h(\d{2})`,
+		Groups:   map[string]int{"hour": 1, "minute": 2},
+	})
+	if err != nil {
+		t.Fatalf("パターンの追加に失敗しました: %v", err)
+	}
+
+	// 時刻のみのパターンは直前の日付と組み合わせる
+	spans = service.ExtractAllDateTimes("１０/５ 19h30 開演", postCreatedAt)
+	if len(spans) != 1 {
+		t.Fatalf("抽出件数が一致しません: got %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Match.Name != "slash_date+h_time" {
+		t.Errorf("パターン名が一致しません: got %s, want slash_date+h_time", span.Match.Name)
+	}
+	if expected := time.Date(2026, 10, 5, 19, 30, 0, 0, jst); !span.StartsAt.Equal(expected) {
+		t.Errorf("開始日時が一致しません: got %v, want %v", span.StartsAt, expected)
+	}
+	if span.EndsAt == nil || !span.EndsAt.Equal(time.Date(2026, 10, 5, 20, 30, 0, 0, jst)) {
+		t.Errorf("終了日時が一致しません: got %v", span.EndsAt)
+	}
+	if span.Confidence != 0.75 {
+		t.Errorf("信頼度が一致しません: got %v, want 0.75", span.Confidence)
+	}
+
+	// 優先度の位置に挿入される
+	defs := service.Patterns()
+	for i, def := range defs {
+		if def.Name == "h_time" && (defs[i-1].Name != "next_week_weekday" || defs[i+1].Name != "time") {
+			t.Errorf("優先度の位置に挿入されていません: %s, %s", defs[i-1].Name, defs[i+1].Name)
+		}
+	}
+
+	// 同じ名前で登録すると置き換える
+	err = service.RegisterPattern(models.DateTimePatternDefinition{
+		Name:            "h_time",
+		Priority:        395,
+		Regex:           `(\d{1,2})h(\d{2})`,
+		Groups:          map[string]int{"hour": 1, "minute": 2},
+		DurationMinutes: 120,
+	})
+	if err != nil {
+		t.Fatalf("パターンの置き換えに失敗しました: %v", err)
+	}
+	if len(service.Patterns()) != len(defs) {
+		t.Errorf("置き換えでパターン数が変わりました: got %d, want %d", len(service.Patterns()), len(defs))
+	}
+	span2 := service.Extract("19h30 開演", postCreatedAt)
+	if span2 == nil || span2.EndsAt == nil || !span2.EndsAt.Equal(time.Date(2026, 10, 1, 21, 30, 0, 0, jst)) {
+		t.Errorf("置き換えたパターンの長さが使われていません: %+v", span2)
+	}
+}
+
+func TestDateTimeExtractionService_LoadPatterns(t *testing.T) {
+	service := NewDateTimeExtractionService()
+	jst, _ := time.LoadLocation("Asia/Tokyo")
+	postCreatedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, jst)
+
+	err := service.LoadPatterns(strings.NewReader(`[
+		{"name": "evening", "priority": 480, "example": "夕方", "regex": "夕方", "time": "17:00"},
+		{"name": "day_after_after_tomorrow_time", "priority": 255, "example": "しあさって19時", "regex": "しあさって(\\d{1,2})時", "groups": {"hour": 1}, "relative_days": 3}
+	]`))
+	if err != nil {
+		t.Fatalf("パターンの読み込みに失敗しました: %v", err)
+	}
+
+	span := service.Extract("夕方から配信", postCreatedAt)
+	if span == nil || !span.StartsAt.Equal(time.Date(2026, 10, 1, 17, 0, 0, 0, jst)) {
+		t.Errorf("置き換えた evening の時刻が使われていません: %+v", span)
+	}
+
+	span = service.Extract("しあさって19時から", postCreatedAt)
+	if span == nil || span.Match.Name != "day_after_after_tomorrow_time" {
+		t.Fatalf("追加したパターンに一致しませんでした: %+v", span)
+	}
+	if expected := time.Date(2026, 10, 4, 19, 0, 0, 0, jst); !span.StartsAt.Equal(expected) {
+		t.Errorf("開始日時が一致しません: got %v, want %v", span.StartsAt, expected)
+	}
+}
+
+func TestDateTimeExtractionService_RegisterPattern_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		def  models.DateTimePatternDefinition
+	}{
+		{
+			name: "名前が不正",
+			def:  models.DateTimePatternDefinition{Name: "Dot Date", Regex: `(\d{1,2}):(\d{2})`, Groups: map[string]int{"hour": 1, "minute": 2}},
+		},
+		{
+			name: "正規表現が不正",
+			def:  models.DateTimePatternDefinition{Name: "broken", Regex: `(\d{1,2}:(\d{2})`, Groups: map[string]int{"hour": 1}},
+		},
+		{
+			name: "不明な役割",
+			def:  models.DateTimePatternDefinition{Name: "unknown_role", Regex: `(\d{1,2})時`, Groups: map[string]int{"second": 1}},
+		},
+		{
+			name: "存在しないキャプチャグループ",
+			def:  models.DateTimePatternDefinition{Name: "missing_group", Regex: `(\d{1,2})時`, Groups: map[string]int{"hour": 2}},
+		},
+		{
+			name: "月のみで日がない",
+			def:  models.DateTimePatternDefinition{Name: "month_only", Regex: `(\d{1,2})月`, Groups: map[string]int{"month": 1}},
+		},
+		{
+			name: "曜日の解釈がない",
+			def:  models.DateTimePatternDefinition{Name: "weekday_only", Regex: `([月火水木金土日])曜`, Groups: map[string]int{"weekday": 1}},
+		},
+		{
+			name: "時刻の形式が不正",
+			def:  models.DateTimePatternDefinition{Name: "bad_time", Regex: `夕方`, Time: "17時"},
+		},
+		{
+			name: "日時の情報がない",
+			def:  models.DateTimePatternDefinition{Name: "no_parts", Regex: `開演`},
+		},
+		{
+			name: "例が一致しない",
+			def:  models.DateTimePatternDefinition{Name: "bad_example", Example: "18時", Regex: `(\d{1,2}):(\d{2})`, Groups: map[string]int{"hour": 1, "minute": 2}},
+		},
+	}
+
+	service := NewDateTimeExtractionService()
+	count := len(service.Patterns())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.RegisterPattern(tt.def)
+			if err == nil || !strings.HasPrefix(err.Error(), "invalid pattern") {
+				t.Errorf("invalid pattern エラーになる想定です: %v", err)
+			}
+		})
+	}
+	if len(service.Patterns()) != count {
+		t.Errorf("不正なパターンが登録されました: got %d, want %d", len(service.Patterns()), count)
+	}
+}
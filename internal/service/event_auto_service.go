@@ -7,6 +7,7 @@ import (
 	"lovender_backend/internal/client"
 	"lovender_backend/internal/models"
 	"lovender_backend/internal/repository"
+	"lovender_backend/pkg/socialaccount"
 	"strings"
 	"sync"
 	"time"
@@ -15,16 +16,22 @@ import (
 // EventAutoService イベント自動登録サービス
 type EventAutoService struct {
 	eventsRepo        repository.EventsRepository
+	candidateRepo     repository.EventCandidateRepository
 	keywordCache      *KeywordCacheService
 	externalClient    *client.ExternalPostClient
 	dateTimeExtractor *DateTimeExtractionService
 	jstLocation       *time.Location
 }
 
+// ユーザーが手動で推しの投稿を取り込める間隔
+const oshiSyncInterval = time.Minute
+
 // NewEventAutoService コンストラクタ
 func NewEventAutoService(
 	eventsRepo repository.EventsRepository,
+	candidateRepo repository.EventCandidateRepository,
 	keywordCache *KeywordCacheService,
+	dateTimeExtractor *DateTimeExtractionService,
 ) *EventAutoService {
 	jst, err := time.LoadLocation("Asia/Tokyo")
 	if err != nil {
@@ -34,9 +41,10 @@ func NewEventAutoService(
 
 	return &EventAutoService{
 		eventsRepo:        eventsRepo,
+		candidateRepo:     candidateRepo,
 		keywordCache:      keywordCache,
 		externalClient:    client.NewExternalPostClient(),
-		dateTimeExtractor: NewDateTimeExtractionService(),
+		dateTimeExtractor: dateTimeExtractor,
 		jstLocation:       jst,
 	}
 }
@@ -54,9 +62,10 @@ func (s *EventAutoService) ProcessAutoEventCreation(ctx context.Context) (*AutoE
 	log.Printf("Found %d oshis to process", len(oshis))
 
 	result := &AutoEventResult{
-		ProcessedOshis: 0,
-		CreatedEvents:  0,
-		Errors:         []string{},
+		ProcessedOshis:   0,
+		CreatedEvents:    0,
+		QueuedCandidates: 0,
+		Errors:           []string{},
 	}
 
 	// 並列処理用のチャネルとワーカープール
@@ -92,13 +101,39 @@ func (s *EventAutoService) ProcessAutoEventCreation(ctx context.Context) (*AutoE
 	for oshiResult := range resultChan {
 		result.ProcessedOshis++
 		result.CreatedEvents += oshiResult.CreatedEvents
+		result.QueuedCandidates += oshiResult.QueuedCandidates
 		if oshiResult.Error != "" {
 			result.Errors = append(result.Errors, oshiResult.Error)
 		}
 	}
 
-	log.Printf("Auto event creation completed. Processed: %d oshis, Created: %d events, Errors: %d",
-		result.ProcessedOshis, result.CreatedEvents, len(result.Errors))
+	log.Printf("Auto event creation completed. Processed: %d oshis, Created: %d events, Queued: %d candidates, Errors: %d",
+		result.ProcessedOshis, result.CreatedEvents, result.QueuedCandidates, len(result.Errors))
+
+	return result, nil
+}
+
+// ユーザーの推し1件の投稿を今すぐ取り込む（ユーザーごとに実行間隔を制限）
+func (s *EventAutoService) SyncOshi(ctx context.Context, userID int64, oshiID int64) (*OshiProcessResult, error) {
+	oshi, err := s.eventsRepo.GetOshiWithAccountsAndCategoriesByID(oshiID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 実行間隔はDBに記録した前回の取り込み日時で判定する（複数インスタンスでも共有される）
+	now := time.Now()
+	claimed, lastSyncedAt, err := s.eventsRepo.ClaimOshiSync(userID, now, oshiSyncInterval)
+	if err != nil {
+		log.Printf("SyncOshi ERROR: failed to claim sync for user %d: %v", userID, err)
+		return nil, err
+	}
+	if !claimed {
+		return nil, &RateLimitError{RetryAfter: intervalRetryAfter(lastSyncedAt, now, oshiSyncInterval)}
+	}
+
+	result := s.processOshiPosts(ctx, oshi)
+	log.Printf("Manual sync completed for oshi %d (user %d). Created: %d events, Queued: %d candidates",
+		oshiID, userID, result.CreatedEvents, result.QueuedCandidates)
 
 	return result, nil
 }
@@ -123,13 +158,28 @@ func (s *EventAutoService) processOshiWorker(
 	}
 }
 
+// 投稿を取得するアカウントのハンドル（投稿の取得はXのみ対応。ハンドルのないURLは取得できない）
+func postSourceHandles(accounts []*models.OshiAccount) []string {
+	handles := make([]string, 0, len(accounts))
+	for _, account := range accounts {
+		if account.Platform != string(socialaccount.X) || account.Handle == nil || *account.Handle == "" {
+			continue
+		}
+		handles = append(handles, *account.Handle)
+	}
+	return handles
+}
+
 // 推しの投稿を処理
 func (s *EventAutoService) processOshiPosts(ctx context.Context, oshi *models.OshiWithDetails) *OshiProcessResult {
 	result := &OshiProcessResult{
-		OshiID:        oshi.Oshi.ID,
-		OshiName:      oshi.Oshi.Name,
-		CreatedEvents: 0,
-		Error:         "",
+		OshiID:             oshi.Oshi.ID,
+		OshiName:           oshi.Oshi.Name,
+		CreatedEvents:      0,
+		QueuedCandidates:   0,
+		CreatedEventIDs:    []int64{},
+		QueuedCandidateIDs: []int64{},
+		Error:              "",
 	}
 
 	// アカウントがない場合はスキップ
@@ -150,12 +200,7 @@ func (s *EventAutoService) processOshiPosts(ctx context.Context, oshi *models.Os
 	}
 
 	// 各アカウントの投稿を処理
-	for _, account := range oshi.Accounts {
-		accountName := s.extractAccountName(account.URL)
-		if accountName == "" {
-			continue
-		}
-
+	for _, accountName := range postSourceHandles(oshi.Accounts) {
 		// 最新5件の投稿を取得
 		posts, err := s.externalClient.GetLatestPostsByUsername(accountName, 5)
 		if err != nil {
@@ -171,8 +216,15 @@ func (s *EventAutoService) processOshiPosts(ctx context.Context, oshi *models.Os
 			case <-ctx.Done():
 				return result
 			default:
-				if s.processPost(oshi.Oshi.ID, post, keywords) {
-					result.CreatedEvents++
+				for _, processed := range s.processPost(oshi.Oshi, accountName, post, keywords) {
+					switch processed.outcome {
+					case postEventCreated:
+						result.CreatedEvents++
+						result.CreatedEventIDs = append(result.CreatedEventIDs, processed.id)
+					case postCandidateQueued:
+						result.QueuedCandidates++
+						result.QueuedCandidateIDs = append(result.QueuedCandidateIDs, processed.id)
+					}
 				}
 			}
 		}
@@ -181,17 +233,24 @@ func (s *EventAutoService) processOshiPosts(ctx context.Context, oshi *models.Os
 	return result
 }
 
-// 投稿を処理してイベント作成
-func (s *EventAutoService) processPost(oshiID int64, post models.ExternalPost, keywords []repository.CategoryKeyword) bool {
-	// 既に登録済みかチェック
-	exists, err := s.eventsRepo.CheckEventExistsByPostIDAndOshiID(post.ID, oshiID)
-	if err != nil {
-		return false
-	}
-	if exists {
-		return false
-	}
+// 投稿の処理結果
+type postProcessOutcome int
+
+const (
+	postSkipped         postProcessOutcome = iota // 対象外・登録済み・エラー
+	postEventCreated                              // イベントとして登録
+	postCandidateQueued                           // 確認待ちの候補として保存
+)
 
+// 投稿内の日時1件の処理結果（作成したイベントまたは候補のID）
+type postProcessResult struct {
+	outcome postProcessOutcome
+	id      int64
+}
+
+// 投稿を処理してイベント作成（推しの設定が確認待ちの場合はイベント候補を保存）
+// 投稿に含まれる日時ごとに1件ずつ作成し、投稿内の位置または開始日時が同じものは重複して作成しない
+func (s *EventAutoService) processPost(oshi *models.Oshi, accountName string, post models.ExternalPost, keywords []repository.CategoryKeyword) []postProcessResult {
 	// キーワードマッチング
 	var matchedKeywords []string
 	var matchedCategoryID *uint16
@@ -208,77 +267,132 @@ func (s *EventAutoService) processPost(oshiID int64, post models.ExternalPost, k
 
 	// キーワードが一致しない場合はスキップ
 	if len(matchedKeywords) == 0 {
-		return false
+		return nil
 	}
 
 	// 投稿日時をパース（日本時間として扱う）
 	createdAt, err := time.ParseInLocation("2006-01-02 15:04:05", post.CreatedAt, s.jstLocation)
 	if err != nil {
 		log.Printf("Failed to parse created_at for post %d: %v", post.ID, err)
-		return false
+		return nil
 	}
 
-	// 投稿内容から日時情報を抽出（日本時間として抽出される）
-	startsAt, endsAt, hasDateTimePattern := s.dateTimeExtractor.ExtractDateTime(post.Content, createdAt)
+	// 投稿内容から日時情報をすべて抽出（日本時間として抽出される）
+	spans := s.dateTimeExtractor.ExtractAllDateTimes(post.Content, createdAt)
 
 	// 日時パターンが見つからない場合はスキップ
-	if !hasDateTimePattern {
+	if len(spans) == 0 {
 		log.Printf("Post[%d] - No datetime pattern found, skipping event creation", post.ID)
-		return false
+		return nil
+	}
+
+	results := make([]postProcessResult, 0, len(spans))
+	for _, span := range spans {
+		outcome, id := s.processPostSpan(oshi, accountName, post, createdAt, matchedKeywords, matchedCategoryID, span)
+		if outcome != postSkipped {
+			results = append(results, postProcessResult{outcome: outcome, id: id})
+		}
+	}
+	return results
+}
+
+// 投稿内の日時1件からイベント（または候補）を作成
+func (s *EventAutoService) processPostSpan(oshi *models.Oshi, accountName string, post models.ExternalPost, createdAt time.Time, matchedKeywords []string, matchedCategoryID *uint16, span DateTimeSpan) (postProcessOutcome, int64) {
+	oshiID := oshi.ID
+	spanStart := span.Match.Start
+
+	// 日時抽出の根拠（UIで作成理由を表示するために保存、日時はUTC）
+	extraction := newEventExtraction(span)
+	startsAtUTC := extraction.StartsAt
+	endsAtUTC := extraction.EndsAt
+
+	// 既に登録済みかチェック
+	exists, err := s.eventsRepo.CheckEventExistsByPostSpanAndOshiID(post.ID, spanStart, startsAtUTC, oshiID)
+	if err != nil {
+		return postSkipped, 0
+	}
+	if exists {
+		return postSkipped, 0
 	}
 
-	// 日時をUTCに変換
-	startsAtUTC := startsAt.UTC()
-	var endsAtUTC *time.Time
-	if endsAt != nil {
-		utcTime := endsAt.UTC()
-		endsAtUTC = &utcTime
+	// 既に候補として保存済み（承認・却下済みを含む）かチェック
+	exists, err = s.candidateRepo.ExistsByPostSpanAndOshiID(post.ID, spanStart, startsAtUTC, oshiID)
+	if err != nil {
+		return postSkipped, 0
+	}
+	if exists {
+		return postSkipped, 0
 	}
 
 	// イベントタイトル生成
 	title := fmt.Sprintf(post.User.Name)
 
+	// 確認待ちの場合はイベント候補として保存
+	if oshi.AutoEventMode != models.AutoEventModePublish {
+		candidate := &models.PostEventCandidate{
+			OshiID:      oshiID,
+			PostID:      post.ID,
+			SpanStart:   spanStart,
+			Content:     post.Content,
+			CreatedAt:   createdAt,
+			AccountName: accountName,
+			Keywords:    matchedKeywords,
+			CategoryID:  matchedCategoryID,
+			Title:       title,
+			StartsAt:    startsAtUTC,
+			EndsAt:      endsAtUTC,
+			Pattern:     span.Match.Pattern,
+			MatchedText: span.Match.Text,
+			Extraction:  extraction,
+		}
+		candidateID, err := s.candidateRepo.Create(candidate)
+		if err != nil {
+			log.Printf("Failed to create event candidate for post %d: %v", post.ID, err)
+			return postSkipped, 0
+		}
+
+		log.Printf("Queued event candidate for oshi %d, post %d (%s at %d), keywords: %v",
+			oshiID, post.ID, span.Match.Name, spanStart, matchedKeywords)
+		return postCandidateQueued, candidateID
+	}
+
 	// イベント作成（UTC時刻で保存）
-	err = s.eventsRepo.CreateAutoEvent(
+	eventID, err := s.eventsRepo.CreateAutoEvent(
 		oshiID,
 		post.ID,
+		spanStart,
 		title,
 		post.Content,
 		matchedCategoryID,
 		startsAtUTC,
 		endsAtUTC,
+		extraction,
 	)
 	if err != nil {
 		log.Printf("Failed to create auto event for post %d: %v", post.ID, err)
-		return false
+		return postSkipped, 0
 	}
 
-	log.Printf("Created auto event for oshi %d, post %d, keywords: %v",
-		oshiID, post.ID, matchedKeywords)
-	return true
-}
-
-// URLからアカウント名を抽出
-func (s *EventAutoService) extractAccountName(url string) string {
-	// 最後のスラッシュ以降を取得
-	parts := strings.Split(url, "/")
-	if len(parts) == 0 {
-		return ""
-	}
-	return parts[len(parts)-1]
+	log.Printf("Created auto event for oshi %d, post %d (%s at %d), keywords: %v",
+		oshiID, post.ID, span.Match.Name, spanStart, matchedKeywords)
+	return postEventCreated, eventID
 }
 
 // 自動イベント作成結果
 type AutoEventResult struct {
-	ProcessedOshis int      `json:"processed_oshis"`
-	CreatedEvents  int      `json:"created_events"`
-	Errors         []string `json:"errors,omitempty"`
+	ProcessedOshis   int      `json:"processed_oshis"`
+	CreatedEvents    int      `json:"created_events"`
+	QueuedCandidates int      `json:"queued_candidates"`
+	Errors           []string `json:"errors,omitempty"`
 }
 
 // 推し処理結果
 type OshiProcessResult struct {
-	OshiID        int64  `json:"oshi_id"`
-	OshiName      string `json:"oshi_name"`
-	CreatedEvents int    `json:"created_events"`
-	Error         string `json:"error,omitempty"`
+	OshiID             int64   `json:"oshi_id"`
+	OshiName           string  `json:"oshi_name"`
+	CreatedEvents      int     `json:"created_events"`
+	QueuedCandidates   int     `json:"queued_candidates"`
+	CreatedEventIDs    []int64 `json:"created_event_ids"`    // 作成したイベントのID
+	QueuedCandidateIDs []int64 `json:"queued_candidate_ids"` // 確認待ちにした候補のID
+	Error              string  `json:"error,omitempty"`
 }
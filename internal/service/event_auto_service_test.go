@@ -0,0 +1,30 @@
+package service
+
+import (
+	"lovender_backend/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestPostSourceHandles(t *testing.T) {
+	handle := func(h string) *string { return &h }
+	accounts := []*models.OshiAccount{
+		{URL: "https://x.com/oshi", Platform: "x", Handle: handle("oshi")},
+		{URL: "https://www.instagram.com/oshi", Platform: "instagram", Handle: handle("oshi_ig")},
+		{URL: "https://www.youtube.com/@oshi", Platform: "youtube", Handle: handle("oshi_yt")},
+		{URL: "https://www.tiktok.com/@oshi", Platform: "tiktok", Handle: handle("oshi_tt")},
+		{URL: "https://bsky.app/profile/oshi.bsky.social", Platform: "bluesky", Handle: handle("oshi.bsky.social")},
+		{URL: "https://example.com/oshi", Platform: "custom"},
+		{URL: "https://x.com/empty", Platform: "x", Handle: handle("")},
+		{URL: "https://x.com/oshi_sub", Platform: "x", Handle: handle("oshi_sub")},
+	}
+
+	// X以外のアカウントはXの投稿取得APIに渡さない
+	want := []string{"oshi", "oshi_sub"}
+	if got := postSourceHandles(accounts); !reflect.DeepEqual(got, want) {
+		t.Errorf("期待値 %v, 実際 %v", want, got)
+	}
+	if got := postSourceHandles(nil); len(got) != 0 {
+		t.Errorf("アカウントがない場合は空にするべきです: %v", got)
+	}
+}
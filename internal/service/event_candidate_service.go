@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/repository"
+)
+
+// 一覧で全状態の候補を返す指定
+const eventCandidateStatusAll = "all"
+
+type EventCandidateService interface {
+	GetCandidates(userID int64, status string) (*models.EventCandidatesResponse, error)
+	AcceptCandidate(candidateID int64, userID int64, data *models.UpdateEventData) (*models.CreateEventResponse, error)
+	RejectCandidate(candidateID int64, userID int64) error
+}
+
+type eventCandidateService struct {
+	candidateRepo repository.EventCandidateRepository
+	eventsRepo    repository.EventsRepository
+}
+
+func NewEventCandidateService(candidateRepo repository.EventCandidateRepository, eventsRepo repository.EventsRepository) EventCandidateService {
+	return &eventCandidateService{
+		candidateRepo: candidateRepo,
+		eventsRepo:    eventsRepo,
+	}
+}
+
+// イベント候補一覧を取得（状態の指定がなければ確認待ちのみ）
+func (s *eventCandidateService) GetCandidates(userID int64, status string) (*models.EventCandidatesResponse, error) {
+	status, err := normalizeEventCandidateStatus(status)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.candidateRepo.GetByUserID(userID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.EventCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		items = append(items, *candidate)
+	}
+
+	return &models.EventCandidatesResponse{Candidates: items}, nil
+}
+
+// イベント候補を承認してイベントを作成（dataがnilなら候補の内容のまま登録）
+func (s *eventCandidateService) AcceptCandidate(candidateID int64, userID int64, data *models.UpdateEventData) (*models.CreateEventResponse, error) {
+	eventID, err := s.candidateRepo.Accept(candidateID, userID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := s.eventsRepo.GetEventByIDWithOshi(eventID, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreateEventResponse{
+		Event: *event,
+	}, nil
+}
+
+func (s *eventCandidateService) RejectCandidate(candidateID int64, userID int64) error {
+	return s.candidateRepo.Reject(candidateID, userID)
+}
+
+// 一覧の状態指定を検証し、リポジトリに渡す値にする（"all" は全件を表す空文字にする）
+func normalizeEventCandidateStatus(status string) (string, error) {
+	switch status {
+	case "":
+		return models.EventCandidateStatusPending, nil
+	case eventCandidateStatusAll:
+		return "", nil
+	case models.EventCandidateStatusPending, models.EventCandidateStatusAccepted, models.EventCandidateStatusRejected:
+		return status, nil
+	}
+	return "", errors.New("invalid status")
+}
@@ -0,0 +1,35 @@
+package service
+
+import "testing"
+
+func TestNormalizeEventCandidateStatus(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", "pending", false},
+		{"all", "", false},
+		{"pending", "pending", false},
+		{"accepted", "accepted", false},
+		{"rejected", "rejected", false},
+		{"unknown", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := normalizeEventCandidateStatus(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("status=%q: エラーになるべきです", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("status=%q: 予期しないエラー: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("status=%q: 期待値 %q, 実際 %q", tt.input, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/repository"
+	"lovender_backend/pkg/ical"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// 1回の取り込みで扱うイベント数の上限
+	maxImportEvents = 500
+
+	// アラーム指定がない場合の通知タイミング（eventsテーブルのデフォルト値）
+	defaultNotificationTiming = "15m"
+)
+
+// 取り込みファイルの形式
+const (
+	EventImportFormatICS = "ics"
+	EventImportFormatCSV = "csv"
+)
+
+// CSVの列名
+// title, starts_at は必須。starts_at, ends_at はRFC3339（タイムゾーンなしの場合は日本時間）
+const (
+	csvColumnTitle              = "title"
+	csvColumnStartsAt           = "starts_at"
+	csvColumnEndsAt             = "ends_at"
+	csvColumnDescription        = "description"
+	csvColumnURL                = "url"
+	csvColumnHasAlarm           = "has_alarm"
+	csvColumnNotificationTiming = "notification_timing"
+)
+
+// タイムゾーンなしで受け付けるCSVの日時形式
+var csvLocalDateTimeFormats = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+}
+
+type EventImportService interface {
+	ImportEvents(userID int64, oshiID int64, format string, r io.Reader) (*models.EventImportResponse, error)
+}
+
+type eventImportService struct {
+	eventsRepo  repository.EventsRepository
+	jstLocation *time.Location
+}
+
+func NewEventImportService(eventsRepo repository.EventsRepository) EventImportService {
+	jst, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		log.Printf("Warning: Failed to load JST location, using UTC: %v", err)
+		jst = time.UTC
+	}
+
+	return &eventImportService{
+		eventsRepo:  eventsRepo,
+		jstLocation: jst,
+	}
+}
+
+// 取り込み対象の1行（不正な行はReasonに理由を入れる）
+type importRow struct {
+	event  *models.CreateEventData
+	reason string
+}
+
+func (s *eventImportService) ImportEvents(userID int64, oshiID int64, format string, r io.Reader) (*models.EventImportResponse, error) {
+	var (
+		rows []importRow
+		err  error
+	)
+	switch format {
+	case EventImportFormatICS:
+		rows, err = s.parseICS(r)
+	case EventImportFormatCSV:
+		rows, err = s.parseCSV(r)
+	default:
+		return nil, errors.New("unsupported format")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid file: %w", err)
+	}
+	if len(rows) > maxImportEvents {
+		return nil, errors.New("too many events")
+	}
+
+	// 有効な行だけを登録対象にする
+	response := &models.EventImportResponse{
+		Rows: make([]models.EventImportRowResult, len(rows)),
+	}
+	valid := make([]*models.CreateEventData, 0, len(rows))
+	validRows := make([]int, 0, len(rows))
+	for i, row := range rows {
+		response.Rows[i].Row = i + 1
+		if row.event != nil {
+			response.Rows[i].Title = row.event.Title
+		}
+
+		reason := row.reason
+		if reason == "" {
+			reason = validateImportedEvent(row.event)
+		}
+		if reason != "" {
+			response.Rows[i].Status = models.EventImportStatusInvalid
+			response.Rows[i].Reason = reason
+			response.Summary.Invalid++
+			continue
+		}
+
+		row.event.OshiID = oshiID
+		valid = append(valid, row.event)
+		validRows = append(validRows, i)
+	}
+
+	// 有効な行がなくても推しの所有確認のため呼び出す
+	eventIDs, err := s.eventsRepo.ImportEventsWithOshi(userID, oshiID, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, eventID := range eventIDs {
+		result := &response.Rows[validRows[i]]
+		if eventID == 0 {
+			result.Status = models.EventImportStatusDuplicate
+			response.Summary.Duplicate++
+			continue
+		}
+		id := eventID
+		result.Status = models.EventImportStatusCreated
+		result.EventID = &id
+		response.Summary.Created++
+	}
+
+	return response, nil
+}
+
+// iCalendarのVEVENTを取り込み対象に変換
+func (s *eventImportService) parseICS(r io.Reader) ([]importRow, error) {
+	events, err := ical.Decode(r, s.jstLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]importRow, 0, len(events))
+	for _, event := range events {
+		if event.Err != nil {
+			rows = append(rows, importRow{
+				event:  &models.CreateEventData{Title: event.Summary},
+				reason: event.Err.Error(),
+			})
+			continue
+		}
+
+		data := &models.CreateEventData{
+			Title:               strings.TrimSpace(event.Summary),
+			Starts_at:           event.Start.UTC(),
+			Has_alarm:           event.Alarm != nil,
+			Notification_timing: defaultNotificationTiming,
+		}
+		if event.End != nil {
+			endsAt := event.End.UTC()
+			data.Ends_at = &endsAt
+		}
+		if event.Description != "" {
+			description := event.Description
+			data.Description = &description
+		}
+		if event.URL != "" {
+			eventURL := event.URL
+			data.URL = &eventURL
+		}
+		if event.Alarm != nil {
+			data.Notification_timing = notificationTimingForBefore(event.Alarm.Before)
+		}
+
+		rows = append(rows, importRow{event: data})
+	}
+
+	return rows, nil
+}
+
+// CSVの各行を取り込み対象に変換（1行目はヘッダー）
+func (s *eventImportService) parseCSV(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("header row is required")
+		}
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		columns[name] = i
+	}
+	for _, required := range []string{csvColumnTitle, csvColumnStartsAt} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("column %q is required", required)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		data, reason := s.csvRecordToEvent(value)
+		rows = append(rows, importRow{event: data, reason: reason})
+	}
+
+	return rows, nil
+}
+
+func (s *eventImportService) csvRecordToEvent(value func(column string) string) (*models.CreateEventData, string) {
+	data := &models.CreateEventData{
+		Title:               value(csvColumnTitle),
+		Has_alarm:           true,
+		Notification_timing: defaultNotificationTiming,
+	}
+
+	if startsAtValue := value(csvColumnStartsAt); startsAtValue != "" {
+		startsAt, err := s.parseCSVDateTime(startsAtValue)
+		if err != nil {
+			return data, "invalid starts_at"
+		}
+		data.Starts_at = startsAt
+	}
+	if endsAtValue := value(csvColumnEndsAt); endsAtValue != "" {
+		endsAt, err := s.parseCSVDateTime(endsAtValue)
+		if err != nil {
+			return data, "invalid ends_at"
+		}
+		data.Ends_at = &endsAt
+	}
+	if description := value(csvColumnDescription); description != "" {
+		data.Description = &description
+	}
+	if eventURL := value(csvColumnURL); eventURL != "" {
+		data.URL = &eventURL
+	}
+	if hasAlarm := value(csvColumnHasAlarm); hasAlarm != "" {
+		b, err := strconv.ParseBool(hasAlarm)
+		if err != nil {
+			return data, "invalid has_alarm"
+		}
+		data.Has_alarm = b
+	}
+	if timing := value(csvColumnNotificationTiming); timing != "" {
+		data.Notification_timing = timing
+	}
+
+	return data, ""
+}
+
+// CSVの日時を解析してUTCにする
+func (s *eventImportService) parseCSVDateTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range csvLocalDateTimeFormats {
+		if t, err := time.ParseInLocation(layout, value, s.jstLocation); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid datetime %q", value)
+}
+
+// 取り込むイベントを検証し、不正な場合は理由を返す
+func validateImportedEvent(event *models.CreateEventData) string {
+	if event == nil {
+		return "invalid row"
+	}
+	if event.Title == "" {
+		return "title is required"
+	}
+	if utf8.RuneCountInString(event.Title) > 255 {
+		return "title is too long"
+	}
+	if event.Starts_at.IsZero() {
+		return "starts_at is required"
+	}
+	if event.Ends_at != nil && event.Ends_at.Before(event.Starts_at) {
+		return "ends_at must not be before starts_at"
+	}
+	if event.URL != nil {
+		parsed, err := url.Parse(*event.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return "invalid url"
+		}
+		if len(*event.URL) > 2048 {
+			return "url is too long"
+		}
+	}
+	if _, ok := notificationTimingDuration(event.Notification_timing); !ok {
+		return "invalid notification_timing"
+	}
+	return ""
+}
+
+// 開始前の通知時間に対応するnotification_timingを返す
+// 一致するものがなければ、指定より早く通知される最も近いタイミングにする
+func notificationTimingForBefore(before time.Duration) string {
+	for _, t := range models.NotificationTimings {
+		if t.Before >= before {
+			return t.Timing
+		}
+	}
+	return models.NotificationTimings[len(models.NotificationTimings)-1].Timing
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEventImportService_ParseCSV(t *testing.T) {
+	s := NewEventImportService(nil).(*eventImportService)
+	input := strings.Join([]string{
+		"title,starts_at,ends_at,description,url,has_alarm,notification_timing",
+		"ワンマンライブ,2026-01-10 14:00,2026-01-10T18:00:00+09:00,当日券あり,https://example.com/live,true,1h",
+		"配信,2026-01-11T20:00:00Z,,,,false,",
+		",2026-01-12 10:00,,,,,",
+		"握手会,2026-13-01,,,,,",
+		"サイン会,2026-01-13 10:00,2026-01-13 09:00,,,,",
+		"リリイベ,2026-01-14 10:00,,,ftp://example.com,,",
+		"生誕祭,2026-01-15 10:00,,,,,3h",
+	}, "\n")
+
+	rows, err := s.parseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("CSVの解析に失敗しました: %v", err)
+	}
+	if len(rows) != 7 {
+		t.Fatalf("行数が一致しません: %d", len(rows))
+	}
+
+	first := rows[0].event
+	if rows[0].reason != "" || validateImportedEvent(first) != "" {
+		t.Fatalf("1行目が不正と判定されました: %q %q", rows[0].reason, validateImportedEvent(first))
+	}
+	if !first.Starts_at.Equal(time.Date(2026, 1, 10, 5, 0, 0, 0, time.UTC)) {
+		t.Errorf("starts_atが日本時間として解釈されていません: %s", first.Starts_at)
+	}
+	if first.Ends_at == nil || !first.Ends_at.Equal(time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("ends_atが一致しません: %v", first.Ends_at)
+	}
+	if !first.Has_alarm || first.Notification_timing != "1h" {
+		t.Errorf("アラーム設定が一致しません: %v %q", first.Has_alarm, first.Notification_timing)
+	}
+
+	second := rows[1].event
+	if second.Has_alarm || second.Notification_timing != defaultNotificationTiming {
+		t.Errorf("2行目のアラーム設定が一致しません: %v %q", second.Has_alarm, second.Notification_timing)
+	}
+
+	expectedReasons := []string{
+		"title is required",
+		"invalid starts_at",
+		"ends_at must not be before starts_at",
+		"invalid url",
+		"invalid notification_timing",
+	}
+	for i, expected := range expectedReasons {
+		row := rows[i+2]
+		reason := row.reason
+		if reason == "" {
+			reason = validateImportedEvent(row.event)
+		}
+		if reason != expected {
+			t.Errorf("%d行目の理由が一致しません\n期待値: %q\n実際値: %q", i+3, expected, reason)
+		}
+	}
+}
+
+func TestEventImportService_ParseCSVMissingColumn(t *testing.T) {
+	s := NewEventImportService(nil).(*eventImportService)
+	if _, err := s.parseCSV(strings.NewReader("title,ends_at\nライブ,2026-01-10 14:00\n")); err == nil {
+		t.Error("starts_at列のないCSVはエラーになるべきです")
+	}
+}
+
+func TestNotificationTimingForBefore(t *testing.T) {
+	tests := []struct {
+		before   time.Duration
+		expected string
+	}{
+		{0, "0"},
+		{15 * time.Minute, "15m"},
+		{20 * time.Minute, "30m"},
+		{3 * time.Hour, "1d"},
+		{30 * 24 * time.Hour, "1w"},
+	}
+
+	for _, tt := range tests {
+		if actual := notificationTimingForBefore(tt.before); actual != tt.expected {
+			t.Errorf("notificationTimingForBefore(%v) = %q, 期待値 %q", tt.before, actual, tt.expected)
+		}
+	}
+}
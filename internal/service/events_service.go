@@ -1,15 +1,31 @@
 package service
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"lovender_backend/internal/models"
 	"lovender_backend/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// イベント一覧の1ページあたりのデフォルト件数と上限
+	defaultEventsPageSize = 100
+	maxEventsPageSize     = 500
 )
 
 type EventsService interface {
-	GetUserOshiEvents(userID int64) (*models.OshiEventsResponse, error)
-	GetEventByID(eventID int64, userID int64) (*models.EventDetailResponse, error)
+	GetUserOshiEvents(userID int64, filter *models.EventFilter, cursor string) (*models.OshiEventsResponse, error)
+	GetEventByID(eventID int64, userID int64, filter *models.EventFilter) (*models.EventDetailResponse, error)
 	UpdateEvent(eventID int64, userID int64, req *models.UpdateEventData) (*models.UpdateEventResponse, error)
 	CreateEvent(userID int64, req *models.CreateEventData) (*models.CreateEventResponse, error)
+	DeleteEvent(eventID int64, userID int64) error
+	RestoreEvent(eventID int64, userID int64) (*models.EventDetailResponse, error)
+	UpdateOccurrence(eventID int64, userID int64, recurrenceID time.Time, req *models.UpdateOccurrenceData) (*models.EventOccurrenceResponse, error)
+	CancelOccurrence(eventID int64, userID int64, recurrenceID time.Time) error
 }
 type eventsService struct {
 	eventsRepo repository.EventsRepository
@@ -19,18 +35,59 @@ func NewEventsService(eventsRepo repository.EventsRepository) EventsService {
 	return &eventsService{eventsRepo: eventsRepo}
 }
 
-func (s *eventsService) GetUserOshiEvents(userID int64) (*models.OshiEventsResponse, error) {
-	events, err := s.eventsRepo.GetOshiEventsByUserID(userID)
+func (s *eventsService) GetUserOshiEvents(userID int64, filter *models.EventFilter, cursor string) (*models.OshiEventsResponse, error) {
+	// 件数の補正
+	if filter.Limit <= 0 {
+		filter.Limit = defaultEventsPageSize
+	}
+	if filter.Limit > maxEventsPageSize {
+		filter.Limit = maxEventsPageSize
+	}
+
+	// カーソルを復元
+	if cursor != "" {
+		startsAt, eventID, err := decodeEventCursor(cursor)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		filter.AfterStartsAt = &startsAt
+		filter.AfterID = eventID
+	}
+
+	events, hasMore, err := s.eventsRepo.GetOshiEventsByUserID(userID, filter)
 	if err != nil {
 		return nil, err
 	}
 
+	// 次ページがある場合はページ内で最後のイベントからカーソルを生成
+	if hasMore {
+		var last *models.Event
+		for i := range events.Oshis {
+			for j := range events.Oshis[i].Events {
+				event := &events.Oshis[i].Events[j]
+				if last == nil || event.Starts_at.After(last.Starts_at) ||
+					(event.Starts_at.Equal(last.Starts_at) && event.ID > last.ID) {
+					last = event
+				}
+			}
+		}
+		if last != nil {
+			nextCursor := encodeEventCursor(last.Starts_at, last.ID)
+			events.NextCursor = &nextCursor
+		}
+	}
+
 	return events, nil
 }
 
-func (s *eventsService) GetEventByID(eventID int64, userID int64) (*models.EventDetailResponse, error) {
+func (s *eventsService) GetEventByID(eventID int64, userID int64, filter *models.EventFilter) (*models.EventDetailResponse, error) {
+	// 繰り返しの回数の補正
+	if filter != nil && filter.Limit > maxEventsPageSize {
+		filter.Limit = maxEventsPageSize
+	}
+
 	// イベント詳細を取得
-	eventDetail, err := s.eventsRepo.GetEventByIDWithOshi(eventID, userID)
+	eventDetail, err := s.eventsRepo.GetEventByIDWithOshi(eventID, userID, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -63,3 +120,79 @@ func (s *eventsService) CreateEvent(userID int64, req *models.CreateEventData) (
 		Event: *createdEvent,
 	}, nil
 }
+
+func (s *eventsService) DeleteEvent(eventID int64, userID int64) error {
+	// イベントを論理削除
+	return s.eventsRepo.DeleteEventByID(eventID, userID)
+}
+
+func (s *eventsService) RestoreEvent(eventID int64, userID int64) (*models.EventDetailResponse, error) {
+	// 論理削除したイベントを復元
+	restoredEvent, err := s.eventsRepo.RestoreEventByID(eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EventDetailResponse{
+		Event: *restoredEvent,
+	}, nil
+}
+
+func (s *eventsService) UpdateOccurrence(eventID int64, userID int64, recurrenceID time.Time, req *models.UpdateOccurrenceData) (*models.EventOccurrenceResponse, error) {
+	// 繰り返しイベントの回を個別に変更
+	occurrence, err := s.eventsRepo.UpsertOccurrenceOverride(eventID, userID, &models.EventOccurrenceOverride{
+		Recurrence_id: recurrenceID,
+		Title:         req.Title,
+		Description:   req.Description,
+		URL:           req.URL,
+		Starts_at:     req.Starts_at,
+		Ends_at:       req.Ends_at,
+		Reset:         req.Reset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EventOccurrenceResponse{
+		Occurrence: *occurrence,
+	}, nil
+}
+
+func (s *eventsService) CancelOccurrence(eventID int64, userID int64, recurrenceID time.Time) error {
+	// 繰り返しイベントの回を中止
+	_, err := s.eventsRepo.UpsertOccurrenceOverride(eventID, userID, &models.EventOccurrenceOverride{
+		Recurrence_id: recurrenceID,
+		Is_cancelled:  true,
+	})
+	return err
+}
+
+// カーソルを生成（開始日時とイベントIDを不透明な文字列にする）
+func encodeEventCursor(startsAt time.Time, eventID int64) string {
+	raw := fmt.Sprintf("%d:%d", startsAt.UnixMilli(), eventID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// カーソルから開始日時とイベントIDを復元
+func decodeEventCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return time.Time{}, 0, fmt.Errorf("malformed cursor")
+	}
+
+	millis, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	eventID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	return time.UnixMilli(millis).UTC(), eventID, nil
+}
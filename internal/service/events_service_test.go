@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventCursor_RoundTrip(t *testing.T) {
+	startsAt := time.Date(2026, 1, 10, 5, 30, 0, 123000000, time.UTC)
+
+	cursor := encodeEventCursor(startsAt, 42)
+	actualStartsAt, actualID, err := decodeEventCursor(cursor)
+	if err != nil {
+		t.Fatalf("カーソルの復元に失敗しました: %v", err)
+	}
+
+	if !actualStartsAt.Equal(startsAt) {
+		t.Errorf("開始日時が一致しません\n期待値: %s\n実際値: %s", startsAt, actualStartsAt)
+	}
+	if actualID != 42 {
+		t.Errorf("イベントIDが一致しません\n期待値: %d\n実際値: %d", 42, actualID)
+	}
+}
+
+func TestEventCursor_Invalid(t *testing.T) {
+	for _, cursor := range []string{"not-base64!!", "MTIz", "YWJjOjE"} {
+		if _, _, err := decodeEventCursor(cursor); err == nil {
+			t.Errorf("不正なカーソル %q はエラーになるべきです", cursor)
+		}
+	}
+}
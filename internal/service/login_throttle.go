@@ -0,0 +1,220 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginAttemptStore ログイン失敗の記録先
+// 複数インスタンスで共有する場合はRedisなどの共有ストアで実装する
+type LoginAttemptStore interface {
+	// since以降の失敗時刻を古い順に返す
+	Failures(key string, since time.Time) ([]time.Time, error)
+	// 失敗を記録する（ttlを過ぎた記録は破棄してよい）
+	AddFailure(key string, at time.Time, ttl time.Duration) error
+	// 失敗の記録を消す
+	Reset(key string) error
+}
+
+// MemoryLoginAttemptStore プロセス内のメモリに記録するストア（単一インスタンス向け）
+type MemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	failures map[string][]time.Time
+	expires  map[string]time.Time
+}
+
+// NewMemoryLoginAttemptStore コンストラクタ
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{
+		failures: make(map[string][]time.Time),
+		expires:  make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryLoginAttemptStore) Failures(key string, since time.Time) ([]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.failures[key]
+	i := sort.Search(len(attempts), func(i int) bool { return !attempts[i].Before(since) })
+	return append([]time.Time(nil), attempts[i:]...), nil
+}
+
+func (s *MemoryLoginAttemptStore) AddFailure(key string, at time.Time, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 期限切れの記録を掃除してマップが増え続けないようにする
+	for k, expiresAt := range s.expires {
+		if !at.Before(expiresAt) {
+			delete(s.failures, k)
+			delete(s.expires, k)
+		}
+	}
+
+	attempts := s.failures[key]
+	since := at.Add(-ttl)
+	i := sort.Search(len(attempts), func(i int) bool { return !attempts[i].Before(since) })
+	s.failures[key] = append(attempts[i:], at)
+	s.expires[key] = at.Add(ttl)
+	return nil
+}
+
+func (s *MemoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+	delete(s.expires, key)
+	return nil
+}
+
+// 失敗回数に応じた待ち時間とロックの設定
+type loginThrottlePolicy struct {
+	freeAttempts int           // 待ち時間なしで失敗できる回数
+	baseDelay    time.Duration // 最初の待ち時間（以降は失敗ごとに2倍）
+	maxDelay     time.Duration // 待ち時間の上限
+	lockoutAfter int           // この回数失敗するとロックする
+	lockout      time.Duration // 最後の失敗からロックを解除するまでの時間
+}
+
+// 次に試行できるまでの時間（0なら試行できる）
+// failuresはwindow内の失敗時刻（古い順）
+func (p loginThrottlePolicy) retryAfter(failures []time.Time, now time.Time) time.Duration {
+	count := len(failures)
+	if count < p.freeAttempts {
+		return 0
+	}
+
+	last := failures[count-1]
+	var wait time.Duration
+	if count >= p.lockoutAfter {
+		wait = p.lockout
+	} else {
+		wait = p.baseDelay << (count - p.freeAttempts)
+		if wait > p.maxDelay || wait <= 0 {
+			wait = p.maxDelay
+		}
+	}
+
+	if remaining := last.Add(wait).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// LoginThrottle メールアドレスと接続元IPごとにログイン失敗を数えて試行を制限する
+type LoginThrottle struct {
+	store       LoginAttemptStore
+	keyPrefix   string // 同じストアを別の用途と共有する場合のキーの接頭辞
+	window      time.Duration
+	emailPolicy loginThrottlePolicy
+	ipPolicy    loginThrottlePolicy
+	now         func() time.Time
+}
+
+// NewLoginThrottle コンストラクタ
+// 同じメールアドレスは15分間に10回、同じIPは50回失敗するとロックする
+// IPは複数のユーザーで共有されることがあるため緩めにする
+func NewLoginThrottle(store LoginAttemptStore) *LoginThrottle {
+	return &LoginThrottle{
+		store:  store,
+		window: 15 * time.Minute,
+		emailPolicy: loginThrottlePolicy{
+			freeAttempts: 3,
+			baseDelay:    time.Second,
+			maxDelay:     time.Minute,
+			lockoutAfter: 10,
+			lockout:      15 * time.Minute,
+		},
+		ipPolicy: loginThrottlePolicy{
+			freeAttempts: 10,
+			baseDelay:    time.Second,
+			maxDelay:     time.Minute,
+			lockoutAfter: 50,
+			lockout:      15 * time.Minute,
+		},
+		now: time.Now,
+	}
+}
+
+// NewPasswordResetThrottle パスワード再設定メールの送信を制限する（試行ごとにRecordFailureで記録する）
+// 同じメールアドレスへは1時間に3回、同じIPからは20回まで送信でき、以降は最後の試行から1時間待つ
+func NewPasswordResetThrottle(store LoginAttemptStore) *LoginThrottle {
+	return &LoginThrottle{
+		store:     store,
+		keyPrefix: "password_reset:",
+		window:    time.Hour,
+		emailPolicy: loginThrottlePolicy{
+			freeAttempts: 3,
+			lockoutAfter: 3,
+			lockout:      time.Hour,
+		},
+		ipPolicy: loginThrottlePolicy{
+			freeAttempts: 20,
+			lockoutAfter: 20,
+			lockout:      time.Hour,
+		},
+		now: time.Now,
+	}
+}
+
+// 試行できない場合はRateLimitErrorを返す
+// ストアに接続できない場合はログインを止めないよう許可する
+func (t *LoginThrottle) Check(email string, ip string) error {
+	now := t.now()
+
+	var retryAfter time.Duration
+	for _, target := range t.targets(email, ip) {
+		failures, err := t.store.Failures(target.key, now.Add(-t.window))
+		if err != nil {
+			log.Printf("LoginThrottle ERROR: failed to get failures for %s: %v", target.key, err)
+			continue
+		}
+		if wait := target.policy.retryAfter(failures, now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// ログイン失敗を記録
+func (t *LoginThrottle) RecordFailure(email string, ip string) {
+	now := t.now()
+	for _, target := range t.targets(email, ip) {
+		if err := t.store.AddFailure(target.key, now, t.window); err != nil {
+			log.Printf("LoginThrottle ERROR: failed to record failure for %s: %v", target.key, err)
+		}
+	}
+}
+
+// ログイン成功時に失敗の記録を消す
+func (t *LoginThrottle) RecordSuccess(email string, ip string) {
+	for _, target := range t.targets(email, ip) {
+		if err := t.store.Reset(target.key); err != nil {
+			log.Printf("LoginThrottle ERROR: failed to reset failures for %s: %v", target.key, err)
+		}
+	}
+}
+
+type loginThrottleTarget struct {
+	key    string
+	policy loginThrottlePolicy
+}
+
+func (t *LoginThrottle) targets(email string, ip string) []loginThrottleTarget {
+	targets := []loginThrottleTarget{
+		{key: t.keyPrefix + "email:" + strings.ToLower(strings.TrimSpace(email)), policy: t.emailPolicy},
+	}
+	if ip != "" {
+		targets = append(targets, loginThrottleTarget{key: t.keyPrefix + "ip:" + ip, policy: t.ipPolicy})
+	}
+	return targets
+}
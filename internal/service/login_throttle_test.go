@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestLoginThrottle(now *time.Time) *LoginThrottle {
+	throttle := NewLoginThrottle(NewMemoryLoginAttemptStore())
+	throttle.now = func() time.Time { return *now }
+	return throttle
+}
+
+func retryAfterOf(t *testing.T, err error) time.Duration {
+	t.Helper()
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("RateLimitErrorになるべきです: %v", err)
+	}
+	return rateLimitErr.RetryAfter
+}
+
+func TestLoginThrottle_ProgressiveDelay(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttle := newTestLoginThrottle(&now)
+
+	// 3回までは待ち時間なし
+	for i := 0; i < 2; i++ {
+		throttle.RecordFailure("user@example.com", "192.0.2.1")
+		if err := throttle.Check("user@example.com", "192.0.2.1"); err != nil {
+			t.Fatalf("%d回目の失敗後は試行できるべきです: %v", i+1, err)
+		}
+	}
+
+	// 3回目以降は1秒、2秒、4秒と待ち時間が増える
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		throttle.RecordFailure("user@example.com", "192.0.2.1")
+		if got := retryAfterOf(t, throttle.Check("user@example.com", "192.0.2.1")); got != want {
+			t.Errorf("%d回目の失敗後の待ち時間: 期待値 %v, 実際 %v", i+3, want, got)
+		}
+		now = now.Add(want)
+		if err := throttle.Check("user@example.com", "192.0.2.1"); err != nil {
+			t.Errorf("待ち時間の経過後は試行できるべきです: %v", err)
+		}
+	}
+
+	// メールアドレスは大文字小文字を区別しない
+	if err := throttle.Check("USER@example.com", "198.51.100.1"); err != nil {
+		t.Errorf("待ち時間の経過後は試行できるべきです: %v", err)
+	}
+	throttle.RecordFailure("USER@example.com", "198.51.100.1")
+	if got := retryAfterOf(t, throttle.Check("user@example.com", "198.51.100.1")); got != 8*time.Second {
+		t.Errorf("大文字のメールアドレスも同じ記録になるべきです: 待ち時間 %v", got)
+	}
+}
+
+func TestLoginThrottle_LockoutAndReset(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttle := newTestLoginThrottle(&now)
+
+	for i := 0; i < 10; i++ {
+		throttle.RecordFailure("user@example.com", "192.0.2.1")
+		now = now.Add(time.Second)
+	}
+
+	// 10回失敗すると最後の失敗から15分ロックされる
+	if got := retryAfterOf(t, throttle.Check("user@example.com", "203.0.113.1")); got != 15*time.Minute-time.Second {
+		t.Errorf("ロック中の待ち時間: 期待値 %v, 実際 %v", 15*time.Minute-time.Second, got)
+	}
+
+	// 別のメールアドレスは影響を受けない
+	if err := throttle.Check("other@example.com", "203.0.113.1"); err != nil {
+		t.Errorf("別のメールアドレスは試行できるべきです: %v", err)
+	}
+
+	// ログインに成功すると記録が消える
+	throttle.RecordSuccess("user@example.com", "192.0.2.1")
+	if err := throttle.Check("user@example.com", "192.0.2.1"); err != nil {
+		t.Errorf("成功後は試行できるべきです: %v", err)
+	}
+
+	// ロックは期間が過ぎると解除される
+	for i := 0; i < 10; i++ {
+		throttle.RecordFailure("user@example.com", "")
+	}
+	now = now.Add(15 * time.Minute)
+	if err := throttle.Check("user@example.com", ""); err != nil {
+		t.Errorf("ロック期間の経過後は試行できるべきです: %v", err)
+	}
+}
+
+func TestPasswordResetThrottle(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryLoginAttemptStore()
+	throttle := NewPasswordResetThrottle(store)
+	throttle.now = func() time.Time { return now }
+
+	// 同じメールアドレスへは3回まで送信できる
+	for i := 0; i < 3; i++ {
+		if err := throttle.Check("user@example.com", "192.0.2.1"); err != nil {
+			t.Fatalf("%d回目は送信できるべきです: %v", i+1, err)
+		}
+		throttle.RecordFailure("user@example.com", "192.0.2.1")
+		now = now.Add(time.Minute)
+	}
+	if got := retryAfterOf(t, throttle.Check("user@example.com", "192.0.2.1")); got != 59*time.Minute {
+		t.Errorf("4回目の待ち時間: 期待値 %v, 実際 %v", 59*time.Minute, got)
+	}
+
+	// 別のメールアドレスは制限されない
+	if err := throttle.Check("other@example.com", "192.0.2.1"); err != nil {
+		t.Errorf("別のメールアドレスは送信できるべきです: %v", err)
+	}
+
+	// 同じストアのログインの記録とは別に数える
+	loginThrottle := NewLoginThrottle(store)
+	loginThrottle.now = func() time.Time { return now }
+	if err := loginThrottle.Check("user@example.com", "192.0.2.1"); err != nil {
+		t.Errorf("パスワード再設定の記録でログインが制限されるべきではありません: %v", err)
+	}
+
+	// 1時間経過すると再び送信できる
+	now = now.Add(time.Hour)
+	if err := throttle.Check("user@example.com", "192.0.2.1"); err != nil {
+		t.Errorf("1時間経過後は送信できるべきです: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"lovender_backend/internal/models"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := t.TempDir()
+	mailer := NewFileMailer(dir, "no-reply@example.com")
+
+	msg := &models.MailMessage{
+		To:      "user@example.com",
+		Subject: "パスワードの再設定",
+		Body:    "1行目\n2行目\n",
+	}
+	if err := mailer.Send(context.Background(), msg); err != nil {
+		t.Fatalf("送信に失敗しました: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("emlファイルが1件作成されていません: %v, %v", files, err)
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("emlファイルを読み込めません: %v", err)
+	}
+	content := string(data)
+
+	for _, want := range []string{
+		"From: no-reply@example.com\r\n",
+		"To: user@example.com\r\n",
+		"Subject: =?UTF-8?b?",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"\r\n\r\n1行目\r\n2行目\r\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("メールに %q が含まれていません:\n%s", want, content)
+		}
+	}
+}
+
+func TestBuildMailBody_StripsHeaderNewlines(t *testing.T) {
+	body := string(buildMailBody("no-reply@example.com", &models.MailMessage{
+		To:      "user@example.com\r\nBcc: attacker@example.com",
+		Subject: "件名",
+		Body:    "本文",
+	}))
+
+	if strings.Contains(body, "\r\nBcc:") {
+		t.Errorf("宛先の改行でヘッダーが追加されています:\n%s", body)
+	}
+}
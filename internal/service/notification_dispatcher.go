@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"log"
+	"lovender_backend/internal/repository"
+	"time"
+)
+
+// 通知ディスパッチャー（通知時刻を迎えたイベントのアラームを送信する）
+type NotificationDispatcher struct {
+	notificationRepo repository.NotificationRepository
+	notifier         Notifier
+	interval         time.Duration
+	grace            time.Duration
+	batchSize        int
+	ctx              context.Context
+	cancel           context.CancelFunc
+}
+
+// コンストラクタ
+func NewNotificationDispatcher(notificationRepo repository.NotificationRepository, notifier Notifier) *NotificationDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &NotificationDispatcher{
+		notificationRepo: notificationRepo,
+		notifier:         notifier,
+		interval:         1 * time.Minute,
+		grace:            10 * time.Minute,
+		batchSize:        100,
+		ctx:              ctx,
+		cancel:           cancel,
+	}
+}
+
+// 定期実行を開始
+func (d *NotificationDispatcher) Start() {
+	go d.run()
+}
+
+// 定期実行のメインループ
+func (d *NotificationDispatcher) run() {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	d.dispatch()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.dispatch()
+		case <-d.ctx.Done():
+			log.Println("Notification dispatcher stopped")
+			return
+		}
+	}
+}
+
+// 通知時刻を迎えたイベントを取得して送信
+func (d *NotificationDispatcher) dispatch() {
+	events, err := d.notificationRepo.GetDueNotificationEvents(time.Now().UTC(), d.grace, d.batchSize)
+	if err != nil {
+		log.Printf("Failed to get due notification events: %v", err)
+		return
+	}
+
+	sent := 0
+	for _, event := range events {
+		if d.ctx.Err() != nil {
+			return
+		}
+
+		// 先に通知済みフラグを立てて送信権を確保する（他インスタンスとの二重送信防止）
+		claimed, err := d.notificationRepo.MarkNotificationSent(event)
+		if err != nil {
+			log.Printf("Failed to claim notification for event %d: %v", event.EventID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(d.ctx, 30*time.Second)
+		err = d.notifier.Notify(ctx, event)
+		cancel()
+		if err != nil {
+			log.Printf("Failed to send notification for event %d: %v", event.EventID, err)
+			// 次回の実行で再送できるようにフラグを戻す
+			if resetErr := d.notificationRepo.ResetNotificationSent(event); resetErr != nil {
+				log.Printf("Failed to reset notification flag for event %d: %v", event.EventID, resetErr)
+			}
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d event notifications", sent)
+	}
+}
+
+// 定期実行を停止
+func (d *NotificationDispatcher) Stop() {
+	log.Println("Stopping notification dispatcher...")
+
+	if d.cancel != nil {
+		d.cancel()
+	}
+}
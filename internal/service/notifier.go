@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"lovender_backend/internal/models"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Notifier イベント通知の送信先
+type Notifier interface {
+	Notify(ctx context.Context, event *models.NotificationEvent) error
+}
+
+// LogNotifier 通知内容をログに出力するだけの通知先（ローカル開発用）
+type LogNotifier struct{}
+
+// NewLogNotifier コンストラクタ
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, event *models.NotificationEvent) error {
+	log.Printf("Notification: user=%d oshi=%q event=%d title=%q starts_at=%s timing=%s",
+		event.UserID, event.OshiName, event.EventID, event.Title,
+		event.StartsAt.Format(time.RFC3339), event.NotificationTiming)
+	return nil
+}
+
+// WebhookNotifier 通知内容をJSONでWebhookにPOSTする通知先
+type WebhookNotifier struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier コンストラクタ
+// secretが空でない場合はリクエストボディのHMAC-SHA256署名をX-Lovender-Signatureヘッダーに付与する
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		secret: secret,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, event *models.NotificationEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(body)
+		req.Header.Set("X-Lovender-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// 環境変数から通知先を生成
+// NOTIFICATION_WEBHOOK_URL が設定されていればWebhook、なければログ出力
+func NewNotifierFromEnv() Notifier {
+	if url := os.Getenv("NOTIFICATION_WEBHOOK_URL"); url != "" {
+		return NewWebhookNotifier(url, os.Getenv("NOTIFICATION_WEBHOOK_SECRET"))
+	}
+	return NewLogNotifier()
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"lovender_backend/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_Notify(t *testing.T) {
+	const secret = "test-secret"
+
+	var received models.NotificationEvent
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature = r.Header.Get("X-Lovender-Signature")
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if signature != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.Unmarshal(body, &received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	event := &models.NotificationEvent{
+		EventID:            42,
+		Title:              "ライブ",
+		StartsAt:           time.Date(2026, 1, 10, 5, 0, 0, 0, time.UTC),
+		NotificationTiming: "15m",
+		NotifyAt:           time.Date(2026, 1, 10, 4, 45, 0, 0, time.UTC),
+		OshiID:             1,
+		OshiName:           "山田美咲",
+		UserID:             1,
+	}
+
+	notifier := NewWebhookNotifier(server.URL, secret)
+	if err := notifier.Notify(context.Background(), event); err != nil {
+		t.Fatalf("通知の送信に失敗しました: %v", err)
+	}
+
+	if received.EventID != event.EventID || received.Title != event.Title {
+		t.Errorf("Webhookで受信した内容が一致しません: %+v", received)
+	}
+	if !received.StartsAt.Equal(event.StartsAt) {
+		t.Errorf("開始時刻が一致しません\n期待値: %s\n実際値: %s", event.StartsAt, received.StartsAt)
+	}
+}
+
+func TestWebhookNotifier_NotifyErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, "")
+	err := notifier.Notify(context.Background(), &models.NotificationEvent{EventID: 1})
+	if err == nil {
+		t.Fatal("5xxレスポンスの場合はエラーになるべきです")
+	}
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/repository"
+	"lovender_backend/pkg/crypto"
+	"lovender_backend/pkg/oidc"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ログイン開始からコールバックまでの有効期間
+const oidcLoginStateTTL = 10 * time.Minute
+
+// 既知のプロバイダーのIssuer（OIDC_<NAME>_ISSUER 未設定時に使う）
+var defaultOIDCIssuers = map[string]string{
+	"google": "https://accounts.google.com",
+	"line":   "https://access.line.me",
+}
+
+// OIDCService OpenID Connectによるソーシャルログイン
+type OIDCService interface {
+	Providers() []string
+	Authorize(ctx context.Context, provider string) (*models.OIDCAuthorizeResponse, error)
+	Callback(ctx context.Context, provider string, req *models.OIDCCallbackRequest, client models.ClientInfo) (*models.LoginResponse, error)
+}
+
+type oidcService struct {
+	providers      map[string]*oidc.Provider
+	oidcRepo       repository.OIDCRepository
+	userRepo       repository.UserRepository
+	sessionService SessionService
+}
+
+func NewOIDCService(providers map[string]*oidc.Provider, oidcRepo repository.OIDCRepository, userRepo repository.UserRepository, sessionService SessionService) OIDCService {
+	return &oidcService{
+		providers:      providers,
+		oidcRepo:       oidcRepo,
+		userRepo:       userRepo,
+		sessionService: sessionService,
+	}
+}
+
+// 利用できるプロバイダー名（名前順）
+func (s *oidcService) Providers() []string {
+	return oidcProviderNames(s.providers)
+}
+
+// state・nonce・code_verifierを発行して認可リクエストのURLを返す
+func (s *oidcService) Authorize(ctx context.Context, provider string) (*models.OIDCAuthorizeResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, errors.New("provider not found")
+	}
+
+	state, err := crypto.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := crypto.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := crypto.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.oidcRepo.CreateLoginState(&models.OIDCLoginState{
+		Provider:     provider,
+		StateHash:    crypto.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(oidcLoginStateTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCAuthorizeResponse{AuthorizationURL: authURL}, nil
+}
+
+// 認可コードを検証してログインする
+// 未登録のアカウントは確認済みのメールアドレスで既存ユーザーに紐付け、該当がなければ新規作成する
+func (s *oidcService) Callback(ctx context.Context, provider string, req *models.OIDCCallbackRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, errors.New("provider not found")
+	}
+
+	state, err := s.oidcRepo.ConsumeLoginState(crypto.HashToken(req.State), provider)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := p.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("OIDC login failed for provider %s: %v", provider, err)
+		return nil, errors.New("oidc authentication failed")
+	}
+
+	userID, err := s.resolveUserID(provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	// 退会の猶予期間中にログインした場合は退会を取り消す
+	if user.DeletionRequestedAt != nil {
+		if err := s.userRepo.CancelDeletion(user.ID); err != nil {
+			return nil, err
+		}
+		log.Printf("Account deletion cancelled by login for user %d", user.ID)
+	}
+
+	// セッションを作成してトークン生成（パスワードログインと同じトークン）
+	tokens, err := s.sessionService.IssueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		AuthTokens: *tokens,
+	}, nil
+}
+
+// プロバイダーのアカウントに対応するユーザーIDを決める
+func (s *oidcService) resolveUserID(provider string, claims *oidc.Claims) (int64, error) {
+	userID, err := s.oidcRepo.GetUserIDByIdentity(provider, claims.Subject)
+	if err != nil {
+		return 0, err
+	}
+	if userID != 0 {
+		return userID, nil
+	}
+
+	// 未確認のメールアドレスで紐付けると他人のアカウントを乗っ取れるため拒否する
+	if claims.Email == "" || !claims.EmailVerified {
+		return 0, errors.New("verified email required")
+	}
+
+	user, err := s.userRepo.GetByEmail(claims.Email)
+	if err != nil {
+		return 0, err
+	}
+
+	switch oidcLinkModeFor(user) {
+	case oidcLinkVerifiedUser:
+		err = s.oidcRepo.LinkIdentity(user.ID, provider, claims.Subject, claims.Email)
+		if err == nil {
+			log.Printf("Linked %s identity to user %d", provider, user.ID)
+			return user.ID, nil
+		}
+	case oidcLinkUnverifiedUser:
+		// メールアドレスを確認していない登録は第三者による先回りの可能性があるため、
+		// パスワードを使えない値にし、既存のセッションと個人用アクセストークンを失効させてから紐付ける
+		var hashedPassword string
+		hashedPassword, err = unusablePasswordHash()
+		if err != nil {
+			return 0, err
+		}
+		err = s.oidcRepo.LinkIdentityResettingCredentials(user.ID, provider, claims.Subject, claims.Email, hashedPassword)
+		if err == nil {
+			log.Printf("AUDIT: linked %s identity to unverified user %d; password reset and all sessions and tokens revoked", provider, user.ID)
+			return user.ID, nil
+		}
+	default:
+		var hashedPassword string
+		hashedPassword, err = unusablePasswordHash()
+		if err != nil {
+			return 0, err
+		}
+
+		user = &models.User{
+			Name:         oidcDisplayName(claims),
+			Email:        claims.Email,
+			PasswordHash: hashedPassword,
+		}
+		err = s.oidcRepo.CreateUserWithIdentity(user, provider, claims.Subject)
+		if err == nil {
+			log.Printf("Created user %d from %s identity", user.ID, provider)
+			return user.ID, nil
+		}
+	}
+
+	// 同じアカウントで同時にログインした場合は先に紐付けた方を使う
+	if isDuplicateKeyError(err) {
+		if userID, lookupErr := s.oidcRepo.GetUserIDByIdentity(provider, claims.Subject); lookupErr == nil && userID != 0 {
+			return userID, nil
+		}
+	}
+	return 0, err
+}
+
+// 確認済みのメールアドレスで見つかったユーザーとの紐付け方
+type oidcLinkMode int
+
+const (
+	oidcCreateUser         oidcLinkMode = iota // 該当するユーザーがいないため新規作成する
+	oidcLinkVerifiedUser                       // メールアドレスを確認済みのユーザーにそのまま紐付ける
+	oidcLinkUnverifiedUser                     // メールアドレスが未確認のユーザーに、認証情報を失効させてから紐付ける
+)
+
+func oidcLinkModeFor(user *models.User) oidcLinkMode {
+	switch {
+	case user == nil:
+		return oidcCreateUser
+	case user.EmailVerifiedAt != nil:
+		return oidcLinkVerifiedUser
+	default:
+		return oidcLinkUnverifiedUser
+	}
+}
+
+// 使えないパスワードのハッシュ（パスワード再設定で設定できる）
+func unusablePasswordHash() (string, error) {
+	randomPassword, err := crypto.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return crypto.HashPassword(randomPassword)
+}
+
+// 表示名（プロバイダーの名前がなければメールアドレスの@より前）
+func oidcDisplayName(claims *oidc.Claims) string {
+	if name := strings.TrimSpace(claims.Name); name != "" {
+		return truncateRunes(name, 191)
+	}
+	if i := strings.Index(claims.Email, "@"); i > 0 {
+		return truncateRunes(claims.Email[:i], 191)
+	}
+	return claims.Email
+}
+
+// 環境変数からプロバイダーを生成
+// OIDC_PROVIDERS にカンマ区切りで名前を指定し、名前ごとに OIDC_<NAME>_CLIENT_ID などを設定する
+func NewOIDCProvidersFromEnv() map[string]*oidc.Provider {
+	providers := make(map[string]*oidc.Provider)
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		issuer := os.Getenv(prefix + "ISSUER")
+		if issuer == "" {
+			issuer = defaultOIDCIssuers[name]
+		}
+		config := oidc.Config{
+			Issuer:       issuer,
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+			TrustEmail:   os.Getenv(prefix+"TRUST_EMAIL") == "true",
+		}
+		if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+			log.Printf("WARNING: OIDC provider %q is missing %sISSUER, %sCLIENT_ID or %sREDIRECT_URL; skipped", name, prefix, prefix, prefix)
+			continue
+		}
+
+		providers[name] = oidc.NewProvider(config)
+	}
+
+	if len(providers) > 0 {
+		log.Printf("OIDC providers enabled: %s", strings.Join(oidcProviderNames(providers), ", "))
+	}
+	return providers
+}
+
+func oidcProviderNames(providers map[string]*oidc.Provider) []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
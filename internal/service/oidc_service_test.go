@@ -0,0 +1,72 @@
+package service
+
+import (
+	"lovender_backend/internal/models"
+	"lovender_backend/pkg/oidc"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewOIDCProvidersFromEnv(t *testing.T) {
+	t.Setenv("OIDC_PROVIDERS", "Google, line, keycloak, x")
+	t.Setenv("OIDC_GOOGLE_CLIENT_ID", "google-client")
+	t.Setenv("OIDC_GOOGLE_REDIRECT_URL", "https://app.example.com/oidc/google")
+	t.Setenv("OIDC_LINE_CLIENT_ID", "line-client")
+	t.Setenv("OIDC_LINE_REDIRECT_URL", "https://app.example.com/oidc/line")
+	t.Setenv("OIDC_KEYCLOAK_ISSUER", "https://id.example.com/realms/lovender")
+	t.Setenv("OIDC_KEYCLOAK_CLIENT_ID", "keycloak-client")
+	t.Setenv("OIDC_KEYCLOAK_REDIRECT_URL", "https://app.example.com/oidc/keycloak")
+	// xはIssuerの既定値がなく、設定もないため無効
+	t.Setenv("OIDC_X_CLIENT_ID", "x-client")
+	t.Setenv("OIDC_X_REDIRECT_URL", "https://app.example.com/oidc/x")
+
+	providers := NewOIDCProvidersFromEnv()
+
+	want := []string{"google", "keycloak", "line"}
+	if got := oidcProviderNames(providers); !reflect.DeepEqual(got, want) {
+		t.Errorf("プロバイダー: 期待値 %v, 実際 %v", want, got)
+	}
+}
+
+func TestOIDCLinkModeFor(t *testing.T) {
+	verifiedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		user *models.User
+		want oidcLinkMode
+	}{
+		{"該当するユーザーがいない場合は新規作成", nil, oidcCreateUser},
+		{"メールアドレスを確認済みのユーザーはそのまま紐付け", &models.User{ID: 1, Email: "victim@example.com", EmailVerifiedAt: &verifiedAt}, oidcLinkVerifiedUser},
+		// 第三者が未確認のまま先に登録したアカウントは、パスワードとセッションを引き継がせない
+		{"メールアドレスが未確認のユーザーは認証情報を失効させて紐付け", &models.User{ID: 1, Email: "victim@example.com"}, oidcLinkUnverifiedUser},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := oidcLinkModeFor(tt.user); got != tt.want {
+				t.Errorf("期待値 %v, 実際 %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOIDCDisplayName(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims oidc.Claims
+		want   string
+	}{
+		{"名前がある場合は名前", oidc.Claims{Name: " 田中太郎 ", Email: "tanaka@example.com"}, "田中太郎"},
+		{"名前がない場合はメールアドレスの@より前", oidc.Claims{Email: "tanaka@example.com"}, "tanaka"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := oidcDisplayName(&tt.claims); got != tt.want {
+				t.Errorf("期待値 %q, 実際 %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -30,10 +30,7 @@ func (s *oshiGetService) GetOshiByID(oshiID int64, userID int64) (*models.GetOsh
 	}
 
 	// URL一覧を配列に変換
-	var urls []string
-	for _, account := range oshiWithDetails.Accounts {
-		urls = append(urls, account.URL)
-	}
+	urls, accounts := toOshiAccountResponses(oshiWithDetails.Accounts)
 
 	// カテゴリ一覧を配列に変換
 	var categorySlugs []string
@@ -48,6 +45,7 @@ func (s *oshiGetService) GetOshiByID(oshiID int64, userID int64) (*models.GetOsh
 			Name:       oshiWithDetails.Oshi.Name,
 			Color:      oshiWithDetails.Oshi.ThemeColor,
 			URLs:       urls,
+			Accounts:   accounts,
 			Categories: categorySlugs,
 		},
 	}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"lovender_backend/internal/repository"
+)
+
+// 削除の取り消し期間を過ぎた推しを定期的に削除する
+type OshiPurger struct {
+	*batchPurger
+}
+
+// コンストラクタ
+func NewOshiPurger(oshiRepo repository.OshiRepository) *OshiPurger {
+	return &OshiPurger{
+		batchPurger: newBatchPurger("Oshi purger", "deleted oshis", oshiRepo.PurgeDeleted, oshiDeletionUndoWindow),
+	}
+}
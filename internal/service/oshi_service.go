@@ -1,10 +1,13 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"lovender_backend/internal/models"
 	"lovender_backend/internal/repository"
+	"lovender_backend/pkg/socialaccount"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -14,8 +17,22 @@ type OshiService interface {
 	GetUserOshis(userID int64) (*models.OshisResponse, error)
 	CreateOshi(userID int64, req *models.CreateOshiRequest) (*models.CreateOshiResponse, error)
 	UpdateOshi(oshiID int64, userID int64, req *models.UpdateOshiRequest) (*models.UpdateOshiResponse, error)
+	UpdateAutoEventMode(oshiID int64, userID int64, req *models.UpdateAutoEventModeRequest) (*models.UpdateAutoEventModeResponse, error)
+	DeleteOshi(oshiID int64, userID int64, dryRun bool) (*models.DeleteOshiResponse, error)
+	RestoreOshi(oshiID int64, userID int64) (*models.OshiDetailResponse, error)
+	PatchOshi(oshiID int64, userID int64, body []byte) (*models.OshiDetailResponse, error)
+	AddAccount(oshiID int64, userID int64, url string) (*models.OshiDetailResponse, error)
+	RemoveAccount(oshiID int64, userID int64, url string) (*models.OshiDetailResponse, error)
+	AddCategory(oshiID int64, userID int64, slug string) (*models.OshiDetailResponse, error)
+	RemoveCategory(oshiID int64, userID int64, slug string) (*models.OshiDetailResponse, error)
 }
 
+// テーマカラー（#RRGGBB）
+var oshiColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// 推しの削除を取り消せる期間（過ぎるとイベントなどを含めて削除される）
+const oshiDeletionUndoWindow = 30 * 24 * time.Hour
+
 type oshiService struct {
 	oshiRepo repository.OshiRepository
 }
@@ -39,28 +56,7 @@ func (s *oshiService) GetUserOshis(userID int64) (*models.OshisResponse, error)
 
 	var oshiResponses []models.OshiResponse
 	for _, detail := range oshisWithDetails {
-		// URL一覧を配列に変換
-		var urls []string
-		for _, account := range detail.Accounts {
-			urls = append(urls, account.URL)
-		}
-
-		// カテゴリ一覧を配列に変換
-		var categorySlugs []string
-		for _, category := range detail.Categories {
-			categorySlugs = append(categorySlugs, category.Slug)
-		}
-
-		// レスポンス用の構造体に変換
-		oshiResponse := models.OshiResponse{
-			ID:         detail.Oshi.ID,
-			Name:       detail.Oshi.Name,
-			Color:      detail.Oshi.ThemeColor,
-			URLs:       urls,
-			Categories: categorySlugs,
-		}
-
-		oshiResponses = append(oshiResponses, oshiResponse)
+		oshiResponses = append(oshiResponses, toOshiResponse(detail))
 	}
 
 	return &models.OshisResponse{
@@ -68,6 +64,28 @@ func (s *oshiService) GetUserOshis(userID int64) (*models.OshisResponse, error)
 	}, nil
 }
 
+// レスポンス用の構造体に変換
+func toOshiResponse(detail *models.OshiWithDetails) models.OshiResponse {
+	// URL一覧を配列に変換
+	urls, accounts := toOshiAccountResponses(detail.Accounts)
+
+	// カテゴリ一覧を配列に変換
+	var categorySlugs []string
+	for _, category := range detail.Categories {
+		categorySlugs = append(categorySlugs, category.Slug)
+	}
+
+	return models.OshiResponse{
+		ID:            detail.Oshi.ID,
+		Name:          detail.Oshi.Name,
+		Color:         detail.Oshi.ThemeColor,
+		URLs:          urls,
+		Accounts:      accounts,
+		Categories:    categorySlugs,
+		AutoEventMode: detail.Oshi.AutoEventMode,
+	}
+}
+
 // 推しの新規作成
 func (s *oshiService) CreateOshi(userID int64, req *models.CreateOshiRequest) (*models.CreateOshiResponse, error) {
 	// 推し情報の作成
@@ -81,8 +99,14 @@ func (s *oshiService) CreateOshi(userID int64, req *models.CreateOshiRequest) (*
 		UpdatedAt:   time.Now(),
 	}
 
+	// URLを解析してプラットフォームとハンドルを取り出す
+	accounts, err := normalizeOshiAccounts(req.URLs)
+	if err != nil {
+		return nil, err
+	}
+
 	// 推し、アカウント、カテゴリ作成
-	oshiID, err := s.oshiRepo.CreateOshiWithTransaction(oshi, req.URLs, req.Categories)
+	oshiID, err := s.oshiRepo.CreateOshiWithTransaction(oshi, accounts, req.Categories)
 	if err != nil {
 		// データベース制約違反をキャッチ
 		if isDuplicateKeyError(err) {
@@ -95,13 +119,15 @@ func (s *oshiService) CreateOshi(userID int64, req *models.CreateOshiRequest) (*
 		return nil, err
 	}
 
-	// レスポンスの整形
+	// レスポンスの整形（URLは正規化したものを返す）
+	urls, accountResponses := toOshiAccountResponses(accounts)
 	resp := &models.CreateOshiResponse{
 		Oshi: models.CreateOshiResponseItem{
 			ID:         oshiID,
 			Name:       req.Name,
 			Color:      req.Color,
-			URLs:       req.URLs,
+			URLs:       urls,
+			Accounts:   accountResponses,
 			Categories: req.Categories,
 		},
 	}
@@ -121,13 +147,23 @@ func (s *oshiService) UpdateOshi(oshiID int64, userID int64, req *models.UpdateO
 		UpdatedAt:   time.Now(),
 	}
 
+	// URLを解析してプラットフォームとハンドルを取り出す
+	accounts, err := normalizeOshiAccounts(req.URLs)
+	if err != nil {
+		return nil, err
+	}
+
 	// 推し情報を更新
-	err := s.oshiRepo.UpdateOshiWithTransaction(oshiID, userID, oshi, req.URLs, req.Categories)
+	err = s.oshiRepo.UpdateOshiWithTransaction(oshiID, userID, oshi, accounts, req.Categories)
 	if err != nil {
 		// 推しが見つからないまたは所有者でない場合
 		if strings.Contains(err.Error(), "oshi not found or not owned by user") {
 			return nil, errors.New("oshi not found")
 		}
+		// 同じ名前の推しがある場合
+		if isDuplicateKeyError(err) {
+			return nil, errors.New("oshi already exists")
+		}
 		// カテゴリ不正エラーをキャッチ
 		if strings.Contains(err.Error(), "invalid categories") {
 			return nil, errors.New("invalid categories provided")
@@ -142,10 +178,7 @@ func (s *oshiService) UpdateOshi(oshiID int64, userID int64, req *models.UpdateO
 	}
 
 	// URL一覧を配列に変換
-	var urls []string
-	for _, account := range updatedOshi.Accounts {
-		urls = append(urls, account.URL)
-	}
+	urls, accountResponses := toOshiAccountResponses(updatedOshi.Accounts)
 
 	// カテゴリ一覧を配列に変換
 	var categorySlugs []string
@@ -160,6 +193,7 @@ func (s *oshiService) UpdateOshi(oshiID int64, userID int64, req *models.UpdateO
 			Name:       updatedOshi.Oshi.Name,
 			Color:      updatedOshi.Oshi.ThemeColor,
 			URLs:       urls,
+			Accounts:   accountResponses,
 			Categories: categorySlugs,
 		},
 	}
@@ -167,6 +201,280 @@ func (s *oshiService) UpdateOshi(oshiID int64, userID int64, req *models.UpdateO
 	return resp, nil
 }
 
+// 自動検出イベントの扱いを更新
+func (s *oshiService) UpdateAutoEventMode(oshiID int64, userID int64, req *models.UpdateAutoEventModeRequest) (*models.UpdateAutoEventModeResponse, error) {
+	if req.AutoEventMode != models.AutoEventModePublish && req.AutoEventMode != models.AutoEventModeReview {
+		return nil, errors.New("invalid auto event mode")
+	}
+
+	if err := s.oshiRepo.UpdateAutoEventMode(oshiID, userID, req.AutoEventMode); err != nil {
+		return nil, err
+	}
+
+	return &models.UpdateAutoEventModeResponse{
+		OshiID:        oshiID,
+		AutoEventMode: req.AutoEventMode,
+	}, nil
+}
+
+// 推しを削除（dryRunの場合は一緒に削除される件数だけ返す）
+// 削除した推しは取り消し期間内なら復元でき、期間を過ぎるとOshiPurgerが削除する
+func (s *oshiService) DeleteOshi(oshiID int64, userID int64, dryRun bool) (*models.DeleteOshiResponse, error) {
+	if dryRun {
+		counts, err := s.oshiRepo.GetDeletionCounts(oshiID, userID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.DeleteOshiResponse{
+			OshiID: oshiID,
+			DryRun: true,
+			Counts: *counts,
+		}, nil
+	}
+
+	counts, deletedAt, err := s.oshiRepo.DeleteOshi(oshiID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	restorableUntil := deletedAt.Add(oshiDeletionUndoWindow)
+	return &models.DeleteOshiResponse{
+		OshiID:          oshiID,
+		Counts:          *counts,
+		RestorableUntil: &restorableUntil,
+	}, nil
+}
+
+// 取り消し期間内に削除した推しを復元
+func (s *oshiService) RestoreOshi(oshiID int64, userID int64) (*models.OshiDetailResponse, error) {
+	if err := s.oshiRepo.RestoreOshi(oshiID, userID, time.Now().UTC().Add(-oshiDeletionUndoWindow)); err != nil {
+		return nil, err
+	}
+
+	return s.getOshiDetail(oshiID, userID)
+}
+
+// JSON Merge Patch（RFC 7396）で推しを部分更新
+// 指定した項目のみ変更し、urls・categoriesは差分のみ追加・削除する（nullは空にする）
+func (s *oshiService) PatchOshi(oshiID int64, userID int64, body []byte) (*models.OshiDetailResponse, error) {
+	patch, err := parseOshiMergePatch(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.oshiRepo.PatchOshi(oshiID, userID, patch); err != nil {
+		return nil, toOshiUpdateError(err)
+	}
+
+	return s.getOshiDetail(oshiID, userID)
+}
+
+// アカウントを1件追加
+func (s *oshiService) AddAccount(oshiID int64, userID int64, url string) (*models.OshiDetailResponse, error) {
+	if strings.TrimSpace(url) == "" {
+		return nil, errors.New("url is required")
+	}
+
+	accounts, err := normalizeOshiAccounts([]string{url})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.oshiRepo.AddAccount(oshiID, userID, accounts[0]); err != nil {
+		return nil, toOshiUpdateError(err)
+	}
+
+	return s.getOshiDetail(oshiID, userID)
+}
+
+// アカウントを1件削除（登録時と同じく正規化したURLで探す）
+func (s *oshiService) RemoveAccount(oshiID int64, userID int64, url string) (*models.OshiDetailResponse, error) {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return nil, errors.New("url is required")
+	}
+	if account, err := socialaccount.Parse(url); err == nil {
+		url = account.URL
+	}
+
+	if err := s.oshiRepo.RemoveAccount(oshiID, userID, url); err != nil {
+		return nil, toOshiUpdateError(err)
+	}
+
+	return s.getOshiDetail(oshiID, userID)
+}
+
+// カテゴリを1件追加
+func (s *oshiService) AddCategory(oshiID int64, userID int64, slug string) (*models.OshiDetailResponse, error) {
+	if err := s.oshiRepo.AddCategory(oshiID, userID, slug); err != nil {
+		return nil, toOshiUpdateError(err)
+	}
+
+	return s.getOshiDetail(oshiID, userID)
+}
+
+// カテゴリを1件削除
+func (s *oshiService) RemoveCategory(oshiID int64, userID int64, slug string) (*models.OshiDetailResponse, error) {
+	if err := s.oshiRepo.RemoveCategory(oshiID, userID, slug); err != nil {
+		return nil, toOshiUpdateError(err)
+	}
+
+	return s.getOshiDetail(oshiID, userID)
+}
+
+// 更新時のDBエラーをサービスのエラーに変換
+func toOshiUpdateError(err error) error {
+	if isDuplicateKeyError(err) {
+		return errors.New("oshi already exists")
+	}
+	if strings.Contains(err.Error(), "invalid categories") {
+		return errors.New("invalid categories provided")
+	}
+	return err
+}
+
+// 更新後の推しを取得
+func (s *oshiService) getOshiDetail(oshiID int64, userID int64) (*models.OshiDetailResponse, error) {
+	detail, err := s.oshiRepo.GetOshiByIDAndUserID(oshiID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updated oshi: %w", err)
+	}
+
+	return &models.OshiDetailResponse{
+		Oshi: toOshiResponse(detail),
+	}, nil
+}
+
+// JSON Merge Patchで指定できる項目
+var oshiMergePatchFields = map[string]bool{
+	"name":            true,
+	"color":           true,
+	"auto_event_mode": true,
+	"urls":            true,
+	"categories":      true,
+}
+
+// JSON Merge Patchのボディを解析（未知の項目はエラーにする）
+func parseOshiMergePatch(body []byte) (*models.OshiPatch, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return nil, errors.New("invalid request body")
+	}
+
+	// 綴りの誤りなどで更新されないまま成功しないよう、値の検証より先に確認する
+	unknown := make([]string, 0)
+	for key := range fields {
+		if !oshiMergePatchFields[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown field: %s", strings.Join(unknown, ", "))
+	}
+
+	patch := &models.OshiPatch{}
+	for key, raw := range fields {
+		isNull := string(raw) == "null"
+
+		switch key {
+		case "name":
+			var name string
+			if isNull || json.Unmarshal(raw, &name) != nil || strings.TrimSpace(name) == "" {
+				return nil, errors.New("name is required")
+			}
+			name = strings.TrimSpace(name)
+			patch.Name = &name
+		case "color":
+			var color string
+			if isNull || json.Unmarshal(raw, &color) != nil || !oshiColorPattern.MatchString(color) {
+				return nil, errors.New("invalid color")
+			}
+			patch.Color = &color
+		case "auto_event_mode":
+			var mode string
+			if isNull || json.Unmarshal(raw, &mode) != nil || (mode != models.AutoEventModePublish && mode != models.AutoEventModeReview) {
+				return nil, errors.New("invalid auto event mode")
+			}
+			patch.AutoEventMode = &mode
+		case "urls", "categories":
+			values := []string{}
+			if !isNull {
+				if err := json.Unmarshal(raw, &values); err != nil {
+					return nil, errors.New("invalid request body")
+				}
+			}
+			if key == "urls" {
+				accounts, err := normalizeOshiAccounts(values)
+				if err != nil {
+					return nil, err
+				}
+				patch.Accounts = &accounts
+				continue
+			}
+			trimmed := make([]string, 0, len(values))
+			for _, value := range values {
+				if value = strings.TrimSpace(value); value != "" {
+					trimmed = append(trimmed, value)
+				}
+			}
+			patch.Categories = &trimmed
+		}
+	}
+
+	return patch, nil
+}
+
+// URLを解析してアカウントに変換（空のURLは無視し、正規化後に同じになるURLは1件にまとめる）
+func normalizeOshiAccounts(urls []string) ([]*models.OshiAccount, error) {
+	accounts := make([]*models.OshiAccount, 0, len(urls))
+	seen := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		if strings.TrimSpace(url) == "" {
+			continue
+		}
+		parsed, err := socialaccount.Parse(url)
+		if err != nil {
+			return nil, errors.New("invalid account url")
+		}
+		// DBの照合順序は大文字・小文字を区別しないため、同じURLとして扱う
+		key := strings.ToLower(parsed.URL)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		account := &models.OshiAccount{
+			URL:      parsed.URL,
+			Platform: string(parsed.Platform),
+		}
+		if parsed.Handle != "" {
+			handle := parsed.Handle
+			account.Handle = &handle
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// アカウントをレスポンス用に変換（urlsは互換性のため残す）
+func toOshiAccountResponses(accounts []*models.OshiAccount) ([]string, []models.OshiAccountResponse) {
+	urls := make([]string, 0, len(accounts))
+	responses := make([]models.OshiAccountResponse, 0, len(accounts))
+	for _, account := range accounts {
+		urls = append(urls, account.URL)
+		response := models.OshiAccountResponse{
+			URL:      account.URL,
+			Platform: account.Platform,
+		}
+		if account.Handle != nil {
+			response.Handle = *account.Handle
+		}
+		responses = append(responses, response)
+	}
+	return urls, responses
+}
+
 // DB制約違反チェック
 func isDuplicateKeyError(err error) bool {
 	errMsg := err.Error()
@@ -0,0 +1,45 @@
+package service
+
+import "testing"
+
+func TestParseOshiMergePatch(t *testing.T) {
+	patch, err := parseOshiMergePatch([]byte(`{"name": " 推し ", "urls": [" https://twitter.com/a/ ", "", "https://x.com/A"], "categories": null}`))
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if patch.Name == nil || *patch.Name != "推し" {
+		t.Errorf("nameが前後の空白を除いて設定されていません: %v", patch.Name)
+	}
+	if patch.Color != nil || patch.AutoEventMode != nil {
+		t.Errorf("指定していない項目は変更しないべきです")
+	}
+	if patch.Accounts == nil || len(*patch.Accounts) != 1 {
+		t.Fatalf("urlsは正規化して重複を除くべきです: %v", patch.Accounts)
+	}
+	if account := (*patch.Accounts)[0]; account.URL != "https://x.com/a" || account.Platform != "x" || account.Handle == nil || *account.Handle != "a" {
+		t.Errorf("urls: 期待値 {https://x.com/a x a}, 実際 %+v", account)
+	}
+	if patch.Categories == nil || len(*patch.Categories) != 0 {
+		t.Errorf("categoriesにnullを指定した場合は空にするべきです: %v", patch.Categories)
+	}
+
+	tests := map[string]string{
+		`[]`:                               "invalid request body",
+		`null`:                             "invalid request body",
+		`{"name": null}`:                   "name is required",
+		`{"name": "  "}`:                   "name is required",
+		`{"color": "red"}`:                 "invalid color",
+		`{"color": null}`:                  "invalid color",
+		`{"auto_event_mode": "manual"}`:    "invalid auto event mode",
+		`{"urls": "https://x.com/a"}`:      "invalid request body",
+		`{"urls": ["https://x.com/home"]}`: "invalid account url",
+		`{"colour": "#FF6699"}`:            "unknown field: colour",
+		`{"accounts": [], "name": "推し"}`:   "unknown field: accounts",
+		`{"name": null, "b": 1, "a": 2}`:   "unknown field: a, b",
+	}
+	for body, want := range tests {
+		if _, err := parseOshiMergePatch([]byte(body)); err == nil || err.Error() != want {
+			t.Errorf("%s: 期待値 %q, 実際 %v", body, want, err)
+		}
+	}
+}
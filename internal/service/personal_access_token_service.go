@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/repository"
+	"lovender_backend/pkg/crypto"
+	"lovender_backend/pkg/jwtutil"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// トークンのランダム部分のバイト長（Base64で43文字）
+	personalAccessTokenBytes = 32
+	// 一覧で見分けるために保存するランダム部分の文字数
+	personalAccessTokenPrefixChars = 4
+	// 有効期間（日数）。期限なしのトークンは作れない
+	personalAccessTokenDefaultDays = 30
+	personalAccessTokenMaxDays     = 365
+	personalAccessTokenMaxNameLen  = 100
+)
+
+// PersonalAccessTokenService スクリプトや外部連携用の個人用アクセストークン（PAT）
+type PersonalAccessTokenService interface {
+	CreateToken(userID int64, req *models.CreatePersonalAccessTokenRequest) (*models.PersonalAccessTokenResponse, error)
+	GetTokens(userID int64) (*models.PersonalAccessTokensResponse, error)
+	RevokeToken(tokenID int64, userID int64) error
+	Authenticate(token string) (*jwtutil.CustomClaims, error)
+}
+
+type personalAccessTokenService struct {
+	tokenRepo repository.PersonalAccessTokenRepository
+}
+
+func NewPersonalAccessTokenService(tokenRepo repository.PersonalAccessTokenRepository) PersonalAccessTokenService {
+	return &personalAccessTokenService{
+		tokenRepo: tokenRepo,
+	}
+}
+
+// PATを作成（トークンはレスポンスでのみ返し、ハッシュを保存する）
+func (s *personalAccessTokenService) CreateToken(userID int64, req *models.CreatePersonalAccessTokenRequest) (*models.PersonalAccessTokenResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if utf8.RuneCountInString(name) > personalAccessTokenMaxNameLen {
+		return nil, errors.New("name is too long")
+	}
+
+	scopes, err := normalizePersonalAccessTokenScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	days := personalAccessTokenDefaultDays
+	if req.ExpiresInDays != nil {
+		days = *req.ExpiresInDays
+	}
+	if days < 1 || days > personalAccessTokenMaxDays {
+		return nil, errors.New("invalid expiration")
+	}
+
+	random, err := crypto.GenerateRandomToken(personalAccessTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	plainToken := jwtutil.PersonalAccessTokenPrefix + random
+
+	token := &models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenHash:   crypto.HashToken(plainToken),
+		TokenPrefix: plainToken[:len(jwtutil.PersonalAccessTokenPrefix)+personalAccessTokenPrefixChars],
+		Scopes:      scopes,
+		ExpiresAt:   time.Now().Add(time.Duration(days) * 24 * time.Hour),
+	}
+	if err := s.tokenRepo.Create(token); err != nil {
+		return nil, err
+	}
+
+	log.Printf("AUDIT personal access token %d created for user %d (scopes: %s)", token.ID, userID, strings.Join(scopes, " "))
+
+	return &models.PersonalAccessTokenResponse{
+		PersonalAccessToken: toPersonalAccessTokenItem(token, plainToken),
+	}, nil
+}
+
+func (s *personalAccessTokenService) GetTokens(userID int64) (*models.PersonalAccessTokensResponse, error) {
+	tokens, err := s.tokenRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// トークンは保存していないため一覧では返さない
+	items := make([]models.PersonalAccessTokenItem, 0, len(tokens))
+	for _, token := range tokens {
+		items = append(items, toPersonalAccessTokenItem(token, ""))
+	}
+
+	return &models.PersonalAccessTokensResponse{PersonalAccessTokens: items}, nil
+}
+
+func (s *personalAccessTokenService) RevokeToken(tokenID int64, userID int64) error {
+	if err := s.tokenRepo.Revoke(tokenID, userID); err != nil {
+		return err
+	}
+
+	log.Printf("AUDIT personal access token %d revoked by user %d", tokenID, userID)
+	return nil
+}
+
+// PATを検証して持ち主のクレームを返す（jwtutil.SetPersonalAccessTokenValidatorに渡す）
+func (s *personalAccessTokenService) Authenticate(token string) (*jwtutil.CustomClaims, error) {
+	pat, err := s.tokenRepo.GetActiveByTokenHash(crypto.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	// 最終使用日時の更新に失敗しても認証は通す
+	if err := s.tokenRepo.TouchLastUsed(pat.ID); err != nil {
+		log.Printf("Failed to update last_used_at of personal access token %d: %v", pat.ID, err)
+	}
+
+	// ロールは設定しない（PATでは管理者向けのエンドポイントを使えない）
+	return &jwtutil.CustomClaims{
+		UserID: int(pat.UserID),
+		Scopes: pat.Scopes,
+	}, nil
+}
+
+// スコープを検証し、重複を除いてjwtutil.Scopesの順に並べる
+func normalizePersonalAccessTokenScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, errors.New("scopes are required")
+	}
+
+	requested := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		if !jwtutil.IsValidScope(scope) {
+			return nil, errors.New("invalid scope")
+		}
+		requested[scope] = true
+	}
+
+	normalized := make([]string, 0, len(requested))
+	for _, scope := range jwtutil.Scopes {
+		if requested[scope] {
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
+
+func toPersonalAccessTokenItem(token *models.PersonalAccessToken, plainToken string) models.PersonalAccessTokenItem {
+	return models.PersonalAccessTokenItem{
+		ID:          token.ID,
+		Name:        token.Name,
+		Token:       plainToken,
+		TokenPrefix: token.TokenPrefix,
+		Scopes:      token.Scopes,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
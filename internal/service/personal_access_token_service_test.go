@@ -0,0 +1,23 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePersonalAccessTokenScopes(t *testing.T) {
+	actual, err := normalizePersonalAccessTokenScopes([]string{"events:write", "oshis:read", "events:write", "events:read"})
+	if err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	expected := []string{"oshis:read", "events:read", "events:write"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("期待値 %v, 実際 %v", expected, actual)
+	}
+
+	for _, scopes := range [][]string{nil, {}, {"events:delete"}, {"events:read", "admin"}} {
+		if _, err := normalizePersonalAccessTokenScopes(scopes); err == nil {
+			t.Errorf("%v はエラーになるべきです", scopes)
+		}
+	}
+}
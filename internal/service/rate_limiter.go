@@ -0,0 +1,27 @@
+package service
+
+import (
+	"time"
+)
+
+// RateLimitError 実行間隔の制限に達した場合のエラー
+type RateLimitError struct {
+	RetryAfter time.Duration // 次に実行できるまでの時間
+}
+
+func (e *RateLimitError) Error() string {
+	return "rate limited"
+}
+
+// intervalごとに1回だけ実行できる場合に、lastに実行してから次に実行できるまでの時間
+// インスタンス間の時計のずれで前回の日時が未来になっても、interval以上は待たせない
+func intervalRetryAfter(last time.Time, now time.Time, interval time.Duration) time.Duration {
+	remaining := last.Add(interval).Sub(now)
+	if remaining > interval {
+		return interval
+	}
+	if remaining < time.Second {
+		return time.Second
+	}
+	return remaining
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntervalRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		last     time.Time
+		expected time.Duration
+	}{
+		{"間隔内は残りの時間", now.Add(-20 * time.Second), 40 * time.Second},
+		{"直前に実行した場合は間隔いっぱい", now, time.Minute},
+		{"前回の日時が未来でも間隔を超えない", now.Add(30 * time.Second), time.Minute},
+		{"間隔の直前でも1秒は待つ", now.Add(-time.Minute + time.Millisecond), time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := intervalRetryAfter(tt.last, now, time.Minute); actual != tt.expected {
+				t.Errorf("期待値 %v, 実際 %v", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -66,8 +66,8 @@ func (s *SchedulerService) executeAutoEventCreation() {
 	}
 
 	duration := time.Since(startTime)
-	log.Printf("Auto event creation completed in %v - Processed: %d oshis, Created: %d events, Errors: %d",
-		duration, result.ProcessedOshis, result.CreatedEvents, len(result.Errors))
+	log.Printf("Auto event creation completed in %v - Processed: %d oshis, Created: %d events, Queued: %d candidates, Errors: %d",
+		duration, result.ProcessedOshis, result.CreatedEvents, result.QueuedCandidates, len(result.Errors))
 
 	// エラーがある場合は詳細をログ出力
 	if len(result.Errors) > 0 {
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"lovender_backend/internal/models"
+	"lovender_backend/internal/repository"
+	"lovender_backend/pkg/crypto"
+	"lovender_backend/pkg/jwtutil"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// リフレッシュトークンの有効期間（再発行のたびに延長する）
+	refreshTokenTTL = 30 * 24 * time.Hour
+
+	// トークンのバイト長
+	refreshTokenBytes = 32
+	sessionJTIBytes   = 16
+
+	// 保存するユーザーエージェントの最大文字数
+	maxUserAgentLength = 255
+)
+
+type SessionService interface {
+	IssueTokens(user *models.User, client models.ClientInfo) (*models.AuthTokens, error)
+	Refresh(refreshToken string) (*models.AuthTokens, error)
+	Logout(jti string) error
+	IsSessionActive(jti string) (bool, error)
+	GetSessions(userID int64, currentJTI string) (*models.SessionsResponse, error)
+	RevokeSession(sessionID int64, userID int64) error
+	RevokeOtherSessions(userID int64, currentJTI string) (int64, error)
+}
+
+type sessionService struct {
+	sessionRepo repository.SessionRepository
+	userRepo    repository.UserRepository
+}
+
+func NewSessionService(sessionRepo repository.SessionRepository, userRepo repository.UserRepository) SessionService {
+	return &sessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// 新しいセッションを作成し、アクセストークンとリフレッシュトークンを発行
+func (s *sessionService) IssueTokens(user *models.User, client models.ClientInfo) (*models.AuthTokens, error) {
+	jti, err := crypto.GenerateRandomToken(sessionJTIBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate jti: %w", err)
+	}
+	refreshToken, err := crypto.GenerateRandomToken(refreshTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	session := &models.Session{
+		UserID:           user.ID,
+		JTI:              jti,
+		RefreshTokenHash: crypto.HashToken(refreshToken),
+		UserAgent:        optionalString(truncateRunes(client.UserAgent, maxUserAgentLength)),
+		IPAddress:        optionalString(client.IPAddress),
+		ExpiresAt:        time.Now().Add(refreshTokenTTL),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	return s.newAuthTokens(user, jti, refreshToken)
+}
+
+// リフレッシュトークンを使ってトークンを再発行（リフレッシュトークンも新しくなる）
+func (s *sessionService) Refresh(refreshToken string) (*models.AuthTokens, error) {
+	if refreshToken == "" {
+		return nil, errors.New("invalid refresh token")
+	}
+	tokenHash := crypto.HashToken(refreshToken)
+
+	session, err := s.sessionRepo.GetByRefreshTokenHash(tokenHash)
+	if err != nil {
+		if err.Error() != "session not found" {
+			return nil, err
+		}
+
+		// ローテーション済みのトークンの再利用はセッションごと失効させる
+		revoked, revokeErr := s.sessionRepo.RevokeByPreviousRefreshTokenHash(tokenHash)
+		if revokeErr != nil {
+			return nil, revokeErr
+		}
+		if revoked {
+			log.Printf("Refresh token reuse detected, session revoked")
+		}
+		return nil, errors.New("invalid refresh token")
+	}
+
+	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	newRefreshToken, err := crypto.GenerateRandomToken(refreshTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	err = s.sessionRepo.Rotate(session.ID, tokenHash, crypto.HashToken(newRefreshToken), time.Now().Add(refreshTokenTTL))
+	if err != nil {
+		if err.Error() == "session not found" {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	return s.newAuthTokens(user, session.JTI, newRefreshToken)
+}
+
+// 現在のセッションを失効させる
+func (s *sessionService) Logout(jti string) error {
+	return s.sessionRepo.RevokeByJTI(jti)
+}
+
+func (s *sessionService) IsSessionActive(jti string) (bool, error) {
+	return s.sessionRepo.IsActiveByJTI(jti)
+}
+
+// ログイン中のセッション（端末）一覧を取得
+func (s *sessionService) GetSessions(userID int64, currentJTI string) (*models.SessionsResponse, error) {
+	sessions, err := s.sessionRepo.GetActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.SessionItem, 0, len(sessions))
+	for _, session := range sessions {
+		items = append(items, models.SessionItem{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.JTI == currentJTI,
+		})
+	}
+
+	return &models.SessionsResponse{Sessions: items}, nil
+}
+
+func (s *sessionService) RevokeSession(sessionID int64, userID int64) error {
+	return s.sessionRepo.Revoke(sessionID, userID)
+}
+
+// 現在のセッション以外からログアウトさせる
+func (s *sessionService) RevokeOtherSessions(userID int64, currentJTI string) (int64, error) {
+	return s.sessionRepo.RevokeOthers(userID, currentJTI)
+}
+
+func (s *sessionService) newAuthTokens(user *models.User, jti string, refreshToken string) (*models.AuthTokens, error) {
+	token, err := jwtutil.GenerateToken(int(user.ID), user.Role, jti)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthTokens{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(jwtutil.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// 文字数の上限で切り詰める
+func truncateRunes(value string, max int) string {
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	return string([]rune(value)[:max])
+}
@@ -2,25 +2,39 @@ package service
 
 import (
 	"errors"
+	"log"
 	"lovender_backend/internal/models"
 	"lovender_backend/internal/repository"
 	"lovender_backend/pkg/crypto"
-	"lovender_backend/pkg/jwtutil"
+	"strings"
+	"time"
 )
 
 type UserService interface {
 	GetUser(id int64) (*models.User, error)
-	Register(req *models.RegisterRequest) (*models.RegisterResponse, error)
-	Login(req *models.LoginRequest) (*models.LoginResponse, error)
+	Register(req *models.RegisterRequest, client models.ClientInfo) (*models.RegisterResponse, error)
+	Login(req *models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error)
+	UpdateProfile(userID int64, req *models.UpdateProfileRequest) (*models.User, error)
+	ChangePassword(userID int64, currentJTI string, req *models.ChangePasswordRequest) error
+	DeleteAccount(userID int64, req *models.DeleteAccountRequest) (*models.DeleteAccountResponse, error)
 }
 
+// 退会申請から削除までの猶予期間（この間にログインすると取り消される）
+const accountDeletionGracePeriod = 30 * 24 * time.Hour
+
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo       repository.UserRepository
+	sessionService SessionService
+	accountService AccountService
+	loginThrottle  *LoginThrottle
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
+func NewUserService(userRepo repository.UserRepository, sessionService SessionService, accountService AccountService, loginThrottle *LoginThrottle) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		sessionService: sessionService,
+		accountService: accountService,
+		loginThrottle:  loginThrottle,
 	}
 }
 
@@ -41,7 +55,7 @@ func (s *userService) GetUser(id int64) (*models.User, error) {
 	return user, nil
 }
 
-func (s *userService) Register(req *models.RegisterRequest) (*models.RegisterResponse, error) {
+func (s *userService) Register(req *models.RegisterRequest, client models.ClientInfo) (*models.RegisterResponse, error) {
 	// メールアドレスの重複チェック
 	existingUser, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil {
@@ -69,41 +83,163 @@ func (s *userService) Register(req *models.RegisterRequest) (*models.RegisterRes
 		return nil, err
 	}
 
-	// JWTトークン生成
-	token, err := jwtutil.GenerateToken(int(user.ID))
+	// 確認メールの送信に失敗しても登録は完了させる（再送信できる）
+	if err := s.accountService.SendEmailVerification(user); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	// セッションを作成してトークン生成
+	tokens, err := s.sessionService.IssueTokens(user, client)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.RegisterResponse{
-		Name:  user.Name,
-		Email: user.Email,
-		Token: token,
+		Name:       user.Name,
+		Email:      user.Email,
+		AuthTokens: *tokens,
 	}, nil
 }
 
-func (s *userService) Login(req *models.LoginRequest) (*models.LoginResponse, error) {
+func (s *userService) Login(req *models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	// 失敗が続いているメールアドレス・IPからの試行は待たせる
+	if err := s.loginThrottle.Check(req.Email, client.IPAddress); err != nil {
+		log.Printf("AUDIT login throttled email=%q ip=%s", req.Email, client.IPAddress)
+		return nil, err
+	}
+
 	// ユーザーを取得
 	user, err := s.userRepo.GetByEmail(req.Email)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
+		s.loginThrottle.RecordFailure(req.Email, client.IPAddress)
 		return nil, errors.New("invalid email or password")
 	}
 
 	// パスワード確認
 	if !crypto.CheckPasswordHash(req.Password, user.PasswordHash) {
+		s.loginThrottle.RecordFailure(req.Email, client.IPAddress)
 		return nil, errors.New("invalid email or password")
 	}
 
-	// JWTトークン生成
-	token, err := jwtutil.GenerateToken(int(user.ID))
+	s.loginThrottle.RecordSuccess(req.Email, client.IPAddress)
+
+	// 退会の猶予期間中にログインした場合は退会を取り消す
+	if user.DeletionRequestedAt != nil {
+		if err := s.userRepo.CancelDeletion(user.ID); err != nil {
+			return nil, err
+		}
+		log.Printf("Account deletion cancelled by login for user %d", user.ID)
+	}
+
+	// セッションを作成してトークン生成
+	tokens, err := s.sessionService.IssueTokens(user, client)
 	if err != nil {
 		return nil, err
 	}
 
 	return &models.LoginResponse{
-		Token: token,
+		AuthTokens: *tokens,
+	}, nil
+}
+
+// 名前・メールアドレスを更新
+func (s *userService) UpdateProfile(userID int64, req *models.UpdateProfileRequest) (*models.User, error) {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := user.Name
+	if req.Name != nil {
+		name = strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, errors.New("name is required")
+		}
+	}
+
+	email := user.Email
+	if req.Email != nil {
+		email = strings.TrimSpace(*req.Email)
+		if email == "" {
+			return nil, errors.New("email is required")
+		}
+	}
+
+	emailChanged := email != user.Email
+	if emailChanged {
+		// メールアドレスの重複チェック
+		existingUser, err := s.userRepo.GetByEmail(email)
+		if err != nil {
+			return nil, err
+		}
+		if existingUser != nil && existingUser.ID != userID {
+			return nil, errors.New("email already exists")
+		}
+	}
+
+	if err := s.userRepo.UpdateProfile(userID, name, email); err != nil {
+		// 同時に同じメールアドレスへ変更された場合は一意制約で弾かれる
+		if isDuplicateKeyError(err) {
+			return nil, errors.New("email already exists")
+		}
+		return nil, err
+	}
+
+	user.Name = name
+	user.Email = email
+	if emailChanged {
+		// 新しいメールアドレスで確認し直す
+		user.EmailVerifiedAt = nil
+		if err := s.accountService.SendEmailVerification(user); err != nil {
+			log.Printf("Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+
+	return user, nil
+}
+
+// 現在のパスワードを確認してからパスワードを変更（他の端末はログアウトされる）
+func (s *userService) ChangePassword(userID int64, currentJTI string, req *models.ChangePasswordRequest) error {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !crypto.CheckPasswordHash(req.CurrentPassword, user.PasswordHash) {
+		return errors.New("invalid current password")
+	}
+
+	hashedPassword, err := crypto.HashPassword(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.UpdatePassword(userID, hashedPassword, currentJTI)
+}
+
+// 退会を申請（猶予期間後に推し・イベントを含めて削除される）
+func (s *userService) DeleteAccount(userID int64, req *models.DeleteAccountRequest) (*models.DeleteAccountResponse, error) {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !crypto.CheckPasswordHash(req.Password, user.PasswordHash) {
+		return nil, errors.New("invalid password")
+	}
+
+	requestedAt, err := s.userRepo.RequestDeletion(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Account deletion requested for user %d", userID)
+
+	return &models.DeleteAccountResponse{
+		Message:             "Account deletion scheduled. Log in again before the scheduled time to cancel.",
+		DeletionScheduledAt: requestedAt.Add(accountDeletionGracePeriod),
 	}, nil
 }
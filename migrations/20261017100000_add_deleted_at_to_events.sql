@@ -0,0 +1,2 @@
+-- Modify "events" table
+ALTER TABLE `events` ADD COLUMN `deleted_at` datetime(3) NULL AFTER `updated_at`;
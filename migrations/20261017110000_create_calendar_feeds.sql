@@ -0,0 +1,16 @@
+-- Create "calendar_feeds" table
+CREATE TABLE `calendar_feeds` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `oshi_id` bigint unsigned NULL,
+  `token_hash` char(64) NOT NULL,
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  `rotated_at` datetime(3) NULL,
+  `revoked_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_calendar_feeds_user` (`user_id`),
+  INDEX `fk_calendar_feeds_oshi` (`oshi_id`),
+  UNIQUE INDEX `uq_calendar_feeds_token_hash` (`token_hash`),
+  CONSTRAINT `fk_calendar_feeds_oshi` FOREIGN KEY (`oshi_id`) REFERENCES `oshis` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT `fk_calendar_feeds_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE
+) CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,19 @@
+-- Modify "events" table
+ALTER TABLE `events` ADD COLUMN `rrule` varchar(255) NULL AFTER `ends_at`;
+-- Create "event_occurrence_overrides" table
+CREATE TABLE `event_occurrence_overrides` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `event_id` bigint unsigned NOT NULL,
+  `recurrence_id` datetime(3) NOT NULL,
+  `is_cancelled` bool NOT NULL DEFAULT 0,
+  `title` varchar(255) NULL,
+  `description` text NULL,
+  `url` varchar(2048) NULL,
+  `starts_at` datetime(3) NULL,
+  `ends_at` datetime(3) NULL,
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  `updated_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uq_event_occurrence_overrides` (`event_id`, `recurrence_id`),
+  CONSTRAINT `fk_event_occurrence_overrides_event` FOREIGN KEY (`event_id`) REFERENCES `events` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE
+) CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci;
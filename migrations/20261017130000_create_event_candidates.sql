@@ -0,0 +1,30 @@
+-- Modify "oshis" table
+ALTER TABLE `oshis` ADD COLUMN `auto_event_mode` enum('publish','review') NOT NULL DEFAULT "review" AFTER `theme_color`;
+-- Create "event_candidates" table
+CREATE TABLE `event_candidates` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `oshi_id` bigint unsigned NOT NULL,
+  `post_id` bigint unsigned NOT NULL,
+  `category_id` smallint unsigned NULL,
+  `title` varchar(255) NOT NULL,
+  `content` text NOT NULL,
+  `account_name` varchar(191) NOT NULL,
+  `post_created_at` datetime(3) NOT NULL,
+  `matched_keywords` json NOT NULL,
+  `matched_pattern` varchar(255) NOT NULL,
+  `matched_text` varchar(255) NOT NULL,
+  `starts_at` datetime(3) NOT NULL,
+  `ends_at` datetime(3) NULL,
+  `status` enum('pending','accepted','rejected') NOT NULL DEFAULT "pending",
+  `event_id` bigint unsigned NULL,
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  `updated_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (`id`),
+  INDEX `fk_event_candidates_category` (`category_id`),
+  INDEX `fk_event_candidates_event` (`event_id`),
+  INDEX `idx_event_candidates_status` (`status`),
+  UNIQUE INDEX `uq_event_candidates_oshi_post` (`oshi_id`, `post_id`),
+  CONSTRAINT `fk_event_candidates_category` FOREIGN KEY (`category_id`) REFERENCES `categories` (`id`) ON UPDATE NO ACTION ON DELETE SET NULL,
+  CONSTRAINT `fk_event_candidates_event` FOREIGN KEY (`event_id`) REFERENCES `events` (`id`) ON UPDATE NO ACTION ON DELETE SET NULL,
+  CONSTRAINT `fk_event_candidates_oshi` FOREIGN KEY (`oshi_id`) REFERENCES `oshis` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE
+) CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci;
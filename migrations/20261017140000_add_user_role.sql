@@ -0,0 +1,2 @@
+-- Modify "users" table
+ALTER TABLE `users` ADD COLUMN `role` enum('user','admin') NOT NULL DEFAULT "user" AFTER `password_hash`;
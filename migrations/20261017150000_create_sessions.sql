@@ -0,0 +1,20 @@
+-- Create "sessions" table
+CREATE TABLE `sessions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `jti` varchar(64) NOT NULL,
+  `refresh_token_hash` char(64) NOT NULL,
+  `previous_refresh_token_hash` char(64) NULL,
+  `user_agent` varchar(255) NULL,
+  `ip_address` varchar(45) NULL,
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  `last_used_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  `expires_at` datetime(3) NOT NULL,
+  `revoked_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_sessions_previous_refresh_token_hash` (`previous_refresh_token_hash`),
+  INDEX `idx_sessions_user` (`user_id`),
+  UNIQUE INDEX `uq_sessions_jti` (`jti`),
+  UNIQUE INDEX `uq_sessions_refresh_token_hash` (`refresh_token_hash`),
+  CONSTRAINT `fk_sessions_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE
+) CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,16 @@
+-- Modify "users" table
+ALTER TABLE `users` ADD COLUMN `email_verified_at` datetime(3) NULL AFTER `role`;
+-- Create "user_tokens" table
+CREATE TABLE `user_tokens` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `purpose` enum('password_reset','email_verification') NOT NULL,
+  `token_hash` char(64) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `used_at` datetime(3) NULL,
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_tokens_user_purpose` (`user_id`, `purpose`),
+  UNIQUE INDEX `uq_user_tokens_token_hash` (`token_hash`),
+  CONSTRAINT `fk_user_tokens_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE
+) CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- Modify "users" table
+ALTER TABLE `users` ADD COLUMN `deletion_requested_at` datetime(3) NULL AFTER `email_verified_at`, ADD INDEX `idx_users_deletion_requested_at` (`deletion_requested_at`);
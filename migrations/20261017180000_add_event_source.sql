@@ -0,0 +1,4 @@
+-- Modify "events" table
+ALTER TABLE `events` ADD COLUMN `source` enum('manual','auto','import') NOT NULL DEFAULT "manual" AFTER `post_id`;
+-- Backfill auto-detected events
+UPDATE `events` SET `source` = "auto" WHERE `post_id` IS NOT NULL;
@@ -0,0 +1,27 @@
+-- Create "user_identities" table
+CREATE TABLE `user_identities` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `provider` varchar(50) NOT NULL,
+  `subject` varchar(255) NOT NULL,
+  `email` varchar(255) NULL,
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  `last_login_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (`id`),
+  INDEX `idx_user_identities_user` (`user_id`),
+  UNIQUE INDEX `uq_user_identities_provider_subject` (`provider`, `subject`),
+  CONSTRAINT `fk_user_identities_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE
+) CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci;
+-- Create "oidc_login_states" table
+CREATE TABLE `oidc_login_states` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `provider` varchar(50) NOT NULL,
+  `state_hash` char(64) NOT NULL,
+  `nonce` varchar(255) NOT NULL,
+  `code_verifier` varchar(255) NOT NULL,
+  `expires_at` datetime(3) NOT NULL,
+  `created_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (`id`),
+  INDEX `idx_oidc_login_states_expires_at` (`expires_at`),
+  UNIQUE INDEX `uq_oidc_login_states_state_hash` (`state_hash`)
+) CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,4 @@
+-- Modify "oshis" table
+ALTER TABLE `oshis` ADD COLUMN `deleted_at` datetime(3) NULL AFTER `updated_at`, ADD INDEX `idx_oshis_deleted_at` (`deleted_at`);
+-- Modify "oshis" table
+ALTER TABLE `oshis` ADD COLUMN `active_name` varchar(191) AS (if((`deleted_at` is null),`name`,NULL)) VIRTUAL NULL AFTER `deleted_at`, DROP INDEX `uq_oshis_user_name`, ADD UNIQUE INDEX `uq_oshis_user_name` (`user_id`, `active_name`);
@@ -0,0 +1,22 @@
+-- Modify "oshi_accounts" table
+ALTER TABLE `oshi_accounts` ADD COLUMN `platform` enum('x','instagram','youtube','tiktok','bluesky','custom') NOT NULL DEFAULT "custom" AFTER `url`, ADD COLUMN `handle` varchar(255) NULL AFTER `platform`, ADD INDEX `idx_oshi_accounts_platform_handle` (`platform`, `handle`);
+-- Backfill "oshi_accounts" platform and handle from url
+UPDATE `oshi_accounts` SET `platform` = 'x', `handle` = REGEXP_REPLACE(`url`, '^https?://(www\\.|mobile\\.)?(x|twitter)\\.com/@?([A-Za-z0-9_]{1,15})([/?#].*)?$', '$3') WHERE `url` REGEXP '^https?://(www\\.|mobile\\.)?(x|twitter)\\.com/@?[A-Za-z0-9_]{1,15}([/?#].*)?$';
+UPDATE `oshi_accounts` SET `platform` = 'instagram', `handle` = REGEXP_REPLACE(`url`, '^https?://(www\\.)?instagram\\.com/([A-Za-z0-9._]{1,30})([/?#].*)?$', '$2') WHERE `url` REGEXP '^https?://(www\\.)?instagram\\.com/[A-Za-z0-9._]{1,30}([/?#].*)?$';
+UPDATE `oshi_accounts` SET `platform` = 'youtube', `handle` = REGEXP_REPLACE(`url`, '^https?://(www\\.|m\\.)?youtube\\.com/@([A-Za-z0-9._-]{3,30})([/?#].*)?$', '$2') WHERE `url` REGEXP '^https?://(www\\.|m\\.)?youtube\\.com/@[A-Za-z0-9._-]{3,30}([/?#].*)?$';
+UPDATE `oshi_accounts` SET `platform` = 'youtube', `handle` = REGEXP_REPLACE(`url`, '^https?://(www\\.|m\\.)?youtube\\.com/channel/(UC[A-Za-z0-9_-]{22})([/?#].*)?$', '$2') WHERE REGEXP_LIKE(`url`, '^https?://(www\\.|m\\.)?youtube\\.com/channel/UC[A-Za-z0-9_-]{22}([/?#].*)?$', 'i');
+UPDATE `oshi_accounts` SET `platform` = 'tiktok', `handle` = REGEXP_REPLACE(`url`, '^https?://(www\\.|m\\.)?tiktok\\.com/@([A-Za-z0-9._]{2,24})([/?#].*)?$', '$2') WHERE `url` REGEXP '^https?://(www\\.|m\\.)?tiktok\\.com/@[A-Za-z0-9._]{2,24}([/?#].*)?$';
+UPDATE `oshi_accounts` SET `platform` = 'bluesky', `handle` = LOWER(REGEXP_REPLACE(`url`, '^https?://(www\\.)?bsky\\.app/profile/([A-Za-z0-9-]+(\\.[A-Za-z0-9-]+)+)([/?#].*)?$', '$2')) WHERE `url` REGEXP '^https?://(www\\.)?bsky\\.app/profile/[A-Za-z0-9-]+(\\.[A-Za-z0-9-]+)+([/?#].*)?$';
+UPDATE `oshi_accounts` SET `platform` = 'bluesky', `handle` = REGEXP_REPLACE(`url`, '^https?://(www\\.)?bsky\\.app/profile/(did:[a-z]+:[A-Za-z0-9._:-]+)([/?#].*)?$', '$2') WHERE `url` REGEXP '^https?://(www\\.)?bsky\\.app/profile/did:[a-z]+:[A-Za-z0-9._:-]+([/?#].*)?$';
+-- Keep non-profile urls (x.com/home, instagram.com/p/...) as custom
+UPDATE `oshi_accounts` SET `platform` = 'custom', `handle` = NULL WHERE (`platform` = 'x' AND `handle` IN ('home', 'i', 'search', 'explore', 'intent', 'share', 'settings', 'notifications', 'messages', 'hashtag')) OR (`platform` = 'instagram' AND `handle` IN ('p', 'reel', 'reels', 'explore', 'stories', 'accounts', 'tv'));
+-- Remove accounts that point to the same profile
+DELETE `a` FROM `oshi_accounts` AS `a` INNER JOIN `oshi_accounts` AS `b` ON `a`.`oshi_id` = `b`.`oshi_id` AND `a`.`platform` = `b`.`platform` AND `a`.`handle` = `b`.`handle` AND `a`.`id` > `b`.`id` WHERE `a`.`platform` <> 'custom';
+-- Normalize "oshi_accounts" url to the profile url
+UPDATE `oshi_accounts` SET `url` = CASE `platform`
+  WHEN 'x' THEN CONCAT('https://x.com/', `handle`)
+  WHEN 'instagram' THEN CONCAT('https://www.instagram.com/', `handle`)
+  WHEN 'youtube' THEN IF(REGEXP_LIKE(`handle`, '^UC[A-Za-z0-9_-]{22}$', 'c'), CONCAT('https://www.youtube.com/channel/', `handle`), CONCAT('https://www.youtube.com/@', `handle`))
+  WHEN 'tiktok' THEN CONCAT('https://www.tiktok.com/@', `handle`)
+  WHEN 'bluesky' THEN CONCAT('https://bsky.app/profile/', `handle`)
+END WHERE `platform` <> 'custom';
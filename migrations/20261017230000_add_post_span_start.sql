@@ -0,0 +1,4 @@
+-- Modify "events" table
+ALTER TABLE `events` ADD COLUMN `post_span_start` int unsigned NULL AFTER `post_id`, ADD UNIQUE INDEX `uq_events_oshi_post_span` (`oshi_id`, `post_id`, `post_span_start`);
+-- Modify "event_candidates" table
+ALTER TABLE `event_candidates` ADD COLUMN `post_span_start` int unsigned NULL AFTER `post_id`, DROP INDEX `uq_event_candidates_oshi_post`, ADD UNIQUE INDEX `uq_event_candidates_oshi_post_span` (`oshi_id`, `post_id`, `post_span_start`);
@@ -0,0 +1,4 @@
+-- Modify "events" table
+ALTER TABLE `events` ADD COLUMN `extraction` json NULL AFTER `rrule`;
+-- Modify "event_candidates" table
+ALTER TABLE `event_candidates` ADD COLUMN `extraction` json NULL AFTER `matched_text`;
@@ -0,0 +1,8 @@
+-- Create "event_occurrence_notifications" table
+CREATE TABLE `event_occurrence_notifications` (
+  `event_id` bigint unsigned NOT NULL,
+  `recurrence_id` datetime(3) NOT NULL,
+  `sent_at` datetime(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (`event_id`, `recurrence_id`),
+  CONSTRAINT `fk_event_occurrence_notifications_event` FOREIGN KEY (`event_id`) REFERENCES `events` (`id`) ON UPDATE NO ACTION ON DELETE CASCADE
+) CHARSET utf8mb4 COLLATE utf8mb4_unicode_ci;
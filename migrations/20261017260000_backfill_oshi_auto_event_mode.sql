@@ -0,0 +1,4 @@
+-- Backfill "oshis" auto_event_mode (oshis created before auto_event_mode keep publishing detected events)
+UPDATE `oshis` SET `auto_event_mode` = 'publish';
+-- Modify "oshis" table (new oshis default to review)
+ALTER TABLE `oshis` MODIFY COLUMN `auto_event_mode` enum('publish','review') NOT NULL DEFAULT "review";
@@ -0,0 +1,2 @@
+-- Modify "users" table
+ALTER TABLE `users` ADD COLUMN `oshi_synced_at` datetime(3) NULL AFTER `deletion_requested_at`;
@@ -0,0 +1,347 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iCalendar (RFC 5545) の日付・ローカル日時形式
+const (
+	dateFormat          = "20060102"
+	localDateTimeFormat = "20060102T150405"
+)
+
+// DecodedEvent 読み込んだVEVENT（不正なプロパティがある場合はErrに理由を入れる）
+type DecodedEvent struct {
+	Event
+	Err error
+}
+
+// 1行分のプロパティ (NAME;PARAM=VALUE:VALUE)
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode iCalendar形式を読み込み、VEVENTを取り出す
+// TZIDもUTC指定もない日時はlocの時刻として扱う
+func Decode(r io.Reader, loc *time.Location) ([]DecodedEvent, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("not an iCalendar file")
+	}
+
+	var (
+		events     []DecodedEvent
+		current    *DecodedEvent
+		components []string
+	)
+
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		if len(components) == 0 && (prop.name != "BEGIN" || strings.ToUpper(prop.value) != "VCALENDAR") {
+			return nil, errors.New("not an iCalendar file")
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			component := strings.ToUpper(prop.value)
+			if component == "VEVENT" {
+				current = &DecodedEvent{}
+			}
+			components = append(components, component)
+			continue
+		case "END":
+			component := strings.ToUpper(prop.value)
+			if len(components) == 0 || components[len(components)-1] != component {
+				return nil, fmt.Errorf("line %d: unexpected END:%s", i+1, prop.value)
+			}
+			components = components[:len(components)-1]
+			if component == "VEVENT" && current != nil {
+				if current.Err == nil && current.Start.IsZero() {
+					current.Err = errors.New("DTSTART is required")
+				}
+				events = append(events, *current)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil || current.Err != nil {
+			continue
+		}
+
+		switch components[len(components)-1] {
+		case "VEVENT":
+			current.Err = applyEventProperty(&current.Event, prop, loc)
+		case "VALARM":
+			// 開始基準の相対TRIGGERのみ取り込む
+			if prop.name == "TRIGGER" && prop.params["VALUE"] == "" && prop.params["RELATED"] != "END" {
+				before, err := ParseTrigger(prop.value)
+				if err != nil {
+					current.Err = err
+					continue
+				}
+				if current.Alarm == nil {
+					current.Alarm = &Alarm{Before: before}
+				}
+			}
+		}
+	}
+
+	if len(components) != 0 {
+		return nil, fmt.Errorf("missing END:%s", components[len(components)-1])
+	}
+
+	return events, nil
+}
+
+// VEVENTのプロパティを反映する
+func applyEventProperty(event *Event, prop property, loc *time.Location) error {
+	switch prop.name {
+	case "UID":
+		event.UID = prop.value
+	case "SUMMARY":
+		event.Summary = UnescapeText(prop.value)
+	case "DESCRIPTION":
+		event.Description = UnescapeText(prop.value)
+	case "URL":
+		event.URL = prop.value
+	case "CATEGORIES":
+		for _, category := range splitEscaped(prop.value, ',') {
+			if category != "" {
+				event.Categories = append(event.Categories, UnescapeText(category))
+			}
+		}
+	case "DTSTART":
+		start, err := parseDateTime(prop, loc)
+		if err != nil {
+			return fmt.Errorf("invalid DTSTART: %w", err)
+		}
+		event.Start = start
+	case "DTEND":
+		end, err := parseDateTime(prop, loc)
+		if err != nil {
+			return fmt.Errorf("invalid DTEND: %w", err)
+		}
+		event.End = &end
+	}
+	return nil
+}
+
+// 日時を解析する（UTC / TZID付き / ローカル / 終日）
+func parseDateTime(prop property, loc *time.Location) (time.Time, error) {
+	if prop.params["VALUE"] == "DATE" || len(prop.value) == len(dateFormat) {
+		return time.ParseInLocation(dateFormat, prop.value, loc)
+	}
+
+	if strings.HasSuffix(prop.value, "Z") {
+		return time.Parse(dateTimeFormat, prop.value)
+	}
+
+	if tzid := prop.params["TZID"]; tzid != "" {
+		tz, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+		}
+		loc = tz
+	}
+	return time.ParseInLocation(localDateTimeFormat, prop.value, loc)
+}
+
+// ParseTrigger TRIGGERの期間形式を開始前の通知時間に変換する (例: "-PT15M" → 15分)
+func ParseTrigger(value string) (time.Duration, error) {
+	s := value
+	negative := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	if !strings.HasPrefix(s, "P") || len(s) < 3 {
+		return 0, fmt.Errorf("invalid TRIGGER %q", value)
+	}
+	s = s[1:]
+
+	var (
+		total  time.Duration
+		inTime bool
+		digits string
+	)
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits += string(r)
+			continue
+		case r == 'T' && !inTime && digits == "":
+			inTime = true
+			continue
+		}
+
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			return 0, fmt.Errorf("invalid TRIGGER %q", value)
+		}
+		digits = ""
+
+		unit, ok := durationUnit(r, inTime)
+		if !ok {
+			return 0, fmt.Errorf("invalid TRIGGER %q", value)
+		}
+		total += time.Duration(n) * unit
+	}
+	if digits != "" {
+		return 0, fmt.Errorf("invalid TRIGGER %q", value)
+	}
+
+	// 開始後の通知は扱わない
+	if !negative && total != 0 {
+		return 0, fmt.Errorf("TRIGGER after start is not supported: %q", value)
+	}
+	return total, nil
+}
+
+func durationUnit(r rune, inTime bool) (time.Duration, bool) {
+	if inTime {
+		switch r {
+		case 'H':
+			return time.Hour, true
+		case 'M':
+			return time.Minute, true
+		case 'S':
+			return time.Second, true
+		}
+		return 0, false
+	}
+	switch r {
+	case 'W':
+		return 7 * 24 * time.Hour, true
+	case 'D':
+		return 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// UnescapeText TEXT型の値のエスケープを戻す
+func UnescapeText(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		if escaped {
+			switch r {
+			case 'n', 'N':
+				b.WriteRune('\n')
+			default:
+				b.WriteRune(r)
+			}
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// エスケープされていない区切り文字で分割する
+func splitEscaped(value string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// 折り返された行を結合する
+func unfoldLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// 先頭のBOMを取り除く
+	if len(lines) > 0 {
+		lines[0] = strings.TrimPrefix(lines[0], "\uFEFF")
+	}
+	return lines, nil
+}
+
+// 1行をプロパティ名・パラメータ・値に分解する
+func parseProperty(line string) (property, error) {
+	// 引用符の外にある最初の ":" が値の開始位置
+	valueIndex := -1
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' {
+			inQuote = !inQuote
+		} else if line[i] == ':' && !inQuote {
+			valueIndex = i
+			break
+		}
+	}
+	if valueIndex < 0 {
+		return property{}, fmt.Errorf("malformed line %q", line)
+	}
+
+	prop := property{
+		params: make(map[string]string),
+		value:  line[valueIndex+1:],
+	}
+
+	segments := strings.Split(line[:valueIndex], ";")
+	prop.name = strings.ToUpper(segments[0])
+	for _, segment := range segments[1:] {
+		key, value, ok := strings.Cut(segment, "=")
+		if !ok {
+			continue
+		}
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return prop, nil
+}
@@ -0,0 +1,152 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:event-1@example.com",
+		`SUMMARY:ワンマンライブ\, 東京`,
+		`DESCRIPTION:詳細は公式サイト\;\n当日券`,
+		" あり",
+		"URL:https://example.com/live",
+		"DTSTART:20260110T050000Z",
+		"DTEND;TZID=Asia/Tokyo:20260110T160000",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"TRIGGER:-PT30M",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:ローカル日時",
+		"DTSTART:20260111T100000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:開始日時なし",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := Decode(strings.NewReader(input), jst)
+	if err != nil {
+		t.Fatalf("デコードに失敗しました: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("イベント数が一致しません: %d", len(events))
+	}
+
+	first := events[0]
+	if first.Err != nil {
+		t.Fatalf("1件目がエラーになりました: %v", first.Err)
+	}
+	if first.Summary != "ワンマンライブ, 東京" {
+		t.Errorf("SUMMARYが一致しません: %q", first.Summary)
+	}
+	if first.Description != "詳細は公式サイト;\n当日券あり" {
+		t.Errorf("DESCRIPTIONが一致しません: %q", first.Description)
+	}
+	if !first.Start.Equal(time.Date(2026, 1, 10, 5, 0, 0, 0, time.UTC)) {
+		t.Errorf("DTSTARTが一致しません: %s", first.Start)
+	}
+	if first.End == nil || !first.End.Equal(time.Date(2026, 1, 10, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("DTENDが一致しません: %v", first.End)
+	}
+	if first.Alarm == nil || first.Alarm.Before != 30*time.Minute {
+		t.Errorf("VALARMが一致しません: %+v", first.Alarm)
+	}
+
+	if !events[1].Start.Equal(time.Date(2026, 1, 11, 1, 0, 0, 0, time.UTC)) {
+		t.Errorf("ローカル日時が指定のタイムゾーンで解釈されていません: %s", events[1].Start)
+	}
+	if events[2].Err == nil {
+		t.Error("DTSTARTのないイベントはエラーになるべきです")
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	inputs := []string{
+		"",
+		"title,starts_at\r\nライブ,2026-01-10T14:00:00+09:00",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR",
+	}
+
+	for _, input := range inputs {
+		if _, err := Decode(strings.NewReader(input), time.UTC); err == nil {
+			t.Errorf("不正な入力 %q はエラーになるべきです", input)
+		}
+	}
+}
+
+func TestParseTrigger(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"PT0S", 0},
+		{"-PT15M", 15 * time.Minute},
+		{"-PT1H30M", 90 * time.Minute},
+		{"-P1D", 24 * time.Hour},
+		{"-P1DT2H", 26 * time.Hour},
+		{"-P1W", 7 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		actual, err := ParseTrigger(tt.value)
+		if err != nil {
+			t.Errorf("ParseTrigger(%q) でエラーになりました: %v", tt.value, err)
+			continue
+		}
+		if actual != tt.expected {
+			t.Errorf("ParseTrigger(%q) = %v, 期待値 %v", tt.value, actual, tt.expected)
+		}
+	}
+
+	for _, value := range []string{"", "15M", "-PT", "-PT15", "PT15M"} {
+		if _, err := ParseTrigger(value); err == nil {
+			t.Errorf("ParseTrigger(%q) はエラーになるべきです", value)
+		}
+	}
+}
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	calendar := &Calendar{
+		ProdID: "-//lovender//calendar//JA",
+		Events: []Event{
+			{
+				UID:         "event-1@lovender",
+				Summary:     "握手会, 大阪",
+				Description: strings.Repeat("長い説明;", 20),
+				Start:       time.Date(2026, 2, 1, 3, 0, 0, 0, time.UTC),
+				Alarm:       &Alarm{Before: 2 * time.Hour},
+			},
+		},
+	}
+
+	var b strings.Builder
+	if err := calendar.Encode(&b, time.Now()); err != nil {
+		t.Fatalf("エンコードに失敗しました: %v", err)
+	}
+
+	events, err := Decode(strings.NewReader(b.String()), time.UTC)
+	if err != nil {
+		t.Fatalf("デコードに失敗しました: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("イベント数が一致しません: %d", len(events))
+	}
+	actual := events[0]
+	expected := calendar.Events[0]
+	if actual.Summary != expected.Summary || actual.Description != expected.Description || !actual.Start.Equal(expected.Start) {
+		t.Errorf("往復後のイベントが一致しません: %+v", actual.Event)
+	}
+	if actual.Alarm == nil || actual.Alarm.Before != expected.Alarm.Before {
+		t.Errorf("往復後のVALARMが一致しません: %+v", actual.Alarm)
+	}
+}
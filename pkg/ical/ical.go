@@ -0,0 +1,177 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar (RFC 5545) の日時形式（UTC）
+const dateTimeFormat = "20060102T150405Z"
+
+// 1行の最大オクテット数（超える場合は折り返す）
+const maxLineOctets = 75
+
+// Calendar VCALENDAR
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Event VEVENT
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	End         *time.Time
+	Categories  []string
+	Alarm       *Alarm
+}
+
+// Alarm VALARM（開始のBefore前に通知する）
+type Alarm struct {
+	Before      time.Duration
+	Description string
+}
+
+// Encode カレンダーをiCalendar形式で書き出す
+func (c *Calendar) Encode(w io.Writer, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	lw := &lineWriter{w: bw}
+
+	lw.writeLine("BEGIN:VCALENDAR")
+	lw.writeLine("VERSION:2.0")
+	lw.writeLine("PRODID:" + c.ProdID)
+	lw.writeLine("CALSCALE:GREGORIAN")
+	lw.writeLine("METHOD:PUBLISH")
+	if c.Name != "" {
+		lw.writeLine("X-WR-CALNAME:" + EscapeText(c.Name))
+	}
+
+	dtstamp := now.UTC().Format(dateTimeFormat)
+	for _, event := range c.Events {
+		lw.writeLine("BEGIN:VEVENT")
+		lw.writeLine("UID:" + event.UID)
+		lw.writeLine("DTSTAMP:" + dtstamp)
+		lw.writeLine("DTSTART:" + event.Start.UTC().Format(dateTimeFormat))
+		if event.End != nil {
+			lw.writeLine("DTEND:" + event.End.UTC().Format(dateTimeFormat))
+		}
+		lw.writeLine("SUMMARY:" + EscapeText(event.Summary))
+		if event.Description != "" {
+			lw.writeLine("DESCRIPTION:" + EscapeText(event.Description))
+		}
+		if event.URL != "" {
+			lw.writeLine("URL:" + event.URL)
+		}
+		if len(event.Categories) > 0 {
+			categories := make([]string, 0, len(event.Categories))
+			for _, category := range event.Categories {
+				categories = append(categories, EscapeText(category))
+			}
+			lw.writeLine("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		if event.Alarm != nil {
+			description := event.Alarm.Description
+			if description == "" {
+				description = event.Summary
+			}
+			lw.writeLine("BEGIN:VALARM")
+			lw.writeLine("ACTION:DISPLAY")
+			lw.writeLine("DESCRIPTION:" + EscapeText(description))
+			lw.writeLine("TRIGGER:" + FormatTrigger(event.Alarm.Before))
+			lw.writeLine("END:VALARM")
+		}
+		lw.writeLine("END:VEVENT")
+	}
+
+	lw.writeLine("END:VCALENDAR")
+	if lw.err != nil {
+		return lw.err
+	}
+	return bw.Flush()
+}
+
+// EscapeText TEXT型の値をエスケープする
+func EscapeText(value string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(value)
+}
+
+// FormatTrigger 開始前の通知時間をTRIGGERの期間形式に変換する (例: 15分前 → "-PT15M")
+func FormatTrigger(before time.Duration) string {
+	if before <= 0 {
+		return "PT0S"
+	}
+
+	switch {
+	case before%(7*24*time.Hour) == 0:
+		return fmt.Sprintf("-P%dW", before/(7*24*time.Hour))
+	case before%(24*time.Hour) == 0:
+		return fmt.Sprintf("-P%dD", before/(24*time.Hour))
+	case before%time.Hour == 0:
+		return fmt.Sprintf("-PT%dH", before/time.Hour)
+	case before%time.Minute == 0:
+		return fmt.Sprintf("-PT%dM", before/time.Minute)
+	default:
+		return fmt.Sprintf("-PT%dS", before/time.Second)
+	}
+}
+
+// 75オクテットで折り返しながらCRLFで行を書き出す
+type lineWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (lw *lineWriter) writeLine(line string) {
+	if lw.err != nil {
+		return
+	}
+
+	first := true
+	for len(line) > 0 {
+		limit := maxLineOctets
+		if !first {
+			// 継続行は先頭の空白1文字分を差し引く
+			limit--
+		}
+
+		cut := len(line)
+		if cut > limit {
+			cut = limit
+			// マルチバイト文字の途中で切らない
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+		}
+
+		if !first {
+			lw.write(" ")
+		}
+		lw.write(line[:cut])
+		lw.write("\r\n")
+		line = line[cut:]
+		first = false
+	}
+}
+
+func (lw *lineWriter) write(s string) {
+	if lw.err != nil {
+		return
+	}
+	_, lw.err = lw.w.WriteString(s)
+}
@@ -0,0 +1,107 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalendar_Encode(t *testing.T) {
+	end := time.Date(2026, 1, 10, 7, 0, 0, 0, time.UTC)
+	calendar := &Calendar{
+		ProdID: "-//lovender//calendar//JA",
+		Name:   "山田美咲",
+		Events: []Event{
+			{
+				UID:         "event-1@lovender",
+				Summary:     "ワンマンライブ, 東京",
+				Description: "詳細は公式サイト;\n当日券あり",
+				URL:         "https://example.com/live",
+				Start:       time.Date(2026, 1, 10, 14, 0, 0, 0, time.FixedZone("JST", 9*60*60)),
+				End:         &end,
+				Categories:  []string{"ライブ・コンサート"},
+				Alarm:       &Alarm{Before: 15 * time.Minute},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := calendar.Encode(&buf, now); err != nil {
+		t.Fatalf("エンコードに失敗しました: %v", err)
+	}
+	output := buf.String()
+
+	expectedLines := []string{
+		"BEGIN:VCALENDAR",
+		"X-WR-CALNAME:山田美咲",
+		"UID:event-1@lovender",
+		"DTSTAMP:20260101T000000Z",
+		"DTSTART:20260110T050000Z",
+		"DTEND:20260110T070000Z",
+		`SUMMARY:ワンマンライブ\, 東京`,
+		`DESCRIPTION:詳細は公式サイト\;\n当日券あり`,
+		"URL:https://example.com/live",
+		"CATEGORIES:ライブ・コンサート",
+		"BEGIN:VALARM",
+		"TRIGGER:-PT15M",
+		"END:VALARM",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(output, line+"\r\n") {
+			t.Errorf("出力に %q が含まれていません\n%s", line, output)
+		}
+	}
+}
+
+func TestCalendar_EncodeFoldsLongLines(t *testing.T) {
+	calendar := &Calendar{
+		ProdID: "-//lovender//calendar//JA",
+		Events: []Event{
+			{
+				UID:         "event-2@lovender",
+				Summary:     "長い説明",
+				Description: strings.Repeat("あいうえお", 30),
+				Start:       time.Date(2026, 1, 10, 5, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := calendar.Encode(&buf, time.Now()); err != nil {
+		t.Fatalf("エンコードに失敗しました: %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("75オクテットを超える行があります (%d): %q", len(line), line)
+		}
+	}
+	if !strings.Contains(buf.String(), "\r\n ") {
+		t.Error("長い行が折り返されていません")
+	}
+}
+
+func TestFormatTrigger(t *testing.T) {
+	tests := []struct {
+		before   time.Duration
+		expected string
+	}{
+		{0, "PT0S"},
+		{5 * time.Minute, "-PT5M"},
+		{30 * time.Minute, "-PT30M"},
+		{2 * time.Hour, "-PT2H"},
+		{24 * time.Hour, "-P1D"},
+		{48 * time.Hour, "-P2D"},
+		{7 * 24 * time.Hour, "-P1W"},
+	}
+
+	for _, tt := range tests {
+		if actual := FormatTrigger(tt.before); actual != tt.expected {
+			t.Errorf("FormatTrigger(%v) = %q, 期待値 %q", tt.before, actual, tt.expected)
+		}
+	}
+}
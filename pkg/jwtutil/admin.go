@@ -0,0 +1,68 @@
+package jwtutil
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// 管理者権限のロール
+	RoleAdmin = "admin"
+
+	// サービス間連携（Cloud Schedulerなど）で共有シークレットを送るヘッダー
+	AdminSecretHeader = "X-Admin-Secret"
+)
+
+// AdminMiddleware 内部用エンドポイントの認証
+// ADMIN_API_SECRETと一致する共有シークレットのヘッダー、またはroleがadminのJWTのどちらかで許可する
+// 拒否したアクセスは監査ログに記録する
+func AdminMiddleware() echo.MiddlewareFunc {
+	adminSecret := os.Getenv("ADMIN_API_SECRET")
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// 共有シークレット（未設定の場合はこの方法を使えない）
+			if secret := c.Request().Header.Get(AdminSecretHeader); secret != "" {
+				if adminSecret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(adminSecret)) == 1 {
+					return next(c)
+				}
+				return denyAdminAccess(c, "invalid shared secret", 0)
+			}
+
+			// 管理者のJWT
+			tokenString, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !ok || tokenString == "" {
+				return denyAdminAccess(c, "missing credentials", 0)
+			}
+
+			claims, err := ParseToken(tokenString)
+			if err != nil {
+				return denyAdminAccess(c, "invalid token", 0)
+			}
+			if err := validateSession(claims); err != nil {
+				return denyAdminAccess(c, "session revoked", 0)
+			}
+			if claims.Role != RoleAdmin {
+				return denyAdminAccess(c, "not an admin", claims.UserID)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// 拒否したアクセスを監査ログに記録してエラーを返す
+func denyAdminAccess(c echo.Context, reason string, userID int) error {
+	log.Printf("AUDIT admin access denied: method=%s path=%s ip=%s user_id=%d reason=%q",
+		c.Request().Method, c.Request().URL.Path, c.RealIP(), userID, reason)
+
+	if userID != 0 {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Access denied"})
+	}
+	return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+}
@@ -0,0 +1,58 @@
+package jwtutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	setupTestKeySet(t)
+	t.Setenv("ADMIN_API_SECRET", "test-admin-secret")
+
+	adminToken, err := GenerateToken(1, RoleAdmin, "admin-session")
+	if err != nil {
+		t.Fatalf("トークン生成に失敗: %v", err)
+	}
+	userToken, err := GenerateToken(2, "user", "user-session")
+	if err != nil {
+		t.Fatalf("トークン生成に失敗: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{"共有シークレット", AdminSecretHeader, "test-admin-secret", http.StatusOK},
+		{"共有シークレット不一致", AdminSecretHeader, "wrong", http.StatusUnauthorized},
+		{"管理者のJWT", echo.HeaderAuthorization, "Bearer " + adminToken, http.StatusOK},
+		{"一般ユーザーのJWT", echo.HeaderAuthorization, "Bearer " + userToken, http.StatusForbidden},
+		{"不正なJWT", echo.HeaderAuthorization, "Bearer invalid", http.StatusUnauthorized},
+		{"認証情報なし", "", "", http.StatusUnauthorized},
+	}
+
+	e := echo.New()
+	handler := AdminMiddleware()(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/z/scheduler/status", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		rec := httptest.NewRecorder()
+
+		if err := handler(e.NewContext(req, rec)); err != nil {
+			t.Errorf("%s: 予期しないエラー: %v", tt.name, err)
+			continue
+		}
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: 期待値 %d, 実際 %d", tt.name, tt.wantStatus, rec.Code)
+		}
+	}
+}
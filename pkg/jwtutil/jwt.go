@@ -2,7 +2,8 @@ package jwtutil
 
 import (
 	"fmt"
-	"os"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -10,41 +11,148 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// sub・user_idはどちらもユーザーID（user_idは既存クライアント向け）
 type CustomClaims struct {
-	UserID int `json:"user_id"`
+	UserID int    `json:"user_id"`
+	Role   string `json:"role,omitempty"`
+	// PATで認証した場合のみ設定する（JWTには含めない）
+	Scopes []string `json:"-"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID int) (string, error) {
+// IsPersonalAccessToken PATで認証したか判定する
+func (c *CustomClaims) IsPersonalAccessToken() bool {
+	return c.Scopes != nil
+}
+
+// HasScope 操作が許可されているか判定する（JWTはすべて許可）
+func (c *CustomClaims) HasScope(scope string) bool {
+	if !c.IsPersonalAccessToken() {
+		return true
+	}
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// アクセストークンの有効期間（期限切れ後はリフレッシュトークンで再発行する）
+const AccessTokenTTL = time.Hour
+
+// jtiはセッションの識別子。セッションを失効させるとそのセッションのアクセストークンも使えなくなる
+func GenerateToken(userID int, role string, jti string) (string, error) {
+	ks, err := currentKeySet()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := &CustomClaims{
 		UserID: userID,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        jti,
+			Issuer:    ks.issuer,
+			Subject:   strconv.Itoa(userID),
+			Audience:  jwt.ClaimStrings{ks.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	secret := os.Getenv("JWT_SECRET")
+	return ks.sign(claims)
+}
 
-	return token.SignedString([]byte(secret))
+// ParseToken 署名・iss・aud・有効期限を検証してクレームを返す（セッションの失効は確認しない）
+func ParseToken(tokenString string) (*CustomClaims, error) {
+	token, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return token.Claims.(*CustomClaims), nil
 }
 
-func JWTMiddleware() echo.MiddlewareFunc {
-	secret := os.Getenv("JWT_SECRET")
+func parseToken(tokenString string) (*jwt.Token, error) {
+	ks, err := currentKeySet()
+	if err != nil {
+		return nil, err
+	}
+	return ks.parse(tokenString)
+}
+
+// SessionValidator jtiのセッションが有効か判定する
+type SessionValidator func(jti string) (bool, error)
+
+var sessionValidator SessionValidator
 
-	return echojwt.WithConfig(echojwt.Config{
-		SigningKey:  []byte(secret),
-		NewClaimsFunc: func(c echo.Context) jwt.Claims {
-			return new(CustomClaims)
+// SetSessionValidator JWTの検証時にセッションの失効を確認する関数を設定する（起動時に1回だけ呼ぶ）
+func SetSessionValidator(validator SessionValidator) {
+	sessionValidator = validator
+}
+
+// セッションが失効していないか確認する（未設定の場合は確認しない）
+func validateSession(claims *CustomClaims) error {
+	if sessionValidator == nil {
+		return nil
+	}
+	if claims.ID == "" {
+		return fmt.Errorf("token has no jti")
+	}
+
+	active, err := sessionValidator(claims.ID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return fmt.Errorf("session revoked")
+	}
+	return nil
+}
+
+func JWTMiddleware() echo.MiddlewareFunc {
+	jwtMiddleware := echojwt.WithConfig(echojwt.Config{
+		ParseTokenFunc: func(c echo.Context, auth string) (interface{}, error) {
+			return parseToken(auth)
 		},
 		ContextKey: "user",
 	})
+
+	// 署名の検証後にセッションが失効していないか確認する
+	// PATの場合はトークンを検証し、スコープはRequireScopeで確認する
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		jwtHandler := jwtMiddleware(func(c echo.Context) error {
+			claims, err := ExtractUser(c)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+			}
+			if err := validateSession(claims); err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+			}
+			return next(c)
+		})
+
+		return func(c echo.Context) error {
+			if token, ok := bearerPersonalAccessToken(c); ok {
+				if err := authenticatePersonalAccessToken(c, token); err != nil {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+				}
+				return next(c)
+			}
+			return jwtHandler(c)
+		}
+	}
 }
 
 func ExtractUser(c echo.Context) (*CustomClaims, error) {
+	// PATの場合はRequireScopeでクレームが設定される
+	if claims, ok := c.Get("user").(*CustomClaims); ok {
+		return claims, nil
+	}
+
 	token, ok := c.Get("user").(*jwt.Token)
 	if !ok {
-		fmt.Println("user is not *jwt.Token")
 		return nil, fmt.Errorf("invalid token format")
 	}
 
@@ -0,0 +1,70 @@
+package jwtutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestJWTMiddlewareSessionValidation(t *testing.T) {
+	setupTestKeySet(t)
+
+	SetSessionValidator(func(jti string) (bool, error) {
+		return jti == "active-session", nil
+	})
+	defer SetSessionValidator(nil)
+
+	activeToken, err := GenerateToken(1, "user", "active-session")
+	if err != nil {
+		t.Fatalf("トークン生成に失敗: %v", err)
+	}
+	revokedToken, err := GenerateToken(1, "user", "revoked-session")
+	if err != nil {
+		t.Fatalf("トークン生成に失敗: %v", err)
+	}
+	noJTIToken, err := GenerateToken(1, "user", "")
+	if err != nil {
+		t.Fatalf("トークン生成に失敗: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"有効なセッション", activeToken, http.StatusOK},
+		{"失効したセッション", revokedToken, http.StatusUnauthorized},
+		{"jtiなし", noJTIToken, http.StatusUnauthorized},
+	}
+
+	e := echo.New()
+	handler := JWTMiddleware()(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+		rec := httptest.NewRecorder()
+
+		if err := handler(e.NewContext(req, rec)); err != nil {
+			t.Errorf("%s: 予期しないエラー: %v", tt.name, err)
+			continue
+		}
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: 期待値 %d, 実際 %d", tt.name, tt.wantStatus, rec.Code)
+		}
+	}
+}
+
+// テスト用にHS256の共有シークレットを設定する
+func setupTestKeySet(t *testing.T) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+	if err := Init(); err != nil {
+		t.Fatalf("鍵の読み込みに失敗: %v", err)
+	}
+	t.Cleanup(func() { SetKeySet(nil) })
+}
@@ -0,0 +1,415 @@
+package jwtutil
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// iss・audの既定値
+	DefaultIssuer   = "lovender"
+	DefaultAudience = "lovender-api"
+
+	// HS256の共有シークレットとして推奨する最小の長さ（バイト）
+	minSecretLength = 32
+)
+
+// KeySet トークンの署名鍵と検証鍵
+// 鍵をローテーションする場合は、新しい鍵で署名しつつ古い公開鍵を検証用に残す
+type KeySet struct {
+	issuer   string
+	audience string
+
+	signingMethod jwt.SigningMethod
+	signingKey    interface{}
+	signingKID    string
+
+	// kidごとの検証用の公開鍵（署名鍵の公開鍵を含む）
+	verificationKeys map[string]verificationKey
+	// HS256の共有シークレット（未設定ならnil）
+	secret []byte
+}
+
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+var (
+	keySetMu sync.RWMutex
+	keySet   *KeySet
+)
+
+// Init 環境変数から鍵を読み込む（起動時に1回だけ呼び、エラーなら起動を中止する）
+func Init() error {
+	ks, err := LoadKeySetFromEnv()
+	if err != nil {
+		return err
+	}
+	SetKeySet(ks)
+	return nil
+}
+
+// SetKeySet トークンの発行・検証に使う鍵を設定する
+func SetKeySet(ks *KeySet) {
+	keySetMu.Lock()
+	defer keySetMu.Unlock()
+	keySet = ks
+}
+
+func currentKeySet() (*KeySet, error) {
+	keySetMu.RLock()
+	defer keySetMu.RUnlock()
+	if keySet == nil {
+		return nil, errors.New("jwt signing key is not configured")
+	}
+	return keySet, nil
+}
+
+// LoadKeySetFromEnv 環境変数から鍵を読み込む
+//
+//   - JWT_PRIVATE_KEY（またはJWT_PRIVATE_KEY_FILE）: 署名に使うRSAまたはEd25519の秘密鍵（PEM）
+//   - JWT_PREVIOUS_PUBLIC_KEYS（またはJWT_PREVIOUS_PUBLIC_KEYS_FILE）: ローテーション前の公開鍵（PEM、複数可）
+//   - JWT_SECRET: HS256の共有シークレット。秘密鍵がない場合は署名に使い、ある場合は移行期間の検証のみに使う
+//   - JWT_ISSUER / JWT_AUDIENCE: iss・audの値
+func LoadKeySetFromEnv() (*KeySet, error) {
+	issuer := os.Getenv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = DefaultIssuer
+	}
+	audience := os.Getenv("JWT_AUDIENCE")
+	if audience == "" {
+		audience = DefaultAudience
+	}
+
+	privateKeyPEM, err := readEnvOrFile("JWT_PRIVATE_KEY")
+	if err != nil {
+		return nil, err
+	}
+	previousKeysPEM, err := readEnvOrFile("JWT_PREVIOUS_PUBLIC_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	secret := os.Getenv("JWT_SECRET")
+
+	if privateKeyPEM == "" && secret == "" {
+		return nil, errors.New("no JWT signing key configured: set JWT_PRIVATE_KEY (RSA or Ed25519) or JWT_SECRET")
+	}
+
+	var ks *KeySet
+	if privateKeyPEM != "" {
+		privateKey, err := ParsePrivateKeyPEM([]byte(privateKeyPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_PRIVATE_KEY: %w", err)
+		}
+		ks, err = NewKeySet(issuer, audience, privateKey)
+		if err != nil {
+			return nil, err
+		}
+		if secret != "" {
+			log.Println("WARNING: JWT_SECRET is accepted for verification only; remove it once HS256 tokens have expired")
+			ks.secret = []byte(secret)
+		}
+	} else {
+		if len(secret) < minSecretLength {
+			log.Printf("WARNING: JWT_SECRET is shorter than %d bytes; use JWT_PRIVATE_KEY in production", minSecretLength)
+		}
+		ks = NewSecretKeySet(issuer, audience, []byte(secret))
+	}
+
+	if previousKeysPEM != "" {
+		publicKeys, err := ParsePublicKeysPEM([]byte(previousKeysPEM))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_PREVIOUS_PUBLIC_KEYS: %w", err)
+		}
+		for _, publicKey := range publicKeys {
+			if err := ks.AddVerificationKey(publicKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ks, nil
+}
+
+// NewKeySet RSA（RS256）またはEd25519（EdDSA）の秘密鍵で署名する
+// kidは公開鍵のJWKサムプリント（RFC 7638）
+func NewKeySet(issuer, audience string, privateKey interface{}) (*KeySet, error) {
+	var publicKey interface{}
+	var method jwt.SigningMethod
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, errors.New("RSA key must be at least 2048 bits")
+		}
+		publicKey, method = &key.PublicKey, jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		publicKey, method = key.Public(), jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+
+	ks := &KeySet{
+		issuer:           issuer,
+		audience:         audience,
+		signingMethod:    method,
+		signingKey:       privateKey,
+		verificationKeys: make(map[string]verificationKey),
+	}
+	if err := ks.AddVerificationKey(publicKey); err != nil {
+		return nil, err
+	}
+	ks.signingKID, _ = thumbprint(publicKey)
+	return ks, nil
+}
+
+// NewSecretKeySet HS256の共有シークレットで署名する（開発用、JWKSは公開しない）
+func NewSecretKeySet(issuer, audience string, secret []byte) *KeySet {
+	return &KeySet{
+		issuer:           issuer,
+		audience:         audience,
+		signingMethod:    jwt.SigningMethodHS256,
+		signingKey:       secret,
+		verificationKeys: make(map[string]verificationKey),
+		secret:           secret,
+	}
+}
+
+// AddVerificationKey 検証用の公開鍵を追加する（ローテーション前の鍵など）
+func (ks *KeySet) AddVerificationKey(publicKey interface{}) error {
+	kid, err := thumbprint(publicKey)
+	if err != nil {
+		return err
+	}
+
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		ks.verificationKeys[kid] = verificationKey{method: jwt.SigningMethodRS256, key: publicKey}
+	case ed25519.PublicKey:
+		ks.verificationKeys[kid] = verificationKey{method: jwt.SigningMethodEdDSA, key: publicKey}
+	}
+	return nil
+}
+
+// 署名する
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signingMethod, claims)
+	if ks.signingKID != "" {
+		token.Header["kid"] = ks.signingKID
+	}
+	return token.SignedString(ks.signingKey)
+}
+
+// 署名・iss・aud・有効期限を検証する
+func (ks *KeySet) parse(tokenString string) (*jwt.Token, error) {
+	methods := []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	if ks.secret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	return jwt.ParseWithClaims(tokenString, new(CustomClaims), func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			return ks.secret, nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		vk, ok := ks.verificationKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		// 鍵の種類とalgが一致しない場合は拒否する
+		if vk.method.Alg() != token.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+		}
+		return vk.key, nil
+	},
+		jwt.WithValidMethods(methods),
+		jwt.WithIssuer(ks.issuer),
+		jwt.WithAudience(ks.audience),
+		jwt.WithExpirationRequired(),
+	)
+}
+
+// JSONWebKey 公開鍵のJWK
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet JWKS
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JWKS 検証用の公開鍵一覧（共有シークレットは含まない）
+func (ks *KeySet) JWKS() JSONWebKeySet {
+	keys := make([]JSONWebKey, 0, len(ks.verificationKeys))
+	for kid, vk := range ks.verificationKeys {
+		jwk, err := toJWK(vk.key)
+		if err != nil {
+			continue
+		}
+		jwk.Kid = kid
+		jwk.Use = "sig"
+		jwk.Alg = vk.method.Alg()
+		keys = append(keys, jwk)
+	}
+
+	// 署名中の鍵を先頭にし、残りはkid順
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i].Kid == ks.signingKID) != (keys[j].Kid == ks.signingKID) {
+			return keys[i].Kid == ks.signingKID
+		}
+		return keys[i].Kid < keys[j].Kid
+	})
+	return JSONWebKeySet{Keys: keys}
+}
+
+// JWKSHandler 他のサービスがトークンを検証するための公開鍵（/.well-known/jwks.json）
+func JWKSHandler(c echo.Context) error {
+	ks, err := currentKeySet()
+	if err != nil {
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "Keys not configured"})
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, ks.JWKS())
+}
+
+func toJWK(publicKey interface{}) (JSONWebKey, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	default:
+		return JSONWebKey{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// JWKサムプリント（RFC 7638）
+func thumbprint(publicKey interface{}) (string, error) {
+	jwk, err := toJWK(publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	// 必須メンバーのみを辞書順に並べたJSON
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// ParsePrivateKeyPEM PKCS#8またはPKCS#1のPEMから秘密鍵を読み込む
+func ParsePrivateKeyPEM(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// ParsePublicKeysPEM 連結されたPEMから公開鍵をすべて読み込む
+func ParsePublicKeysPEM(data []byte) ([]interface{}, error) {
+	var keys []interface{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case *rsa.PublicKey, ed25519.PublicKey:
+			keys = append(keys, key)
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T", key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM block found")
+	}
+	return keys, nil
+}
+
+// NAME または NAME_FILE（Secret Managerをファイルとしてマウントする場合）から読み込む
+func readEnvOrFile(name string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+	return string(data), nil
+}
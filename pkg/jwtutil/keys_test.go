@@ -0,0 +1,255 @@
+package jwtutil
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func generateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("RSA鍵の生成に失敗: %v", err)
+	}
+	return key
+}
+
+func generateEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Ed25519鍵の生成に失敗: %v", err)
+	}
+	return key
+}
+
+func privateKeyPEM(t *testing.T, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("秘密鍵のエンコードに失敗: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func publicKeyPEM(t *testing.T, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatalf("公開鍵のエンコードに失敗: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestLoadKeySetFromEnvRequiresKey(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_PRIVATE_KEY", "")
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+
+	if _, err := LoadKeySetFromEnv(); err == nil {
+		t.Errorf("署名鍵が未設定の場合はエラーになるべきです")
+	}
+
+	t.Setenv("JWT_PRIVATE_KEY", "not a pem")
+	if _, err := LoadKeySetFromEnv(); err == nil {
+		t.Errorf("不正な秘密鍵はエラーになるべきです")
+	}
+}
+
+func TestGenerateTokenWithoutKeySet(t *testing.T) {
+	SetKeySet(nil)
+	if _, err := GenerateToken(1, "user", "session"); err == nil {
+		t.Errorf("鍵が未設定の場合はトークンを発行できないべきです")
+	}
+}
+
+func TestAsymmetricSigningAndRotation(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		oldKey interface{}
+		newKey interface{}
+		alg    string
+	}{
+		{"RS256", generateRSAKey(t), generateRSAKey(t), "RS256"},
+		{"EdDSA", generateEd25519Key(t), generateEd25519Key(t), "EdDSA"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { SetKeySet(nil) })
+			t.Setenv("JWT_SECRET", "")
+
+			// ローテーション前の鍵で発行
+			t.Setenv("JWT_PRIVATE_KEY", privateKeyPEM(t, tt.oldKey))
+			if err := Init(); err != nil {
+				t.Fatalf("鍵の読み込みに失敗: %v", err)
+			}
+			oldToken, err := GenerateToken(42, "user", "session-1")
+			if err != nil {
+				t.Fatalf("トークン生成に失敗: %v", err)
+			}
+
+			// 新しい鍵に切り替え、古い公開鍵は検証用に残す
+			oldPublic := tt.oldKey.(crypto.Signer).Public()
+			t.Setenv("JWT_PRIVATE_KEY", privateKeyPEM(t, tt.newKey))
+			t.Setenv("JWT_PREVIOUS_PUBLIC_KEYS", publicKeyPEM(t, oldPublic))
+			if err := Init(); err != nil {
+				t.Fatalf("鍵の読み込みに失敗: %v", err)
+			}
+			newToken, err := GenerateToken(42, "user", "session-2")
+			if err != nil {
+				t.Fatalf("トークン生成に失敗: %v", err)
+			}
+
+			for name, token := range map[string]string{"古い鍵": oldToken, "新しい鍵": newToken} {
+				claims, err := ParseToken(token)
+				if err != nil {
+					t.Errorf("%sのトークンを検証できません: %v", name, err)
+					continue
+				}
+				if claims.UserID != 42 || claims.Subject != "42" || claims.Issuer != DefaultIssuer ||
+					len(claims.Audience) != 1 || claims.Audience[0] != DefaultAudience || claims.IssuedAt == nil {
+					t.Errorf("%sのクレームが期待と違います: %+v", name, claims)
+				}
+			}
+
+			// JWKSに両方の公開鍵が含まれ、署名中の鍵が先頭
+			parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &CustomClaims{})
+			if err != nil {
+				t.Fatalf("トークンの解析に失敗: %v", err)
+			}
+			if parsed.Header["alg"] != tt.alg {
+				t.Errorf("alg: 期待値 %s, 実際 %v", tt.alg, parsed.Header["alg"])
+			}
+			ks, _ := currentKeySet()
+			jwks := ks.JWKS()
+			if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != parsed.Header["kid"] || jwks.Keys[0].Alg != tt.alg {
+				t.Errorf("JWKSが期待と違います: %+v (kid=%v)", jwks.Keys, parsed.Header["kid"])
+			}
+
+			// 古い公開鍵を外すと古いトークンは使えない
+			t.Setenv("JWT_PREVIOUS_PUBLIC_KEYS", "")
+			if err := Init(); err != nil {
+				t.Fatalf("鍵の読み込みに失敗: %v", err)
+			}
+			if _, err := ParseToken(oldToken); err == nil {
+				t.Errorf("検証鍵から外した鍵のトークンはエラーになるべきです")
+			}
+		})
+	}
+}
+
+func TestParseTokenRejectsInvalidTokens(t *testing.T) {
+	key := generateRSAKey(t)
+	ks, err := NewKeySet(DefaultIssuer, DefaultAudience, key)
+	if err != nil {
+		t.Fatalf("鍵の作成に失敗: %v", err)
+	}
+	SetKeySet(ks)
+	t.Cleanup(func() { SetKeySet(nil) })
+
+	sign := func(method jwt.SigningMethod, signingKey interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = ks.signingKID
+		signed, err := token.SignedString(signingKey)
+		if err != nil {
+			t.Fatalf("署名に失敗: %v", err)
+		}
+		return signed
+	}
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"user_id": 1,
+			"sub":     "1",
+			"iss":     DefaultIssuer,
+			"aud":     DefaultAudience,
+			"exp":     time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	wrongIssuer := valid()
+	wrongIssuer["iss"] = "other"
+	wrongAudience := valid()
+	wrongAudience["aud"] = "other-api"
+	expired := valid()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	noExpiry := valid()
+	delete(noExpiry, "exp")
+
+	publicDER, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+
+	tests := map[string]string{
+		"issuerが違う":      sign(jwt.SigningMethodRS256, key, wrongIssuer),
+		"audienceが違う":    sign(jwt.SigningMethodRS256, key, wrongAudience),
+		"期限切れ":           sign(jwt.SigningMethodRS256, key, expired),
+		"有効期限なし":         sign(jwt.SigningMethodRS256, key, noExpiry),
+		"別の鍵で署名":         sign(jwt.SigningMethodRS256, generateRSAKey(t), valid()),
+		"公開鍵をHS256の鍵に流用": sign(jwt.SigningMethodHS256, publicDER, valid()),
+	}
+	for name, token := range tests {
+		if _, err := ParseToken(token); err == nil {
+			t.Errorf("%s: エラーになるべきです", name)
+		}
+	}
+
+	if _, err := ParseToken(sign(jwt.SigningMethodRS256, key, valid())); err != nil {
+		t.Errorf("正しいトークンは検証できるべきです: %v", err)
+	}
+}
+
+func TestSecretAcceptedDuringMigration(t *testing.T) {
+	t.Cleanup(func() { SetKeySet(nil) })
+
+	// 移行前: 共有シークレットで発行
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+	t.Setenv("JWT_PRIVATE_KEY", "")
+	if err := Init(); err != nil {
+		t.Fatalf("鍵の読み込みに失敗: %v", err)
+	}
+	hsToken, err := GenerateToken(1, "user", "session")
+	if err != nil {
+		t.Fatalf("トークン生成に失敗: %v", err)
+	}
+
+	// 移行中: 秘密鍵で発行しつつ、共有シークレットのトークンも検証できる
+	t.Setenv("JWT_PRIVATE_KEY", privateKeyPEM(t, generateEd25519Key(t)))
+	if err := Init(); err != nil {
+		t.Fatalf("鍵の読み込みに失敗: %v", err)
+	}
+	if _, err := ParseToken(hsToken); err != nil {
+		t.Errorf("移行中は共有シークレットのトークンを検証できるべきです: %v", err)
+	}
+
+	// 移行後: 共有シークレットを外すと使えない
+	t.Setenv("JWT_SECRET", "")
+	if err := Init(); err != nil {
+		t.Fatalf("鍵の読み込みに失敗: %v", err)
+	}
+	if _, err := ParseToken(hsToken); err == nil {
+		t.Errorf("共有シークレットを外した後はエラーになるべきです")
+	}
+
+	// JWKSには共有シークレットを含めない
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := JWKSHandler(e.NewContext(httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil), rec)); err != nil {
+		t.Fatalf("JWKSHandlerでエラー: %v", err)
+	}
+	var jwks JSONWebKeySet
+	if err := json.Unmarshal(rec.Body.Bytes(), &jwks); err != nil {
+		t.Fatalf("JWKSの解析に失敗: %v", err)
+	}
+	if len(jwks.Keys) != 1 || jwks.Keys[0].Kty != "OKP" || jwks.Keys[0].Crv != "Ed25519" {
+		t.Errorf("JWKSが期待と違います: %+v", jwks.Keys)
+	}
+}
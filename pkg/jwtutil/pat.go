@@ -0,0 +1,105 @@
+package jwtutil
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PersonalAccessTokenPrefix 個人用アクセストークン（PAT）の接頭辞（JWTと見分けるために使う）
+const PersonalAccessTokenPrefix = "lvd_pat_"
+
+// PATで許可できる操作の範囲
+const (
+	ScopeProfileRead = "profile:read"
+	ScopeOshisRead   = "oshis:read"
+	ScopeOshisWrite  = "oshis:write"
+	ScopeEventsRead  = "events:read"
+	ScopeEventsWrite = "events:write"
+)
+
+// Scopes 指定できるスコープの一覧
+var Scopes = []string{
+	ScopeProfileRead,
+	ScopeOshisRead,
+	ScopeOshisWrite,
+	ScopeEventsRead,
+	ScopeEventsWrite,
+}
+
+// IsValidScope 指定できるスコープか判定する
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// PersonalAccessTokenValidator PATを検証して持ち主のクレームを返す（Scopesを必ず設定する）
+type PersonalAccessTokenValidator func(token string) (*CustomClaims, error)
+
+var personalAccessTokenValidator PersonalAccessTokenValidator
+
+// SetPersonalAccessTokenValidator PATを検証する関数を設定する（起動時に1回だけ呼ぶ、未設定ならPATは使えない）
+func SetPersonalAccessTokenValidator(validator PersonalAccessTokenValidator) {
+	personalAccessTokenValidator = validator
+}
+
+// RequireScopeで確認するまでPATのクレームを置いておくキー
+const personalAccessTokenContextKey = "personal_access_token"
+
+// Authorizationヘッダーのトークンが PAT なら返す
+func bearerPersonalAccessToken(c echo.Context) (string, bool) {
+	auth := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(auth) < len("Bearer ") || !strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return "", false
+	}
+	token := auth[len("Bearer "):]
+	return token, strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// PATを検証してコンテキストに置く（この時点ではExtractUserで取得できない）
+func authenticatePersonalAccessToken(c echo.Context, token string) error {
+	if personalAccessTokenValidator == nil {
+		return fmt.Errorf("personal access tokens are not enabled")
+	}
+
+	claims, err := personalAccessTokenValidator(token)
+	if err != nil {
+		return err
+	}
+	if !claims.IsPersonalAccessToken() {
+		return fmt.Errorf("personal access token has no scopes")
+	}
+
+	c.Set(personalAccessTokenContextKey, claims)
+	return nil
+}
+
+// RequireScope PATで呼び出す場合に必要なスコープを指定する（JWTMiddlewareの後に使う）
+// JWT（ログインセッション）はすべての操作を許可する。
+// このミドルウェアを付けていないエンドポイントではPATのユーザーを取得できないため、PATは使えない
+func RequireScope(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, ok := c.Get(personalAccessTokenContextKey).(*CustomClaims)
+			if !ok {
+				return next(c)
+			}
+
+			for _, scope := range scopes {
+				if !claims.HasScope(scope) {
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="insufficient_scope", scope="`+strings.Join(scopes, " ")+`"`)
+					return c.JSON(http.StatusForbidden, map[string]string{"error": "Insufficient scope"})
+				}
+			}
+
+			c.Set("user", claims)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package jwtutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	setupTestKeySet(t)
+
+	SetPersonalAccessTokenValidator(func(token string) (*CustomClaims, error) {
+		if token != PersonalAccessTokenPrefix+"events-read" {
+			return nil, fmt.Errorf("personal access token not found")
+		}
+		return &CustomClaims{UserID: 7, Scopes: []string{ScopeEventsRead}}, nil
+	})
+	defer SetPersonalAccessTokenValidator(nil)
+
+	jwtToken, err := GenerateToken(7, "user", "session")
+	if err != nil {
+		t.Fatalf("トークン生成に失敗: %v", err)
+	}
+
+	e := echo.New()
+	// ハンドラーと同じくExtractUserでユーザーを取得する
+	handlerFunc := func(c echo.Context) error {
+		claims, err := ExtractUser(c)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		}
+		if claims.UserID != 7 {
+			t.Errorf("ユーザーID: 期待値 7, 実際 %d", claims.UserID)
+		}
+		return c.NoContent(http.StatusOK)
+	}
+	routes := map[string]echo.HandlerFunc{
+		"events:read":  JWTMiddleware()(RequireScope(ScopeEventsRead)(handlerFunc)),
+		"events:write": JWTMiddleware()(RequireScope(ScopeEventsWrite)(handlerFunc)),
+		"スコープ指定なし":     JWTMiddleware()(handlerFunc),
+	}
+
+	tests := []struct {
+		name       string
+		route      string
+		token      string
+		wantStatus int
+	}{
+		{"PATでスコープあり", "events:read", PersonalAccessTokenPrefix + "events-read", http.StatusOK},
+		{"PATでスコープなし", "events:write", PersonalAccessTokenPrefix + "events-read", http.StatusForbidden},
+		{"PATはスコープ指定のないエンドポイントで使えない", "スコープ指定なし", PersonalAccessTokenPrefix + "events-read", http.StatusUnauthorized},
+		{"無効なPAT", "events:read", PersonalAccessTokenPrefix + "unknown", http.StatusUnauthorized},
+		{"JWTはすべてのスコープを持つ", "events:write", jwtToken, http.StatusOK},
+		{"JWTはスコープ指定のないエンドポイントで使える", "スコープ指定なし", jwtToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/me/events", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+		rec := httptest.NewRecorder()
+
+		if err := routes[tt.route](e.NewContext(req, rec)); err != nil {
+			t.Errorf("%s: 予期しないエラー: %v", tt.name, err)
+			continue
+		}
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: 期待値 %d, 実際 %d", tt.name, tt.wantStatus, rec.Code)
+		}
+	}
+}
+
+func TestPersonalAccessTokenDisabledWithoutValidator(t *testing.T) {
+	SetPersonalAccessTokenValidator(nil)
+
+	e := echo.New()
+	handler := JWTMiddleware()(RequireScope(ScopeEventsRead)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/events", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+PersonalAccessTokenPrefix+"token")
+	rec := httptest.NewRecorder()
+	if err := handler(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("予期しないエラー: %v", err)
+	}
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("検証関数が未設定の場合は401になるべきです: 実際 %d", rec.Code)
+	}
+}
@@ -0,0 +1,245 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// テスト用のIdP（ディスカバリー・JWKS・トークンエンドポイントのみ）
+type stubIdP struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu         sync.Mutex
+	challenges map[string]string // code -> code_challenge
+	claims     map[string]jwt.MapClaims
+}
+
+func newStubIdP(t *testing.T, clientID string) *stubIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("鍵の生成に失敗: %v", err)
+	}
+
+	idp := &stubIdP{
+		key:        key,
+		clientID:   clientID,
+		challenges: make(map[string]string),
+		claims:     make(map[string]jwt.MapClaims),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		idp.mu.Lock()
+		challenge, ok := idp.challenges[r.PostForm.Get("code")]
+		claims := idp.claims[r.PostForm.Get("code")]
+		delete(idp.challenges, r.PostForm.Get("code"))
+		idp.mu.Unlock()
+
+		if !ok || CodeChallenge(r.PostForm.Get("code_verifier")) != challenge || r.PostForm.Get("client_id") != clientID {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     idp.sign(t, claims),
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+// 認可画面でユーザーが同意した状態を再現し、認可コードを発行する
+func (idp *stubIdP) authorize(t *testing.T, authURL string, claims jwt.MapClaims) (code string, state string) {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("認可URLが不正: %v", err)
+	}
+	query := u.Query()
+
+	code = "code-" + query.Get("state")
+	idToken := jwt.MapClaims{
+		"iss":   idp.server.URL,
+		"aud":   idp.clientID,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for k, v := range claims {
+		idToken[k] = v
+	}
+
+	idp.mu.Lock()
+	idp.challenges[code] = query.Get("code_challenge")
+	idp.claims[code] = idToken
+	idp.mu.Unlock()
+
+	return code, query.Get("state")
+}
+
+func (idp *stubIdP) sign(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(idp.key)
+	if err != nil {
+		t.Fatalf("IDトークンの署名に失敗: %v", err)
+	}
+	return signed
+}
+
+func TestProviderAuthorizationCodeFlow(t *testing.T) {
+	idp := newStubIdP(t, "client-1")
+	provider := NewProvider(Config{
+		Issuer:       idp.server.URL,
+		ClientID:     "client-1",
+		ClientSecret: "secret",
+		RedirectURL:  "https://app.example.com/oidc/callback",
+	})
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURLでエラー: %v", err)
+	}
+	if !strings.HasPrefix(authURL, idp.server.URL+"/authorize?") {
+		t.Fatalf("認可エンドポイントが違います: %s", authURL)
+	}
+	query, _ := url.ParseQuery(strings.SplitN(authURL, "?", 2)[1])
+	for key, want := range map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-1",
+		"redirect_uri":          "https://app.example.com/oidc/callback",
+		"scope":                 "openid email profile",
+		"code_challenge":        CodeChallenge("verifier-1"),
+		"code_challenge_method": "S256",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s: 期待値 %q, 実際 %q", key, want, got)
+		}
+	}
+
+	code, _ := idp.authorize(t, authURL, jwt.MapClaims{
+		"sub":            "user-123",
+		"email":          "user@example.com",
+		"email_verified": "true",
+		"name":           "テストユーザー",
+	})
+
+	claims, err := provider.Exchange(ctx, code, "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchangeでエラー: %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "user@example.com" || !claims.EmailVerified || claims.Name != "テストユーザー" {
+		t.Errorf("クレームが期待と違います: %+v", claims)
+	}
+
+	// 認可コードは1回しか使えない
+	if _, err := provider.Exchange(ctx, code, "verifier-1", "nonce-1"); err == nil {
+		t.Errorf("使用済みの認可コードはエラーになるべきです")
+	}
+}
+
+func TestProviderExchangeRejectsInvalidTokens(t *testing.T) {
+	idp := newStubIdP(t, "client-1")
+	provider := NewProvider(Config{
+		Issuer:      idp.server.URL,
+		ClientID:    "client-1",
+		RedirectURL: "https://app.example.com/oidc/callback",
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		verifier string
+		nonce    string
+	}{
+		{"code_verifierが違う", jwt.MapClaims{"sub": "user-1"}, "wrong-verifier", "nonce-1"},
+		{"nonceが違う", jwt.MapClaims{"sub": "user-1"}, "verifier-1", "other-nonce"},
+		{"audienceが違う", jwt.MapClaims{"sub": "user-1", "aud": "other-client"}, "verifier-1", "nonce-1"},
+		{"issuerが違う", jwt.MapClaims{"sub": "user-1", "iss": "https://evil.example.com"}, "verifier-1", "nonce-1"},
+		{"期限切れ", jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(-time.Hour).Unix()}, "verifier-1", "nonce-1"},
+		{"subなし", jwt.MapClaims{}, "verifier-1", "nonce-1"},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := "state-" + string(rune('a'+i))
+			authURL, err := provider.AuthCodeURL(ctx, state, "nonce-1", "verifier-1")
+			if err != nil {
+				t.Fatalf("AuthCodeURLでエラー: %v", err)
+			}
+			code, _ := idp.authorize(t, authURL, tt.claims)
+
+			if _, err := provider.Exchange(ctx, code, tt.verifier, tt.nonce); err == nil {
+				t.Errorf("エラーになるべきです")
+			}
+		})
+	}
+}
+
+func TestProviderTrustEmail(t *testing.T) {
+	idp := newStubIdP(t, "client-1")
+	ctx := context.Background()
+
+	for _, trust := range []bool{false, true} {
+		provider := NewProvider(Config{Issuer: idp.server.URL, ClientID: "client-1", TrustEmail: trust})
+		authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", "verifier")
+		if err != nil {
+			t.Fatalf("AuthCodeURLでエラー: %v", err)
+		}
+		// email_verified を返さないプロバイダー
+		code, _ := idp.authorize(t, authURL, jwt.MapClaims{"sub": "line-user", "email": "line@example.com"})
+
+		claims, err := provider.Exchange(ctx, code, "verifier", "nonce")
+		if err != nil {
+			t.Fatalf("Exchangeでエラー: %v", err)
+		}
+		if claims.EmailVerified != trust {
+			t.Errorf("TrustEmail=%v のときEmailVerifiedは %v になるべきです", trust, trust)
+		}
+	}
+}
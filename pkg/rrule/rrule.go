@@ -0,0 +1,386 @@
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency FREQの値
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// 展開する期間数の上限（無限に続くルールの安全装置）
+const maxPeriods = 100000
+
+// UNTILの日時形式
+const (
+	untilDateTimeFormat = "20060102T150405Z"
+	untilDateFormat     = "20060102"
+)
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// WeekdayNum BYDAYの1要素（Nは月内の第N曜日、0なら毎週、負数は末尾から）
+type WeekdayNum struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule RRULE（FREQ, INTERVAL, BYDAY, COUNT, UNTIL のみ対応）
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []WeekdayNum
+	Count    int
+	Until    *time.Time
+
+	// UNTILが日付のみで指定された場合はその日の終わりまでを含める
+	untilIsDate bool
+}
+
+// Parse RRULE文字列を解析する（先頭の "RRULE:" は省略可）
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, errors.New("empty rule")
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		val = strings.ToUpper(strings.TrimSpace(val))
+		if !ok || val == "" {
+			return nil, fmt.Errorf("malformed part %q", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate %s", key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			switch Frequency(val) {
+			case Daily, Weekly, Monthly, Yearly:
+				rule.Freq = Frequency(val)
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %q", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", val)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid COUNT %q", val)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, isDate, err := parseUntil(val)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+			rule.untilIsDate = isDate
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				weekday, err := parseWeekdayNum(code)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported part %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("COUNT and UNTIL must not be used together")
+	}
+	for _, weekday := range rule.ByDay {
+		if rule.Freq == Yearly {
+			return nil, errors.New("BYDAY is not supported with FREQ=YEARLY")
+		}
+		if weekday.N != 0 && rule.Freq != Monthly {
+			return nil, errors.New("BYDAY with an ordinal requires FREQ=MONTHLY")
+		}
+	}
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(untilDateTimeFormat, value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.Parse(untilDateFormat, value); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("invalid UNTIL %q", value)
+}
+
+func parseWeekdayNum(code string) (WeekdayNum, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+	}
+
+	day, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+	}
+
+	n := 0
+	if prefix := code[:len(code)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY %q", code)
+		}
+	}
+
+	return WeekdayNum{N: n, Day: day}, nil
+}
+
+// String 正規化したRRULE文字列を返す
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, weekday := range r.ByDay {
+			code := strings.ToUpper(weekday.Day.String()[:2])
+			if weekday.N != 0 {
+				code = strconv.Itoa(weekday.N) + code
+			}
+			codes = append(codes, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		if r.untilIsDate {
+			parts = append(parts, "UNTIL="+r.Until.Format(untilDateFormat))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilDateTimeFormat))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between dtstartから始まる繰り返しのうち、開始日時が [from, to) に含まれるものを最大limit件返す
+// 曜日や日付はlocの暦で判定する。toがゼロ値なら上限なし、limitが0以下なら件数の上限なし
+func (r *Rule) Between(dtstart, from, to time.Time, loc *time.Location, limit int) []time.Time {
+	dtstart = dtstart.In(loc)
+	var occurrences []time.Time
+
+	// COUNTがない場合は計算を省くためfromの直前の期間から展開する
+	period := 0
+	if r.Count == 0 && from.After(dtstart) {
+		period = r.periodsBetween(dtstart, from.In(loc))/r.Interval - 1
+		if period < 0 {
+			period = 0
+		}
+	}
+
+	count := 0
+	for ; period < maxPeriods; period++ {
+		for _, candidate := range r.candidates(dtstart, period) {
+			if candidate.Before(dtstart) {
+				continue
+			}
+			if r.Until != nil && r.afterUntil(candidate) {
+				return occurrences
+			}
+			if !to.IsZero() && !candidate.Before(to) {
+				return occurrences
+			}
+
+			count++
+			if r.Count > 0 && count > r.Count {
+				return occurrences
+			}
+			if candidate.Before(from) {
+				continue
+			}
+
+			occurrences = append(occurrences, candidate)
+			if limit > 0 && len(occurrences) >= limit {
+				return occurrences
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// Contains tがdtstartから始まる繰り返しの開始日時の1つか判定する
+func (r *Rule) Contains(dtstart, t time.Time, loc *time.Location) bool {
+	occurrences := r.Between(dtstart, t, t.Add(time.Nanosecond), loc, 1)
+	return len(occurrences) == 1 && occurrences[0].Equal(t)
+}
+
+func (r *Rule) afterUntil(t time.Time) bool {
+	if r.untilIsDate {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).After(*r.Until)
+	}
+	return t.After(*r.Until)
+}
+
+// dtstartからtまでの期間数（FREQの単位）
+func (r *Rule) periodsBetween(dtstart, t time.Time) int {
+	switch r.Freq {
+	case Daily:
+		return int(dateOf(t).Sub(dateOf(dtstart)).Hours() / 24)
+	case Weekly:
+		return int(dateOf(t).Sub(weekStart(dtstart)).Hours() / (24 * 7))
+	case Monthly:
+		return (t.Year()-dtstart.Year())*12 + int(t.Month()-dtstart.Month())
+	default:
+		return t.Year() - dtstart.Year()
+	}
+}
+
+// period番目の期間に含まれる開始日時の候補（昇順）
+func (r *Rule) candidates(dtstart time.Time, period int) []time.Time {
+	step := period * r.Interval
+	hour, min, sec := dtstart.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, dtstart.Nanosecond(), dtstart.Location())
+	}
+
+	switch r.Freq {
+	case Daily:
+		day := dtstart.AddDate(0, 0, step)
+		if len(r.ByDay) > 0 && !r.hasWeekday(day.Weekday()) {
+			return nil
+		}
+		return []time.Time{at(day.Date())}
+
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			day := dtstart.AddDate(0, 0, step*7)
+			return []time.Time{at(day.Date())}
+		}
+		start := weekStart(dtstart).AddDate(0, 0, step*7)
+		var result []time.Time
+		for offset := 0; offset < 7; offset++ {
+			day := start.AddDate(0, 0, offset)
+			if r.hasWeekday(day.Weekday()) {
+				result = append(result, at(day.Date()))
+			}
+		}
+		return result
+
+	case Monthly:
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, dtstart.Location())
+		year, month := first.Year(), first.Month()
+		if len(r.ByDay) == 0 {
+			// 存在しない日付（31日など）の月は飛ばす
+			if dtstart.Day() > daysIn(year, month) {
+				return nil
+			}
+			return []time.Time{at(year, month, dtstart.Day())}
+		}
+		days := make(map[int]bool)
+		for _, weekday := range r.ByDay {
+			for _, day := range weekdaysInMonth(year, month, weekday) {
+				days[day] = true
+			}
+		}
+		result := make([]time.Time, 0, len(days))
+		for day := range days {
+			result = append(result, at(year, month, day))
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+		return result
+
+	default:
+		year := dtstart.Year() + step
+		// 2月29日はうるう年のみ
+		if dtstart.Day() > daysIn(year, dtstart.Month()) {
+			return nil
+		}
+		return []time.Time{at(year, dtstart.Month(), dtstart.Day())}
+	}
+}
+
+func (r *Rule) hasWeekday(day time.Weekday) bool {
+	for _, weekday := range r.ByDay {
+		if weekday.Day == day {
+			return true
+		}
+	}
+	return false
+}
+
+// 月内で条件に合う曜日の日付
+func weekdaysInMonth(year int, month time.Month, weekday WeekdayNum) []int {
+	var days []int
+	for day := 1; day <= daysIn(year, month); day++ {
+		if time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() == weekday.Day {
+			days = append(days, day)
+		}
+	}
+
+	switch {
+	case weekday.N > 0:
+		if weekday.N > len(days) {
+			return nil
+		}
+		return []int{days[weekday.N-1]}
+	case weekday.N < 0:
+		if -weekday.N > len(days) {
+			return nil
+		}
+		return []int{days[len(days)+weekday.N]}
+	default:
+		return days
+	}
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// tを含む週の月曜日（WKST=MO）
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return dateOf(t).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,177 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"FREQ=DAILY", "FREQ=DAILY"},
+		{"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE"},
+		{"freq=monthly;byday=-1fr;count=3", "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"},
+		{"FREQ=WEEKLY;UNTIL=20260331T150000Z", "FREQ=WEEKLY;UNTIL=20260331T150000Z"},
+		{"FREQ=YEARLY;UNTIL=20300101", "FREQ=YEARLY;UNTIL=20300101"},
+	}
+
+	for _, tt := range tests {
+		rule, err := Parse(tt.value)
+		if err != nil {
+			t.Errorf("Parse(%q) でエラーになりました: %v", tt.value, err)
+			continue
+		}
+		if actual := rule.String(); actual != tt.expected {
+			t.Errorf("Parse(%q).String() = %q, 期待値 %q", tt.value, actual, tt.expected)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	values := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20260101T000000Z",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=YEARLY;BYDAY=MO",
+		"FREQ=MONTHLY;BYMONTHDAY=1",
+		"FREQ=DAILY;FREQ=WEEKLY",
+	}
+
+	for _, value := range values {
+		if _, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) はエラーになるべきです", value)
+		}
+	}
+}
+
+func TestRule_Between(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		dtstart  time.Time
+		from     time.Time
+		to       time.Time
+		limit    int
+		expected []time.Time
+	}{
+		{
+			name:    "毎週日曜（日本時間の曜日で判定）",
+			rule:    "FREQ=WEEKLY;COUNT=3",
+			dtstart: time.Date(2026, 1, 4, 1, 0, 0, 0, jst),
+			expected: []time.Time{
+				time.Date(2026, 1, 4, 1, 0, 0, 0, jst),
+				time.Date(2026, 1, 11, 1, 0, 0, 0, jst),
+				time.Date(2026, 1, 18, 1, 0, 0, 0, jst),
+			},
+		},
+		{
+			name:    "隔週の月・水",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			dtstart: time.Date(2026, 1, 5, 20, 0, 0, 0, jst),
+			limit:   4,
+			expected: []time.Time{
+				time.Date(2026, 1, 5, 20, 0, 0, 0, jst),
+				time.Date(2026, 1, 7, 20, 0, 0, 0, jst),
+				time.Date(2026, 1, 19, 20, 0, 0, 0, jst),
+				time.Date(2026, 1, 21, 20, 0, 0, 0, jst),
+			},
+		},
+		{
+			name:    "毎月最終金曜",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: time.Date(2026, 1, 30, 21, 0, 0, 0, jst),
+			limit:   3,
+			expected: []time.Time{
+				time.Date(2026, 1, 30, 21, 0, 0, 0, jst),
+				time.Date(2026, 2, 27, 21, 0, 0, 0, jst),
+				time.Date(2026, 3, 27, 21, 0, 0, 0, jst),
+			},
+		},
+		{
+			name:    "毎月31日（存在しない月は飛ばす）",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: time.Date(2026, 1, 31, 12, 0, 0, 0, jst),
+			expected: []time.Time{
+				time.Date(2026, 1, 31, 12, 0, 0, 0, jst),
+				time.Date(2026, 3, 31, 12, 0, 0, 0, jst),
+				time.Date(2026, 5, 31, 12, 0, 0, 0, jst),
+			},
+		},
+		{
+			name:    "UNTILまで（当日を含む）",
+			rule:    "FREQ=DAILY;UNTIL=20260103",
+			dtstart: time.Date(2026, 1, 1, 9, 0, 0, 0, jst),
+			expected: []time.Time{
+				time.Date(2026, 1, 1, 9, 0, 0, 0, jst),
+				time.Date(2026, 1, 2, 9, 0, 0, 0, jst),
+				time.Date(2026, 1, 3, 9, 0, 0, 0, jst),
+			},
+		},
+		{
+			name:    "期間指定（開始から離れた範囲）",
+			rule:    "FREQ=WEEKLY;BYDAY=SA",
+			dtstart: time.Date(2020, 1, 4, 22, 0, 0, 0, jst),
+			from:    time.Date(2026, 1, 1, 0, 0, 0, 0, jst),
+			to:      time.Date(2026, 1, 15, 0, 0, 0, 0, jst),
+			expected: []time.Time{
+				time.Date(2026, 1, 3, 22, 0, 0, 0, jst),
+				time.Date(2026, 1, 10, 22, 0, 0, 0, jst),
+			},
+		},
+		{
+			name:    "COUNTは期間外の回も数える",
+			rule:    "FREQ=DAILY;COUNT=5",
+			dtstart: time.Date(2026, 1, 1, 9, 0, 0, 0, jst),
+			from:    time.Date(2026, 1, 4, 0, 0, 0, 0, jst),
+			expected: []time.Time{
+				time.Date(2026, 1, 4, 9, 0, 0, 0, jst),
+				time.Date(2026, 1, 5, 9, 0, 0, 0, jst),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) でエラーになりました: %v", tt.rule, err)
+			}
+
+			actual := rule.Between(tt.dtstart, tt.from, tt.to, jst, tt.limit)
+			if len(actual) != len(tt.expected) {
+				t.Fatalf("件数が一致しません\n期待値: %v\n実際値: %v", tt.expected, actual)
+			}
+			for i := range actual {
+				if !actual[i].Equal(tt.expected[i]) {
+					t.Errorf("%d件目が一致しません\n期待値: %s\n実際値: %s", i+1, tt.expected[i], actual[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRule_Contains(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=TU,TH")
+	if err != nil {
+		t.Fatalf("Parseでエラーになりました: %v", err)
+	}
+	dtstart := time.Date(2026, 1, 6, 19, 0, 0, 0, jst)
+
+	if !rule.Contains(dtstart, time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC), jst) {
+		t.Error("木曜の回が含まれていません")
+	}
+	if rule.Contains(dtstart, time.Date(2026, 1, 14, 10, 0, 0, 0, time.UTC), jst) {
+		t.Error("水曜の回が含まれています")
+	}
+	if rule.Contains(dtstart, time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC), jst) {
+		t.Error("開始時刻の異なる日時が含まれています")
+	}
+}
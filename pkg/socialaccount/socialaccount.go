@@ -0,0 +1,158 @@
+package socialaccount
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Platform アカウントのプラットフォーム
+type Platform string
+
+const (
+	X         Platform = "x"
+	Instagram Platform = "instagram"
+	YouTube   Platform = "youtube"
+	TikTok    Platform = "tiktok"
+	Bluesky   Platform = "bluesky"
+	Custom    Platform = "custom" // 上記以外のURL（ハンドルなし）
+)
+
+// URLの長さの上限（oshi_accounts.url）
+const maxURLLength = 2048
+
+var (
+	xHandlePattern         = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+	instagramHandlePattern = regexp.MustCompile(`^[A-Za-z0-9._]{1,30}$`)
+	youtubeHandlePattern   = regexp.MustCompile(`^[A-Za-z0-9._-]{3,30}$`)
+	youtubeChannelPattern  = regexp.MustCompile(`^UC[A-Za-z0-9_-]{22}$`)
+	tiktokHandlePattern    = regexp.MustCompile(`^[A-Za-z0-9._]{2,24}$`)
+	blueskyHandlePattern   = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	blueskyDIDPattern      = regexp.MustCompile(`^did:[a-z]+:[A-Za-z0-9._:-]+$`)
+)
+
+// プロフィールではないパス（x.com/home など）
+var (
+	xReservedPaths         = []string{"home", "i", "search", "explore", "intent", "share", "settings", "notifications", "messages", "hashtag"}
+	instagramReservedPaths = []string{"p", "reel", "reels", "explore", "stories", "accounts", "tv"}
+)
+
+// ErrInvalidURL URLとして解析できない、または対応プラットフォームのプロフィールURLではない
+var ErrInvalidURL = errors.New("invalid account url")
+
+// Account 解析したアカウント
+type Account struct {
+	Platform Platform
+	Handle   string // Customの場合は空
+	URL      string // 正規化したURL
+}
+
+// Parse アカウントのURLを解析してプラットフォームとハンドルを取り出す
+// スキームは省略可（https を補う）。対応プラットフォームのURLはプロフィールURLに正規化し、
+// それ以外は Custom としてホスト名の小文字化・フラグメントと末尾のスラッシュの除去のみ行う
+func Parse(raw string) (*Account, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || len(raw) > maxURLLength {
+		return nil, ErrInvalidURL
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return nil, ErrInvalidURL
+	}
+
+	host := strings.ToLower(u.Hostname())
+	segments := pathSegments(u.EscapedPath())
+
+	switch strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(host, "www."), "mobile."), "m.") {
+	case "x.com", "twitter.com":
+		return parseHandle(X, segments, "", xHandlePattern, xReservedPaths, "https://x.com/")
+	case "instagram.com":
+		return parseHandle(Instagram, segments, "", instagramHandlePattern, instagramReservedPaths, "https://www.instagram.com/")
+	case "tiktok.com":
+		return parseHandle(TikTok, segments, "@", tiktokHandlePattern, nil, "https://www.tiktok.com/@")
+	case "youtube.com":
+		// チャンネルIDのURL（/channel/UC...）はチャンネルIDをハンドルとする
+		if len(segments) >= 2 && segments[0] == "channel" {
+			if !youtubeChannelPattern.MatchString(segments[1]) {
+				return nil, ErrInvalidURL
+			}
+			return &Account{Platform: YouTube, Handle: segments[1], URL: YouTubeURL(segments[1])}, nil
+		}
+		return parseHandle(YouTube, segments, "@", youtubeHandlePattern, nil, "https://www.youtube.com/@")
+	case "bsky.app":
+		if len(segments) < 2 || segments[0] != "profile" {
+			return nil, ErrInvalidURL
+		}
+		// ハンドル（ドメイン名）は小文字に揃え、DIDはそのまま使う
+		handle := segments[1]
+		if !blueskyDIDPattern.MatchString(handle) {
+			handle = strings.ToLower(handle)
+			if !blueskyHandlePattern.MatchString(handle) {
+				return nil, ErrInvalidURL
+			}
+		}
+		return &Account{Platform: Bluesky, Handle: handle, URL: "https://bsky.app/profile/" + handle}, nil
+	}
+
+	// 対応外のサイトはURLのまま保存する
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	return &Account{Platform: Custom, URL: u.String()}, nil
+}
+
+// YouTubeURL YouTubeのハンドル（またはチャンネルID）からプロフィールURLを作る
+func YouTubeURL(handle string) string {
+	if youtubeChannelPattern.MatchString(handle) {
+		return "https://www.youtube.com/channel/" + handle
+	}
+	return "https://www.youtube.com/@" + handle
+}
+
+// パスの先頭セグメントをハンドルとして取り出す（以降のセグメントやクエリは無視する）
+func parseHandle(platform Platform, segments []string, prefix string, pattern *regexp.Regexp, reserved []string, baseURL string) (*Account, error) {
+	if len(segments) == 0 {
+		return nil, ErrInvalidURL
+	}
+
+	first := segments[0]
+	if prefix != "" && !strings.HasPrefix(first, prefix) {
+		return nil, ErrInvalidURL
+	}
+	// x.com/@name のような入力も受け付ける
+	handle := strings.TrimPrefix(first, "@")
+
+	if !pattern.MatchString(handle) {
+		return nil, ErrInvalidURL
+	}
+	for _, path := range reserved {
+		if strings.EqualFold(handle, path) {
+			return nil, ErrInvalidURL
+		}
+	}
+
+	return &Account{Platform: platform, Handle: handle, URL: baseURL + handle}, nil
+}
+
+// 空のセグメントを除いてパスを分割する
+func pathSegments(escapedPath string) []string {
+	var segments []string
+	for _, segment := range strings.Split(escapedPath, "/") {
+		if segment == "" {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
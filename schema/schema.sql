@@ -4,10 +4,15 @@ CREATE TABLE users (
   name            VARCHAR(191)     NOT NULL,
   email           VARCHAR(255)     NOT NULL,
   password_hash   VARCHAR(255)     NOT NULL,
+  role            ENUM('user', 'admin') NOT NULL DEFAULT 'user', -- adminは内部用エンドポイント（/api/z）を利用できる
+  email_verified_at DATETIME(3)             DEFAULT NULL,          -- メールアドレスの確認日時
+  deletion_requested_at DATETIME(3)         DEFAULT NULL,          -- 退会申請日時（猶予期間後に削除）
+  oshi_synced_at  DATETIME(3)             DEFAULT NULL,          -- 推しの投稿を最後に手動で取り込んだ日時（取り込みの間隔の制限に使う）
   created_at      DATETIME(3)      NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
   updated_at      DATETIME(3)      NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
   PRIMARY KEY (id),
-  UNIQUE KEY uq_users_email (email)
+  UNIQUE KEY uq_users_email (email),
+  KEY idx_users_deletion_requested_at (deletion_requested_at)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 -- 2) oshii
@@ -17,11 +22,15 @@ CREATE TABLE oshis (
   name          VARCHAR(191)     NOT NULL,
   description   TEXT,
   theme_color   CHAR(7)          NOT NULL DEFAULT '#FFFFFF',  -- '#RRGGBB'
+  auto_event_mode ENUM('publish', 'review') NOT NULL DEFAULT 'review', -- 自動検出イベントをそのまま登録するか確認待ちにするか
   created_at    DATETIME(3)      NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
   updated_at    DATETIME(3)      NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+  deleted_at    DATETIME(3)               DEFAULT NULL, -- 論理削除日時（取り消し期間を過ぎると削除される）
+  active_name   VARCHAR(191) AS (IF(deleted_at IS NULL, name, NULL)) VIRTUAL, -- 削除済みの推しと同じ名前で作成できるようにするため
   PRIMARY KEY (id),
-  UNIQUE KEY uq_oshis_user_name (user_id, name),
+  UNIQUE KEY uq_oshis_user_name (user_id, active_name),
   KEY idx_oshis_user (user_id),
+  KEY idx_oshis_deleted_at (deleted_at),
   CONSTRAINT fk_oshis_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
@@ -29,11 +38,14 @@ CREATE TABLE oshis (
 CREATE TABLE oshi_accounts (
   id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
   oshi_id    BIGINT UNSIGNED NOT NULL,
-  url        VARCHAR(2048)   NOT NULL,
+  url        VARCHAR(2048)   NOT NULL, -- 正規化したURL
+  platform   ENUM('x','instagram','youtube','tiktok','bluesky','custom') NOT NULL DEFAULT 'custom',
+  handle     VARCHAR(255)    NULL,     -- customの場合はNULL
   created_at DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
   PRIMARY KEY (id),
   UNIQUE KEY uq_oshi_accounts (oshi_id, url(191)),
   KEY idx_oshi_accounts_oshi (oshi_id),
+  KEY idx_oshi_accounts_platform_handle (platform, handle),
   CONSTRAINT fk_oshi_accounts_oshi FOREIGN KEY (oshi_id) REFERENCES oshis(id) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
@@ -79,6 +91,8 @@ CREATE TABLE events (
   oshi_id      BIGINT UNSIGNED   NOT NULL,
   category_id  SMALLINT UNSIGNED          DEFAULT NULL,
   post_id      BIGINT UNSIGNED            DEFAULT NULL,
+  post_span_start INT UNSIGNED            DEFAULT NULL, -- 投稿内で日時が見つかった位置（文字数、NULLは投稿全体から1件登録した旧データ）
+  source       ENUM('manual', 'auto', 'import') NOT NULL DEFAULT 'manual', -- 登録経路（手動・ポストから自動検出・ファイル取り込み）
   title        VARCHAR(255)      NOT NULL,
   description  TEXT,
   url          VARCHAR(2048)              DEFAULT NULL, -- イベントURL
@@ -87,13 +101,172 @@ CREATE TABLE events (
   has_notification_sent   TINYINT(1)    DEFAULT 0,
   starts_at    DATETIME(3)       NOT NULL,
   ends_at      DATETIME(3)                DEFAULT NULL,
+  rrule        VARCHAR(255)               DEFAULT NULL, -- 繰り返しルール（RFC 5545 RRULE、NULLなら単発）
+  extraction   JSON                       DEFAULT NULL, -- 日時抽出の根拠（パターン・一致箇所・信頼度・推測した内容、自動登録のみ）
   created_at   DATETIME(3)       NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
   updated_at   DATETIME(3)       NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+  deleted_at   DATETIME(3)                DEFAULT NULL, -- 論理削除日時
   PRIMARY KEY (id),
   KEY idx_events_oshi (oshi_id),
   KEY idx_events_category (category_id),
   KEY idx_events_oshi_starts (oshi_id, starts_at),
+  UNIQUE KEY uq_events_oshi_post_span (oshi_id, post_id, post_span_start),
   CONSTRAINT fk_events_oshi FOREIGN KEY (oshi_id) REFERENCES oshis(id) ON DELETE CASCADE,
   CONSTRAINT fk_events_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL,
   CONSTRAINT chk_events_time CHECK (ends_at IS NULL OR ends_at >= starts_at)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 5) カレンダーフィード（iCalendar配信用のトークン）
+CREATE TABLE calendar_feeds (
+  id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id     BIGINT UNSIGNED NOT NULL,
+  oshi_id     BIGINT UNSIGNED          DEFAULT NULL, -- NULLの場合は全推し
+  token_hash  CHAR(64)        NOT NULL,              -- トークンのSHA-256
+  created_at  DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  rotated_at  DATETIME(3)              DEFAULT NULL,
+  revoked_at  DATETIME(3)              DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_calendar_feeds_token_hash (token_hash),
+  KEY idx_calendar_feeds_user (user_id),
+  CONSTRAINT fk_calendar_feeds_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT fk_calendar_feeds_oshi FOREIGN KEY (oshi_id) REFERENCES oshis(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 6) 繰り返しイベントの個別変更・中止
+CREATE TABLE event_occurrence_overrides (
+  id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  event_id      BIGINT UNSIGNED NOT NULL,
+  recurrence_id DATETIME(3)     NOT NULL,              -- 変更対象の回の本来の開始日時
+  is_cancelled  TINYINT(1)      NOT NULL DEFAULT 0,    -- この回を中止
+  title         VARCHAR(255)             DEFAULT NULL, -- NULLの項目は繰り返し元の値を使う
+  description   TEXT,
+  url           VARCHAR(2048)            DEFAULT NULL,
+  starts_at     DATETIME(3)              DEFAULT NULL,
+  ends_at       DATETIME(3)              DEFAULT NULL,
+  created_at    DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  updated_at    DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_event_occurrence_overrides (event_id, recurrence_id),
+  CONSTRAINT fk_event_occurrence_overrides_event FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 7) 自動検出したイベント候補（確認待ち）
+CREATE TABLE event_candidates (
+  id               BIGINT UNSIGNED   NOT NULL AUTO_INCREMENT,
+  oshi_id          BIGINT UNSIGNED   NOT NULL,
+  post_id          BIGINT UNSIGNED   NOT NULL,
+  post_span_start  INT UNSIGNED               DEFAULT NULL, -- 投稿内で日時が見つかった位置（文字数）
+  category_id      SMALLINT UNSIGNED          DEFAULT NULL,
+  title            VARCHAR(255)      NOT NULL,
+  content          TEXT              NOT NULL,              -- 元の投稿内容
+  account_name     VARCHAR(191)      NOT NULL,              -- 投稿したアカウント
+  post_created_at  DATETIME(3)       NOT NULL,
+  matched_keywords JSON              NOT NULL,              -- 一致したキーワード
+  matched_pattern  VARCHAR(255)      NOT NULL,              -- 日時抽出に使った正規表現
+  matched_text     VARCHAR(255)      NOT NULL,              -- 正規表現に一致した文字列
+  extraction       JSON                       DEFAULT NULL, -- 日時抽出の根拠（パターン・一致箇所・信頼度・推測した内容）
+  starts_at        DATETIME(3)       NOT NULL,
+  ends_at          DATETIME(3)                DEFAULT NULL,
+  status           ENUM('pending', 'accepted', 'rejected') NOT NULL DEFAULT 'pending',
+  event_id         BIGINT UNSIGNED            DEFAULT NULL, -- 承認して作成したイベント
+  created_at       DATETIME(3)       NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  updated_at       DATETIME(3)       NOT NULL DEFAULT CURRENT_TIMESTAMP(3) ON UPDATE CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_event_candidates_oshi_post_span (oshi_id, post_id, post_span_start),
+  KEY idx_event_candidates_status (status),
+  CONSTRAINT fk_event_candidates_oshi FOREIGN KEY (oshi_id) REFERENCES oshis(id) ON DELETE CASCADE,
+  CONSTRAINT fk_event_candidates_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL,
+  CONSTRAINT fk_event_candidates_event FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 8) ログインセッション（リフレッシュトークンとアクセストークンの失効管理）
+CREATE TABLE sessions (
+  id                          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id                     BIGINT UNSIGNED NOT NULL,
+  jti                         VARCHAR(64)     NOT NULL,              -- アクセストークンのjti
+  refresh_token_hash          CHAR(64)        NOT NULL,              -- リフレッシュトークンのSHA-256
+  previous_refresh_token_hash CHAR(64)                 DEFAULT NULL, -- ローテーション前のトークン（再利用の検知用）
+  user_agent                  VARCHAR(255)             DEFAULT NULL,
+  ip_address                  VARCHAR(45)              DEFAULT NULL,
+  created_at                  DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  last_used_at                DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  expires_at                  DATETIME(3)     NOT NULL,              -- リフレッシュトークンの有効期限
+  revoked_at                  DATETIME(3)              DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_sessions_jti (jti),
+  UNIQUE KEY uq_sessions_refresh_token_hash (refresh_token_hash),
+  KEY idx_sessions_previous_refresh_token_hash (previous_refresh_token_hash),
+  KEY idx_sessions_user (user_id),
+  CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 9) パスワード再設定・メールアドレス確認用のトークン（1回限り、有効期限付き）
+CREATE TABLE user_tokens (
+  id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id     BIGINT UNSIGNED NOT NULL,
+  purpose     ENUM('password_reset', 'email_verification') NOT NULL,
+  token_hash  CHAR(64)        NOT NULL,              -- トークンのSHA-256
+  expires_at  DATETIME(3)     NOT NULL,
+  used_at     DATETIME(3)              DEFAULT NULL,
+  created_at  DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_user_tokens_token_hash (token_hash),
+  KEY idx_user_tokens_user_purpose (user_id, purpose),
+  CONSTRAINT fk_user_tokens_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 10) 外部IDプロバイダー（OpenID Connect）のアカウントとの紐付け
+CREATE TABLE user_identities (
+  id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id       BIGINT UNSIGNED NOT NULL,
+  provider      VARCHAR(50)     NOT NULL,              -- google, line など（OIDC_PROVIDERSの名前）
+  subject       VARCHAR(255)    NOT NULL,              -- プロバイダーでのユーザーID（sub）
+  email         VARCHAR(255)             DEFAULT NULL, -- 紐付け時にプロバイダーから取得したメールアドレス
+  created_at    DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  last_login_at DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_user_identities_provider_subject (provider, subject),
+  KEY idx_user_identities_user (user_id),
+  CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 11) OpenID Connectログインの途中状態（state・nonce・PKCEのcode_verifier、1回限り）
+CREATE TABLE oidc_login_states (
+  id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  provider      VARCHAR(50)     NOT NULL,
+  state_hash    CHAR(64)        NOT NULL,              -- stateのSHA-256
+  nonce         VARCHAR(255)    NOT NULL,
+  code_verifier VARCHAR(255)    NOT NULL,
+  expires_at    DATETIME(3)     NOT NULL,
+  created_at    DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_oidc_login_states_state_hash (state_hash),
+  KEY idx_oidc_login_states_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 12) 個人用アクセストークン（スクリプトや外部連携用、トークンはハッシュのみ保存）
+CREATE TABLE personal_access_tokens (
+  id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id       BIGINT UNSIGNED NOT NULL,
+  name          VARCHAR(100)    NOT NULL,
+  token_hash    CHAR(64)        NOT NULL,              -- トークンのSHA-256
+  token_prefix  VARCHAR(20)     NOT NULL,              -- 一覧で見分けるためのトークンの先頭部分
+  scopes        VARCHAR(255)    NOT NULL,              -- スペース区切り（events:read events:write など）
+  expires_at    DATETIME(3)     NOT NULL,
+  last_used_at  DATETIME(3)              DEFAULT NULL,
+  created_at    DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  revoked_at    DATETIME(3)              DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_personal_access_tokens_token_hash (token_hash),
+  KEY idx_personal_access_tokens_user (user_id, revoked_at),
+  CONSTRAINT fk_personal_access_tokens_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 13) 繰り返しイベントの回ごとの通知済み記録（繰り返しのないイベントは events.has_notification_sent）
+CREATE TABLE event_occurrence_notifications (
+  event_id      BIGINT UNSIGNED NOT NULL,
+  recurrence_id DATETIME(3)     NOT NULL,              -- 通知した回の本来の開始日時
+  sent_at       DATETIME(3)     NOT NULL DEFAULT CURRENT_TIMESTAMP(3),
+  PRIMARY KEY (event_id, recurrence_id),
+  CONSTRAINT fk_event_occurrence_notifications_event FOREIGN KEY (event_id) REFERENCES events(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;